@@ -6,17 +6,22 @@ import (
 	"log"
 	"os"
 	"strings"
+	"text/tabwriter"
 
 	"github.com/Uttam-Mahata/RootAccess/backend/internal/config"
 	"github.com/Uttam-Mahata/RootAccess/backend/internal/database"
+	"github.com/Uttam-Mahata/RootAccess/backend/internal/models"
 	"github.com/Uttam-Mahata/RootAccess/backend/internal/repositories"
 	"github.com/Uttam-Mahata/RootAccess/backend/internal/services"
 )
 
-var adminService *services.AdminService
+var (
+	adminService *services.AdminService
+	cfg          *config.Config
+)
 
 func main() {
-	cfg := config.LoadConfig()
+	cfg = config.LoadConfig()
 	database.ConnectTurso(cfg.TursoURL, cfg.TursoAuthToken)
 	database.BootstrapSchema(database.TursoDB)
 
@@ -32,9 +37,11 @@ func main() {
 	fmt.Println()
 	fmt.Println("1. Create Admin User")
 	fmt.Println("2. Promote User to Admin")
-	fmt.Println("3. Demote Admin to User")
-	fmt.Println("4. List All Users")
-	fmt.Println("5. Exit")
+	fmt.Println("3. Make User a Challenge Author")
+	fmt.Println("4. Demote Admin or Author to User")
+	fmt.Println("5. List All Users")
+	fmt.Println("6. Reconcile Scores and Solve Counts")
+	fmt.Println("7. Exit")
 	fmt.Println()
 	fmt.Print("Choose an option: ")
 
@@ -47,10 +54,14 @@ func main() {
 	case "2":
 		promoteToAdmin(reader)
 	case "3":
-		demoteToUser(reader)
+		promoteToAuthor(reader)
 	case "4":
-		listAllUsers()
+		demoteToUser(reader)
 	case "5":
+		listAllUsers()
+	case "6":
+		reconcileScores(reader)
+	case "7":
 		fmt.Println("Goodbye!")
 		os.Exit(0)
 	default:
@@ -103,8 +114,25 @@ func promoteToAdmin(reader *bufio.Reader) {
 	fmt.Println("   New Role: admin")
 }
 
+func promoteToAuthor(reader *bufio.Reader) {
+	fmt.Println("\n=== Make User a Challenge Author ===")
+
+	fmt.Print("Enter username or email: ")
+	identifier, _ := reader.ReadString('\n')
+	identifier = strings.TrimSpace(identifier)
+
+	user, err := adminService.PromoteToAuthor(identifier)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Printf("\n✅ User '%s' is now a challenge author!\n", user.Username)
+	fmt.Println("   Email:", user.Email)
+	fmt.Println("   New Role: author")
+}
+
 func demoteToUser(reader *bufio.Reader) {
-	fmt.Println("\n=== Demote Admin to User ===")
+	fmt.Println("\n=== Demote Admin or Author to User ===")
 
 	fmt.Print("Enter username or email: ")
 	identifier, _ := reader.ReadString('\n')
@@ -153,3 +181,80 @@ func listAllUsers() {
 
 	fmt.Printf("\nTotal users: %d\n", len(users))
 }
+
+func reconcileScores(reader *bufio.Reader) {
+	fmt.Println("\n=== Reconcile Scores and Solve Counts ===")
+
+	// The scoreboard cache must be flushed once the counters are repaired
+	database.ConnectRedisRegistry(map[string]string{"scoreboard": cfg.RedisURLScoreboard})
+
+	db := database.TursoDB
+	challengeRepo := repositories.NewChallengeRepository(db)
+	contestSolveRepo := repositories.NewContestSolveRepository(db)
+	teamRepo := repositories.NewTeamRepository(db)
+	ledgerRepo := repositories.NewScoreLedgerRepository(db)
+	ledgerService := services.NewScoreLedgerService(
+		ledgerRepo,
+		challengeRepo,
+		repositories.NewSubmissionRepository(db),
+		contestSolveRepo,
+		repositories.NewContestEntityRepository(db),
+		repositories.NewTeamContestRegistrationRepository(db),
+		repositories.NewManualSubmissionRepository(db),
+		repositories.NewHintRepository(db),
+		repositories.NewScoreAdjustmentRepository(db),
+	)
+	reconciliationService := services.NewReconciliationService(challengeRepo, contestSolveRepo, teamRepo, ledgerRepo, ledgerService)
+
+	report, err := reconciliationService.Preview()
+	if err != nil {
+		log.Fatal("Failed to compute reconciliation:", err)
+	}
+	if report.InSync() {
+		fmt.Println("\n✅ Everything is in sync with the submissions.")
+		return
+	}
+	printReconciliation(report)
+
+	fmt.Print("\nApply these fixes? [y/N]: ")
+	answer, _ := reader.ReadString('\n')
+	if strings.ToLower(strings.TrimSpace(answer)) != "y" {
+		fmt.Println("Nothing changed.")
+		return
+	}
+
+	report, err = reconciliationService.Apply("")
+	if err != nil {
+		log.Fatal("Failed to apply reconciliation:", err)
+	}
+	fmt.Printf("\n✅ Repaired %d counters and recorded %d score ledger corrections.\n", len(report.Drifts), len(report.LedgerEntries))
+	fmt.Println("   Scoreboard caches flushed.")
+}
+
+func printReconciliation(report *models.ReconciliationReport) {
+	if len(report.Drifts) > 0 {
+		fmt.Println()
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "COUNTER\tCONTEST\tTARGET\tSTORED\tEXPECTED")
+		for _, d := range report.Drifts {
+			target := d.TargetID
+			if d.Name != "" {
+				target = fmt.Sprintf("%s (%s)", d.Name, d.TargetID)
+			}
+			contest := d.ContestID
+			if contest == "" {
+				contest = "-"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%d\n", d.Counter, contest, target, d.Stored, d.Expected)
+		}
+		w.Flush()
+	}
+
+	if len(report.LedgerEntries) > 0 {
+		points := 0
+		for _, e := range report.LedgerEntries {
+			points += e.Points
+		}
+		fmt.Printf("\nScore ledger: %d corrections totalling %+d points\n", len(report.LedgerEntries), points)
+	}
+}
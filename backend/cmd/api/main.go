@@ -6,10 +6,12 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"time"
 
 	"github.com/Uttam-Mahata/RootAccess/backend/internal/config"
 	"github.com/Uttam-Mahata/RootAccess/backend/internal/database"
 	"github.com/Uttam-Mahata/RootAccess/backend/internal/routes"
+	"github.com/Uttam-Mahata/RootAccess/backend/internal/services"
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/aws/aws-lambda-go/lambda"
 	ginadapter "github.com/awslabs/aws-lambda-go-api-proxy/gin"
@@ -39,6 +41,7 @@ import (
 var (
 	ginLambda *ginadapter.GinLambda
 	appConfig *config.Config
+	scheduler *services.ChallengeScheduler
 )
 
 func main() {
@@ -60,7 +63,8 @@ func main() {
 		"general":    cfg.RedisURLGeneral,
 	})
 
-	r := routes.SetupRouter(cfg)
+	r, challengeScheduler := routes.SetupRouter(cfg)
+	scheduler = challengeScheduler
 
 	// Check if running in AWS Lambda
 	if os.Getenv("AWS_LAMBDA_FUNCTION_NAME") != "" {
@@ -70,6 +74,11 @@ func main() {
 		return
 	}
 
+	// Long-running mode publishes scheduled challenges in the background
+	if cfg.SchedulerIntervalSeconds > 0 {
+		go scheduler.Start(context.Background(), time.Duration(cfg.SchedulerIntervalSeconds)*time.Second)
+	}
+
 	log.Printf("Server running on port %s", cfg.Port)
 	r.Run(":" + cfg.Port)
 }
@@ -84,7 +93,17 @@ func UnifiedHandler(ctx context.Context, event json.RawMessage) (interface{}, er
 		return ginLambda.ProxyWithContext(ctx, restReq)
 	}
 
-	// 2. Try to unmarshal as APIGatewayWebsocketProxyRequest (WebSocket)
+	// 2. EventBridge scheduled rule: run one challenge release pass
+	var scheduledEvent events.CloudWatchEvent
+	if err := json.Unmarshal(event, &scheduledEvent); err == nil && scheduledEvent.Source == "aws.events" {
+		released, err := scheduler.Tick(time.Now(), services.SchedulerActor)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]int{"released": len(released)}, nil
+	}
+
+	// 3. Try to unmarshal as APIGatewayWebsocketProxyRequest (WebSocket)
 	var wsReq events.APIGatewayWebsocketProxyRequest
 	if err := json.Unmarshal(event, &wsReq); err == nil && wsReq.RequestContext.ConnectionID != "" {
 		path := "/ws/default"
@@ -3,6 +3,7 @@ package database
 import (
 	"database/sql"
 	"log"
+	"strings"
 )
 
 func BootstrapSchema(db *sql.DB) {
@@ -85,7 +86,13 @@ func BootstrapSchema(db *sql.DB) {
 			contest_id TEXT REFERENCES contests(id) ON DELETE SET NULL,
 			official_writeup TEXT,
 			official_writeup_format TEXT,
-			official_writeup_published INTEGER NOT NULL DEFAULT 0
+			official_writeup_published INTEGER NOT NULL DEFAULT 0,
+			status TEXT NOT NULL DEFAULT 'draft',
+			author_id TEXT,
+			first_blood_bonus TEXT,
+			max_attempts INTEGER NOT NULL DEFAULT 0,
+			grading_mode TEXT NOT NULL DEFAULT 'auto',
+			flag_format TEXT
 		);`,
 		// Hints
 		`CREATE TABLE IF NOT EXISTS hints (
@@ -95,6 +102,14 @@ func BootstrapSchema(db *sql.DB) {
 			cost INTEGER NOT NULL,
 			display_order INTEGER NOT NULL
 		);`,
+		// Challenge Flags (accepted answers per challenge)
+		`CREATE TABLE IF NOT EXISTS challenge_flags (
+			id TEXT PRIMARY KEY,
+			challenge_id TEXT NOT NULL REFERENCES challenges(id) ON DELETE CASCADE,
+			flag_type TEXT NOT NULL,
+			value TEXT NOT NULL,
+			display_order INTEGER NOT NULL DEFAULT 0
+		);`,
 		// Hint Reveals
 		`CREATE TABLE IF NOT EXISTS hint_reveals (
 			id TEXT PRIMARY KEY,
@@ -102,7 +117,9 @@ func BootstrapSchema(db *sql.DB) {
 			challenge_id TEXT NOT NULL REFERENCES challenges(id) ON DELETE CASCADE,
 			user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
 			team_id TEXT REFERENCES teams(id) ON DELETE CASCADE,
-			cost INTEGER NOT NULL
+			cost INTEGER NOT NULL,
+			contest_id TEXT,
+			created_at TEXT
 		);`,
 		// Submissions
 		`CREATE TABLE IF NOT EXISTS submissions (
@@ -114,7 +131,11 @@ func BootstrapSchema(db *sql.DB) {
 			flag TEXT NOT NULL,
 			is_correct INTEGER NOT NULL,
 			ip_address TEXT,
-			timestamp TEXT NOT NULL
+			timestamp TEXT NOT NULL,
+			invalid_format INTEGER NOT NULL DEFAULT 0,
+			invalidated_at TEXT,
+			invalidated_by TEXT,
+			invalidation_reason TEXT
 		);`,
 		// Notifications
 		`CREATE TABLE IF NOT EXISTS notifications (
@@ -179,6 +200,134 @@ func BootstrapSchema(db *sql.DB) {
 			created_by TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
 			created_at TEXT NOT NULL
 		);`,
+		// Score Ledger (append-only record of every point-affecting event)
+		`CREATE TABLE IF NOT EXISTS score_ledger (
+			id TEXT PRIMARY KEY,
+			contest_id TEXT NOT NULL DEFAULT '',
+			team_id TEXT NOT NULL DEFAULT '',
+			user_id TEXT NOT NULL DEFAULT '',
+			challenge_id TEXT NOT NULL DEFAULT '',
+			kind TEXT NOT NULL,
+			points INTEGER NOT NULL,
+			actor_id TEXT NOT NULL DEFAULT '',
+			reference_id TEXT NOT NULL DEFAULT '',
+			reason TEXT NOT NULL DEFAULT '',
+			created_at TEXT NOT NULL
+		);`,
+		// Challenge Prerequisites (unlock rules)
+		`CREATE TABLE IF NOT EXISTS challenge_prerequisites (
+			id TEXT PRIMARY KEY,
+			challenge_id TEXT NOT NULL REFERENCES challenges(id) ON DELETE CASCADE,
+			prerequisite_type TEXT NOT NULL,
+			required_challenge_id TEXT REFERENCES challenges(id) ON DELETE CASCADE,
+			category TEXT,
+			required_count INTEGER NOT NULL DEFAULT 0
+		);`,
+		// Challenge Revisions (immutable edit history)
+		`CREATE TABLE IF NOT EXISTS challenge_revisions (
+			id TEXT PRIMARY KEY,
+			challenge_id TEXT NOT NULL REFERENCES challenges(id) ON DELETE CASCADE,
+			revision_number INTEGER NOT NULL,
+			snapshot TEXT NOT NULL,
+			author_id TEXT,
+			note TEXT,
+			created_at TEXT NOT NULL,
+			UNIQUE (challenge_id, revision_number)
+		);`,
+		// Challenge Reviews (workflow transitions and reviewer comments)
+		`CREATE TABLE IF NOT EXISTS challenge_reviews (
+			id TEXT PRIMARY KEY,
+			challenge_id TEXT NOT NULL REFERENCES challenges(id) ON DELETE CASCADE,
+			action TEXT NOT NULL,
+			from_status TEXT NOT NULL,
+			to_status TEXT NOT NULL,
+			actor_id TEXT,
+			comment TEXT,
+			created_at TEXT NOT NULL
+		);`,
+		// Challenge Feedback (solver ratings, one entry per user and challenge)
+		`CREATE TABLE IF NOT EXISTS challenge_feedback (
+			id TEXT PRIMARY KEY,
+			challenge_id TEXT NOT NULL REFERENCES challenges(id) ON DELETE CASCADE,
+			user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			team_id TEXT,
+			contest_id TEXT,
+			difficulty_rating INTEGER NOT NULL,
+			quality_rating INTEGER NOT NULL,
+			comment TEXT,
+			created_at TEXT NOT NULL,
+			updated_at TEXT NOT NULL,
+			UNIQUE (challenge_id, user_id)
+		);`,
+		// Attempt counter resets (wrong submissions before the latest reset no longer count)
+		`CREATE TABLE IF NOT EXISTS attempt_resets (
+			id TEXT PRIMARY KEY,
+			challenge_id TEXT NOT NULL REFERENCES challenges(id) ON DELETE CASCADE,
+			owner_id TEXT NOT NULL,
+			contest_id TEXT NOT NULL DEFAULT '',
+			reset_by TEXT,
+			created_at TEXT NOT NULL
+		);`,
+		// Answers to manually graded challenges (judging queue)
+		`CREATE TABLE IF NOT EXISTS manual_submissions (
+			id TEXT PRIMARY KEY,
+			challenge_id TEXT NOT NULL REFERENCES challenges(id) ON DELETE CASCADE,
+			user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			team_id TEXT,
+			contest_id TEXT NOT NULL DEFAULT '',
+			answer TEXT NOT NULL,
+			status TEXT NOT NULL DEFAULT 'pending',
+			points_awarded INTEGER NOT NULL DEFAULT 0,
+			feedback TEXT,
+			judged_by TEXT,
+			judged_at TEXT,
+			created_at TEXT NOT NULL
+		);`,
+		// Wrong submissions that were very close to an accepted flag (kind of difference only, no flag text)
+		`CREATE TABLE IF NOT EXISTS near_misses (
+			id TEXT PRIMARY KEY,
+			challenge_id TEXT NOT NULL REFERENCES challenges(id) ON DELETE CASCADE,
+			contest_id TEXT NOT NULL DEFAULT '',
+			submission_id TEXT,
+			user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			team_id TEXT,
+			flag_id TEXT,
+			kind TEXT NOT NULL,
+			distance INTEGER NOT NULL DEFAULT 0,
+			detail TEXT,
+			created_at TEXT NOT NULL
+		);`,
+		// Challenge Attachments (files kept in the storage provider)
+		`CREATE TABLE IF NOT EXISTS challenge_attachments (
+			id TEXT PRIMARY KEY,
+			challenge_id TEXT NOT NULL REFERENCES challenges(id) ON DELETE CASCADE,
+			filename TEXT NOT NULL,
+			storage_key TEXT NOT NULL,
+			content_type TEXT,
+			size INTEGER NOT NULL,
+			sha256 TEXT NOT NULL,
+			uploaded_by TEXT,
+			created_at TEXT NOT NULL
+		);`,
+		// Cheating Incidents
+		`CREATE TABLE IF NOT EXISTS cheating_incidents (
+			id TEXT PRIMARY KEY,
+			incident_type TEXT NOT NULL,
+			challenge_id TEXT NOT NULL REFERENCES challenges(id) ON DELETE CASCADE,
+			contest_id TEXT,
+			user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			team_id TEXT,
+			source_team_id TEXT,
+			source_user_id TEXT,
+			submission_id TEXT,
+			ip_address TEXT,
+			details TEXT,
+			status TEXT NOT NULL DEFAULT 'open',
+			reviewed_by TEXT,
+			review_note TEXT,
+			created_at TEXT NOT NULL,
+			reviewed_at TEXT
+		);`,
 		// Contest Config
 		`CREATE TABLE IF NOT EXISTS contest_config (
 			id TEXT PRIMARY KEY,
@@ -203,7 +352,12 @@ func BootstrapSchema(db *sql.DB) {
 			scoreboard_visibility TEXT NOT NULL,
 			is_active INTEGER NOT NULL DEFAULT 0,
 			created_at TEXT NOT NULL,
-			updated_at TEXT NOT NULL
+			updated_at TEXT NOT NULL,
+			flag_format TEXT,
+			scoring_type TEXT,
+			ranking_mode TEXT,
+			penalty_minutes INTEGER NOT NULL DEFAULT 0,
+			tie_breaker TEXT
 		);`,
 		// Contest Rounds
 		`CREATE TABLE IF NOT EXISTS contest_rounds (
@@ -241,6 +395,17 @@ func BootstrapSchema(db *sql.DB) {
 			solve_count INTEGER NOT NULL DEFAULT 0,
 			PRIMARY KEY (contest_id, challenge_id)
 		);`,
+		// Solves: the first valid correct submission of each team (or user without a team) on a
+		// challenge, per contest ('' outside contests). The primary key is what keeps teammates
+		// submitting at the same moment from counting the solve twice.
+		`CREATE TABLE IF NOT EXISTS solves (
+			challenge_id TEXT NOT NULL REFERENCES challenges(id) ON DELETE CASCADE,
+			contest_id TEXT NOT NULL DEFAULT '',
+			owner_id TEXT NOT NULL,
+			submission_id TEXT NOT NULL REFERENCES submissions(id) ON DELETE CASCADE,
+			solved_at TEXT NOT NULL,
+			PRIMARY KEY (challenge_id, contest_id, owner_id)
+		);`,
 		// User IP History
 		`CREATE TABLE IF NOT EXISTS user_ip_history (
 			user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
@@ -255,5 +420,57 @@ func BootstrapSchema(db *sql.DB) {
 			log.Fatalf("Failed to execute schema statement: %v\nQuery: %s", err, stmt)
 		}
 	}
+
+	// Columns added after the initial schema. CREATE TABLE IF NOT EXISTS does not touch
+	// existing tables, so older databases get them through ALTER TABLE.
+	columns := []struct {
+		table, column, definition string
+	}{
+		{"challenges", "status", "TEXT NOT NULL DEFAULT 'draft'"},
+		{"challenges", "author_id", "TEXT"},
+		{"challenges", "first_blood_bonus", "TEXT"},
+		{"challenges", "max_attempts", "INTEGER NOT NULL DEFAULT 0"},
+		{"challenges", "grading_mode", "TEXT NOT NULL DEFAULT 'auto'"},
+		{"challenges", "flag_format", "TEXT"},
+		{"cheating_incidents", "source_user_id", "TEXT"},
+		{"contests", "flag_format", "TEXT"},
+		{"contests", "scoring_type", "TEXT"},
+		{"contests", "ranking_mode", "TEXT"},
+		{"contests", "penalty_minutes", "INTEGER NOT NULL DEFAULT 0"},
+		{"contests", "tie_breaker", "TEXT"},
+		{"hint_reveals", "contest_id", "TEXT"},
+		{"hint_reveals", "created_at", "TEXT"},
+		{"submissions", "invalid_format", "INTEGER NOT NULL DEFAULT 0"},
+		{"submissions", "invalidated_at", "TEXT"},
+		{"submissions", "invalidated_by", "TEXT"},
+		{"submissions", "invalidation_reason", "TEXT"},
+	}
+	for _, col := range columns {
+		stmt := "ALTER TABLE " + col.table + " ADD COLUMN " + col.column + " " + col.definition
+		if _, err := db.Exec(stmt); err != nil && !strings.Contains(strings.ToLower(err.Error()), "duplicate column") {
+			log.Fatalf("Failed to add column %s.%s: %v", col.table, col.column, err)
+		}
+	}
+
+	// Data fixes that are safe to run on every start
+	backfills := []string{
+		// Challenges published before the review workflow existed keep their published state
+		`UPDATE challenges SET status = 'published' WHERE is_published = 1 AND status = 'draft'`,
+		// Solves made before the solves table existed, claimed by their earliest submission
+		`INSERT OR IGNORE INTO solves (challenge_id, contest_id, owner_id, submission_id, solved_at)
+			SELECT challenge_id, COALESCE(contest_id, ''),
+				CASE WHEN COALESCE(team_id, '') != '' THEN 'team:' || team_id ELSE user_id END,
+				id, timestamp
+			FROM submissions WHERE is_correct = 1 AND invalidated_at IS NULL
+			ORDER BY timestamp, rowid`,
+		// Ledger times recorded in local time before they were stored in UTC
+		`UPDATE score_ledger SET created_at = strftime('%Y-%m-%dT%H:%M:%SZ', created_at)
+			WHERE created_at NOT LIKE '%Z' AND strftime('%Y-%m-%dT%H:%M:%SZ', created_at) IS NOT NULL`,
+	}
+	for _, stmt := range backfills {
+		if _, err := db.Exec(stmt); err != nil {
+			log.Fatalf("Failed to execute schema backfill: %v\nQuery: %s", err, stmt)
+		}
+	}
 	log.Println("Database schema bootstrapped successfully with consistent relations")
 }
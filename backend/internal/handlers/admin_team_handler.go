@@ -1,13 +1,11 @@
 package handlers
 
 import (
-	"context"
 	"net/http"
-	"time"
 
-	"github.com/Uttam-Mahata/RootAccess/backend/internal/database"
 	"github.com/Uttam-Mahata/RootAccess/backend/internal/models"
 	"github.com/Uttam-Mahata/RootAccess/backend/internal/repositories"
+	"github.com/Uttam-Mahata/RootAccess/backend/internal/services"
 	"github.com/Uttam-Mahata/RootAccess/backend/internal/utils"
 	"github.com/gin-gonic/gin"
 )
@@ -17,7 +15,7 @@ type AdminTeamHandler struct {
 	userRepo       *repositories.UserRepository
 	submissionRepo *repositories.SubmissionRepository
 	invitationRepo *repositories.TeamInvitationRepository
-	adjustmentRepo *repositories.ScoreAdjustmentRepository
+	ledgerService  *services.ScoreLedgerService
 }
 
 func NewAdminTeamHandler(
@@ -25,14 +23,14 @@ func NewAdminTeamHandler(
 	userRepo *repositories.UserRepository,
 	submissionRepo *repositories.SubmissionRepository,
 	invitationRepo *repositories.TeamInvitationRepository,
-	adjustmentRepo *repositories.ScoreAdjustmentRepository,
+	ledgerService *services.ScoreLedgerService,
 ) *AdminTeamHandler {
 	return &AdminTeamHandler{
 		teamRepo:       teamRepo,
 		userRepo:       userRepo,
 		submissionRepo: submissionRepo,
 		invitationRepo: invitationRepo,
-		adjustmentRepo: adjustmentRepo,
+		ledgerService:  ledgerService,
 	}
 }
 
@@ -96,39 +94,46 @@ func (h *AdminTeamHandler) AdjustTeamScore(c *gin.Context) {
 		return
 	}
 
-	// Update the team's stored score so admin views and analytics see it.
-	if err := h.teamRepo.UpdateTeamScore(teamID, req.Delta); err != nil {
+	// The adjustment is recorded in the score ledger, which also updates the team's stored score
+	adj := &models.ScoreAdjustment{
+		TargetType: models.ScoreAdjustmentTargetTeam,
+		TargetID:   team.ID,
+		Delta:      req.Delta,
+		Reason:     req.Reason,
+		CreatedBy:  c.GetString("user_id"),
+	}
+	if err := h.ledgerService.RecordAdjustment(adj); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update team score"})
 		return
 	}
 
-	// Record a score adjustment so scoreboard/analytics can apply it
-	if h.adjustmentRepo != nil {
-		adminIDStr, _ := c.Get("user_id")
-		adminIDHex, _ := adminIDStr.(string)
-		adminID := adminIDHex
+	c.JSON(http.StatusOK, gin.H{"message": "Team score adjusted successfully"})
+}
 
-		adj := &models.ScoreAdjustment{
-			TargetType: models.ScoreAdjustmentTargetTeam,
-			TargetID:   team.ID,
-			Delta:      req.Delta,
-			Reason:     req.Reason,
-			CreatedBy:  adminID,
-		}
-		_ = h.adjustmentRepo.Create(adj)
+// GetTeamLedger explains a team's score entry by entry
+// @Summary Get a team's score ledger
+// @Description List every ledger entry behind a team's score, oldest first, with the running balance. With contest_id, only the entries counting in that contest.
+// @Tags Admin Teams
+// @Produce json
+// @Param id path string true "Team ID"
+// @Param contest_id query string false "Contest ID"
+// @Success 200 {object} models.LedgerStatement
+// @Failure 404 {object} map[string]string
+// @Security ApiKeyAuth
+// @Router /admin/teams/{id}/ledger [get]
+func (h *AdminTeamHandler) GetTeamLedger(c *gin.Context) {
+	team, err := h.teamRepo.FindTeamByID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Team not found"})
+		return
 	}
 
-	// Clear relevant scoreboard caches to reflect changes quickly
-	if database.Registry != nil && database.Registry.Scoreboard != nil {
-		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
-		defer cancel()
-		_ = database.Registry.Scoreboard.Del(ctx,
-			"team_scoreboard",
-			"team_scoreboard_frozen",
-		).Err()
+	statement, err := h.ledgerService.TeamStatement(team.ID, c.Query("contest_id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, err.Error(), err)
+		return
 	}
-
-	c.JSON(http.StatusOK, gin.H{"message": "Team score adjusted successfully"})
+	c.JSON(http.StatusOK, statement)
 }
 
 // TeamMemberInfo represents a team member's info
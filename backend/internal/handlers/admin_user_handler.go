@@ -5,6 +5,7 @@ import (
 
 	"github.com/Uttam-Mahata/RootAccess/backend/internal/models"
 	"github.com/Uttam-Mahata/RootAccess/backend/internal/repositories"
+	"github.com/Uttam-Mahata/RootAccess/backend/internal/services"
 	"github.com/Uttam-Mahata/RootAccess/backend/internal/utils"
 	"github.com/gin-gonic/gin"
 )
@@ -13,19 +14,19 @@ type AdminUserHandler struct {
 	userRepo       *repositories.UserRepository
 	teamRepo       *repositories.TeamRepository
 	submissionRepo *repositories.SubmissionRepository
-	adjustmentRepo *repositories.ScoreAdjustmentRepository
+	ledgerService  *services.ScoreLedgerService
 }
 
 func NewAdminUserHandler(userRepo *repositories.UserRepository) *AdminUserHandler {
 	return &AdminUserHandler{userRepo: userRepo}
 }
 
-func NewAdminUserHandlerWithRepos(userRepo *repositories.UserRepository, teamRepo *repositories.TeamRepository, submissionRepo *repositories.SubmissionRepository, adjustmentRepo *repositories.ScoreAdjustmentRepository) *AdminUserHandler {
+func NewAdminUserHandlerWithRepos(userRepo *repositories.UserRepository, teamRepo *repositories.TeamRepository, submissionRepo *repositories.SubmissionRepository, ledgerService *services.ScoreLedgerService) *AdminUserHandler {
 	return &AdminUserHandler{
 		userRepo:       userRepo,
 		teamRepo:       teamRepo,
 		submissionRepo: submissionRepo,
-		adjustmentRepo: adjustmentRepo,
+		ledgerService:  ledgerService,
 	}
 }
 
@@ -202,7 +203,7 @@ type UpdateUserRoleRequest struct {
 
 // UpdateUserRole updates user's role
 // @Summary Update user role
-// @Description Change a user's role (admin or user). Admins cannot change their own role.
+// @Description Change a user's role (admin, author or user). Admins cannot change their own role.
 // @Tags Admin Users
 // @Accept json
 // @Produce json
@@ -232,8 +233,8 @@ func (h *AdminUserHandler) UpdateUserRole(c *gin.Context) {
 		utils.RespondWithError(c, http.StatusBadRequest, err.Error(), err)
 		return
 	}
-	if req.Role != "admin" && req.Role != "user" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid role. Must be 'admin' or 'user'"})
+	if !models.IsValidRole(req.Role) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid role. Must be 'admin', 'author' or 'user'"})
 		return
 	}
 	err := h.userRepo.UpdateFields(objID, map[string]interface{}{"role": req.Role})
@@ -266,7 +267,7 @@ type AdjustUserScoreRequest struct {
 // @Security ApiKeyAuth
 // @Router /admin/users/{id}/score-adjust [post]
 func (h *AdminUserHandler) AdjustUserScore(c *gin.Context) {
-	if h.adjustmentRepo == nil {
+	if h.ledgerService == nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Score adjustments not available"})
 		return
 	}
@@ -305,14 +306,11 @@ func (h *AdminUserHandler) AdjustUserScore(c *gin.Context) {
 		Reason:     req.Reason,
 		CreatedBy:  adminID,
 	}
-	if err := h.adjustmentRepo.Create(adj); err != nil {
+	if err := h.ledgerService.RecordAdjustment(adj); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record score adjustment"})
 		return
 	}
 
-	// No need to touch Redis here; user scoreboard cache key is "scoreboard"
-	// and expires quickly, and this adjustment is picked up on next recompute.
-
 	c.JSON(http.StatusOK, gin.H{"message": "User score adjusted successfully"})
 }
 
@@ -33,3 +33,116 @@ func (h *AnalyticsHandler) GetPlatformAnalytics(c *gin.Context) {
 	}
 	c.JSON(http.StatusOK, analytics)
 }
+
+// GetFeedbackStats returns solver feedback aggregated per challenge (admin only)
+// @Summary Get challenge feedback statistics
+// @Description Average difficulty and quality ratings per challenge, next to the official difficulty.
+// @Tags Analytics
+// @Produce json
+// @Param contest_id query string false "Only count feedback from solves in this contest"
+// @Success 200 {array} models.ChallengeFeedbackStats
+// @Failure 403 {object} map[string]string
+// @Security ApiKeyAuth
+// @Router /admin/analytics/feedback [get]
+func (h *AnalyticsHandler) GetFeedbackStats(c *gin.Context) {
+	stats, err := h.analyticsService.GetFeedbackStats(c.Query("contest_id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, err.Error(), err)
+		return
+	}
+	c.JSON(http.StatusOK, stats)
+}
+
+// GetChallengeFeedback returns the feedback summary and comments of one challenge (admin only)
+func (h *AnalyticsHandler) GetChallengeFeedback(c *gin.Context) {
+	stats, feedback, err := h.analyticsService.GetChallengeFeedback(c.Param("id"))
+	if err != nil {
+		if err.Error() == "challenge not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Challenge not found"})
+			return
+		}
+		utils.RespondWithError(c, http.StatusInternalServerError, err.Error(), err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"stats":    stats,
+		"feedback": feedback,
+	})
+}
+
+// GetContestFeedbackStats returns solver feedback for a contest, overall and per challenge (admin only)
+func (h *AnalyticsHandler) GetContestFeedbackStats(c *gin.Context) {
+	stats, err := h.analyticsService.GetContestFeedbackStats(c.Param("id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, err.Error(), err)
+		return
+	}
+	c.JSON(http.StatusOK, stats)
+}
+
+// GetChallengeStats returns attempt and solve figures for one challenge (admins and its author)
+// @Summary Get challenge solve stats
+// @Description Attempts, wrong submissions, distinct solvers, first and latest solve times and solver feedback for a challenge.
+// @Tags Analytics
+// @Produce json
+// @Param id path string true "Challenge ID"
+// @Success 200 {object} models.ChallengeSolveStats
+// @Failure 404 {object} map[string]string
+// @Security ApiKeyAuth
+// @Router /admin/challenges/{id}/stats [get]
+func (h *AnalyticsHandler) GetChallengeStats(c *gin.Context) {
+	stats, err := h.analyticsService.GetChallengeStats(c.Param("id"))
+	if err != nil {
+		if err.Error() == "challenge not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Challenge not found"})
+			return
+		}
+		utils.RespondWithError(c, http.StatusInternalServerError, err.Error(), err)
+		return
+	}
+	c.JSON(http.StatusOK, stats)
+}
+
+// GetNearMisses lists challenges by the number of wrong flags that were very close to an
+// accepted one, which usually points at an ambiguity or a typo in the challenge (admin only)
+// @Summary Get near-miss overview
+// @Description Challenges with near-miss submissions (case, whitespace, wrapper or one-character differences from an accepted flag), most first.
+// @Tags Analytics
+// @Produce json
+// @Param contest_id query string false "Only count near misses in this contest"
+// @Success 200 {array} models.NearMissSummary
+// @Failure 403 {object} map[string]string
+// @Security ApiKeyAuth
+// @Router /admin/analytics/near-misses [get]
+func (h *AnalyticsHandler) GetNearMisses(c *gin.Context) {
+	summary, err := h.analyticsService.GetNearMissSummary(c.Query("contest_id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, err.Error(), err)
+		return
+	}
+	c.JSON(http.StatusOK, summary)
+}
+
+// GetChallengeNearMisses returns the near-miss report of one challenge (admin only)
+// @Summary Get challenge near-miss report
+// @Description Every near miss on a challenge, newest first, with counts by kind, accepted flag and difference. Submitted flags are never stored, only how they differed.
+// @Tags Analytics
+// @Produce json
+// @Param id path string true "Challenge ID"
+// @Param contest_id query string false "Only include near misses in this contest"
+// @Success 200 {object} models.NearMissReport
+// @Failure 404 {object} map[string]string
+// @Security ApiKeyAuth
+// @Router /admin/analytics/near-misses/challenges/{id} [get]
+func (h *AnalyticsHandler) GetChallengeNearMisses(c *gin.Context) {
+	report, err := h.analyticsService.GetNearMissReport(c.Param("id"), c.Query("contest_id"))
+	if err != nil {
+		if err.Error() == "challenge not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Challenge not found"})
+			return
+		}
+		utils.RespondWithError(c, http.StatusInternalServerError, err.Error(), err)
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
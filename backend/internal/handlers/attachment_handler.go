@@ -0,0 +1,204 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/Uttam-Mahata/RootAccess/backend/internal/models"
+	"github.com/Uttam-Mahata/RootAccess/backend/internal/repositories"
+	"github.com/Uttam-Mahata/RootAccess/backend/internal/services"
+	"github.com/Uttam-Mahata/RootAccess/backend/internal/storage"
+	"github.com/Uttam-Mahata/RootAccess/backend/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// multipartOverhead is the room left in an upload request for multipart headers and
+// boundaries on top of the file itself
+const multipartOverhead = 1 << 20
+
+type AttachmentHandler struct {
+	attachmentService   *services.AttachmentService
+	contestAdminService *services.ContestAdminService
+	teamRepo            *repositories.TeamRepository
+	localProvider       *storage.LocalProvider // set only when files are stored locally
+}
+
+func NewAttachmentHandler(attachmentService *services.AttachmentService, contestAdminService *services.ContestAdminService, teamRepo *repositories.TeamRepository, localProvider *storage.LocalProvider) *AttachmentHandler {
+	return &AttachmentHandler{
+		attachmentService:   attachmentService,
+		contestAdminService: contestAdminService,
+		teamRepo:            teamRepo,
+		localProvider:       localProvider,
+	}
+}
+
+// canAccessChallenge applies the same visibility rules as the challenge endpoints (admins see everything)
+func (h *AttachmentHandler) canAccessChallenge(c *gin.Context, challengeID string) bool {
+	if role, _ := c.Get("role"); role == models.RoleAdmin || h.contestAdminService == nil {
+		return true
+	}
+	var teamID *string
+	if userIDStr, exists := c.Get("user_id"); exists && h.teamRepo != nil {
+		if team, err := h.teamRepo.FindTeamByMemberID(userIDStr.(string)); err == nil && team != nil {
+			teamID = &team.ID
+		}
+	}
+	visible, err := h.contestAdminService.IsChallengeVisible(challengeID, time.Now(), teamID)
+	return err == nil && visible
+}
+
+// UploadAttachment stores a file uploaded as multipart form field "file" (admin only)
+// @Summary Upload challenge attachment
+// @Description Upload a file for a challenge. The SHA-256 checksum and size are recorded.
+// @Tags Admin
+// @Accept multipart/form-data
+// @Produce json
+// @Param id path string true "Challenge ID"
+// @Param file formData file true "Attachment"
+// @Success 201 {object} models.ChallengeAttachment
+// @Failure 400 {object} map[string]string
+// @Failure 413 {object} map[string]string
+// @Security ApiKeyAuth
+// @Router /admin/challenges/{id}/attachments [post]
+func (h *AttachmentHandler) UploadAttachment(c *gin.Context) {
+	maxSize := h.attachmentService.MaxSize()
+	if maxSize > 0 {
+		// Stop reading the request once it cannot hold an acceptable file
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxSize+multipartOverhead)
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "File is too large"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A file must be provided in the 'file' form field"})
+		return
+	}
+	if maxSize > 0 && fileHeader.Size > maxSize {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "File is too large"})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, "Could not read uploaded file", err)
+		return
+	}
+	defer file.Close()
+
+	userID, _ := c.Get("user_id")
+	uploadedBy, _ := userID.(string)
+	attachment, err := h.attachmentService.Upload(c.Request.Context(), c.Param("id"), fileHeader.Filename, fileHeader.Header.Get("Content-Type"), file, uploadedBy)
+	if err != nil {
+		if errors.Is(err, services.ErrAttachmentTooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "File is too large"})
+			return
+		}
+		if err.Error() == "challenge not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Challenge not found"})
+			return
+		}
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, attachment)
+}
+
+// DeleteAttachment removes an attachment and its stored file (admin only)
+func (h *AttachmentHandler) DeleteAttachment(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	actorID, _ := userID.(string)
+	if err := h.attachmentService.DeleteAttachment(c.Request.Context(), c.Param("id"), c.Param("attachmentId"), actorID); err != nil {
+		if err.Error() == "attachment not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		utils.RespondWithError(c, http.StatusInternalServerError, err.Error(), err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Attachment deleted"})
+}
+
+// GetAttachments lists a challenge's attachments with their checksums and sizes
+func (h *AttachmentHandler) GetAttachments(c *gin.Context) {
+	challengeID := c.Param("id")
+	if !h.canAccessChallenge(c, challengeID) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Challenge not found"})
+		return
+	}
+
+	attachments, err := h.attachmentService.GetAttachments(challengeID)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, err.Error(), err)
+		return
+	}
+	c.JSON(http.StatusOK, attachments)
+}
+
+// GetDownloadURL returns a time-limited signed download URL for an attachment
+// @Summary Get attachment download URL
+// @Description Returns a signed URL that expires after a few minutes. Only available while the challenge is visible to the caller's team.
+// @Tags Challenges
+// @Produce json
+// @Param id path string true "Challenge ID"
+// @Param attachmentId path string true "Attachment ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]string
+// @Security ApiKeyAuth
+// @Router /challenges/{id}/attachments/{attachmentId}/download [get]
+func (h *AttachmentHandler) GetDownloadURL(c *gin.Context) {
+	challengeID := c.Param("id")
+	if !h.canAccessChallenge(c, challengeID) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Challenge not found"})
+		return
+	}
+
+	attachment, err := h.attachmentService.GetAttachment(challengeID, c.Param("attachmentId"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	url, expiresAt, err := h.attachmentService.GetDownloadURL(c.Request.Context(), attachment)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, err.Error(), err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"url":        url,
+		"expires_at": expiresAt,
+		"filename":   attachment.Filename,
+		"size":       attachment.Size,
+		"sha256":     attachment.SHA256,
+	})
+}
+
+// ServeLocalFile serves a file from local storage after checking the URL signature
+func (h *AttachmentHandler) ServeLocalFile(c *gin.Context) {
+	if h.localProvider == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Not found"})
+		return
+	}
+
+	filename := strings.TrimPrefix(c.Param("filename"), "/")
+
+	fullPath, err := h.localProvider.Open(c.Param("bucket"), filename, c.Query("expires"), c.Query("signature"), time.Now())
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "File not found"})
+			return
+		}
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.FileAttachment(fullPath, path.Base(filename))
+}
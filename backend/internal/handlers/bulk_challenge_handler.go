@@ -19,17 +19,18 @@ func NewBulkChallengeHandler(challengeService *services.ChallengeService) *BulkC
 }
 
 type BulkChallengeImport struct {
-	Title       string   `json:"title"`
-	Description string   `json:"description"`
-	Category    string   `json:"category"`
-	Difficulty  string   `json:"difficulty"`
-	MaxPoints   int      `json:"max_points"`
-	MinPoints   int      `json:"min_points"`
-	Decay       int      `json:"decay"`
-	ScoringType string   `json:"scoring_type"`
-	Flag        string   `json:"flag"`
-	Files       []string `json:"files"`
-	Tags        []string `json:"tags"`
+	Title       string        `json:"title"`
+	Description string        `json:"description"`
+	Category    string        `json:"category"`
+	Difficulty  string        `json:"difficulty"`
+	MaxPoints   int           `json:"max_points"`
+	MinPoints   int           `json:"min_points"`
+	Decay       int           `json:"decay"`
+	ScoringType string        `json:"scoring_type"`
+	Flag        string        `json:"flag"`
+	Flags       []FlagRequest `json:"flags"`
+	Files       []string      `json:"files"`
+	Tags        []string      `json:"tags"`
 }
 
 type ExportChallenge struct {
@@ -66,7 +67,7 @@ func (h *BulkChallengeHandler) ImportChallenges(c *gin.Context) {
 	imported := 0
 	var errors []string
 	for i, ch := range challenges {
-		if ch.Title == "" || ch.Flag == "" {
+		if ch.Title == "" || (ch.Flag == "" && len(ch.Flags) == 0) {
 			errors = append(errors, fmt.Sprintf("Challenge %d: missing title or flag", i))
 			continue
 		}
@@ -82,10 +83,19 @@ func (h *BulkChallengeHandler) ImportChallenges(c *gin.Context) {
 			errors = append(errors, fmt.Sprintf("Challenge %d (%s): min_points cannot exceed max_points", i, ch.Title))
 			continue
 		}
+		flags, err := buildChallengeFlags(ch.Flag, ch.Flags)
+		if err != nil {
+			errors = append(errors, fmt.Sprintf("Challenge %d (%s): %s", i, ch.Title, err.Error()))
+			continue
+		}
 		scoringType := ch.ScoringType
 		if scoringType == "" {
 			scoringType = models.ScoringDynamic
 		}
+		if !models.IsValidScoringType(scoringType) {
+			errors = append(errors, fmt.Sprintf("Challenge %d (%s): invalid scoring_type %q", i, ch.Title, scoringType))
+			continue
+		}
 		challenge := &models.Challenge{
 			Title:       ch.Title,
 			Description: ch.Description,
@@ -95,12 +105,12 @@ func (h *BulkChallengeHandler) ImportChallenges(c *gin.Context) {
 			MinPoints:   ch.MinPoints,
 			Decay:       ch.Decay,
 			ScoringType: scoringType,
-			FlagHash:    utils.HashFlag(ch.Flag),
+			FlagHash:    primaryFlagHash(flags),
+			Flags:       flags,
 			Files:       ch.Files,
 			Tags:        ch.Tags,
-			IsPublished: true,
 		}
-		if err := h.challengeService.CreateChallenge(challenge); err != nil {
+		if err := h.challengeService.CreateChallenge(challenge, c.GetString("user_id")); err != nil {
 			errors = append(errors, fmt.Sprintf("Challenge %d (%s): %s", i, ch.Title, err.Error()))
 			continue
 		}
@@ -178,16 +188,29 @@ func (h *BulkChallengeHandler) DuplicateChallenge(c *gin.Context) {
 		Decay:             original.Decay,
 		ScoringType:       original.ScoringType,
 		FlagHash:          original.FlagHash,
+		Flags:             duplicateFlags(original.Flags),
 		Files:             original.Files,
 		Tags:              original.Tags,
 		Hints:             original.Hints,
-		IsPublished:       original.IsPublished,
+		FirstBloodBonus:   original.FirstBloodBonus,
+		MaxAttempts:       original.MaxAttempts,
+		GradingMode:       original.GradingMode,
+		FlagFormat:        original.FlagFormat,
 	}
 
-	if err := h.challengeService.CreateChallenge(duplicate); err != nil {
+	if err := h.challengeService.CreateChallenge(duplicate, c.GetString("user_id")); err != nil {
 		utils.RespondWithError(c, http.StatusInternalServerError, err.Error(), err)
 		return
 	}
 
 	c.JSON(http.StatusCreated, gin.H{"message": "Challenge duplicated successfully"})
 }
+
+// duplicateFlags copies flag entries without their IDs so the copy gets fresh rows
+func duplicateFlags(flags []models.ChallengeFlag) []models.ChallengeFlag {
+	result := make([]models.ChallengeFlag, 0, len(flags))
+	for _, f := range flags {
+		result = append(result, models.ChallengeFlag{Type: f.Type, Value: f.Value})
+	}
+	return result
+}
@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Uttam-Mahata/RootAccess/backend/internal/services"
+	"github.com/Uttam-Mahata/RootAccess/backend/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type ChallengeFeedbackHandler struct {
+	feedbackService *services.ChallengeFeedbackService
+}
+
+func NewChallengeFeedbackHandler(feedbackService *services.ChallengeFeedbackService) *ChallengeFeedbackHandler {
+	return &ChallengeFeedbackHandler{
+		feedbackService: feedbackService,
+	}
+}
+
+type SubmitFeedbackRequest struct {
+	DifficultyRating int    `json:"difficulty_rating" binding:"required"` // 1 (trivial) to 5 (very hard)
+	QualityRating    int    `json:"quality_rating" binding:"required"`    // 1 (poor) to 5 (excellent)
+	Comment          string `json:"comment"`
+}
+
+// SubmitFeedback rates a solved challenge
+// @Summary Submit challenge feedback
+// @Description Rate the difficulty and quality of a challenge your team has solved. Submitting again replaces your earlier feedback.
+// @Tags Challenges
+// @Accept json
+// @Produce json
+// @Param id path string true "Challenge ID"
+// @Param request body SubmitFeedbackRequest true "Ratings and comment"
+// @Success 200 {object} models.ChallengeFeedback
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Security ApiKeyAuth
+// @Router /challenges/{id}/feedback [post]
+func (h *ChallengeFeedbackHandler) SubmitFeedback(c *gin.Context) {
+	var req SubmitFeedbackRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	feedback, err := h.feedbackService.SubmitFeedback(c.GetString("user_id"), c.Param("id"), req.DifficultyRating, req.QualityRating, req.Comment)
+	if err != nil {
+		switch err.Error() {
+		case "challenge not found":
+			c.JSON(http.StatusNotFound, gin.H{"error": "Challenge not found"})
+		case "only solvers can leave feedback on a challenge":
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, feedback)
+}
+
+// GetMyFeedback returns the current user's feedback on a challenge
+func (h *ChallengeFeedbackHandler) GetMyFeedback(c *gin.Context) {
+	feedback, err := h.feedbackService.GetUserFeedback(c.GetString("user_id"), c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, feedback)
+}
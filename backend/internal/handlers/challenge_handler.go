@@ -1,9 +1,16 @@
 package handlers
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/Uttam-Mahata/RootAccess/backend/internal/middleware"
 	"github.com/Uttam-Mahata/RootAccess/backend/internal/models"
 	"github.com/Uttam-Mahata/RootAccess/backend/internal/repositories"
 	"github.com/Uttam-Mahata/RootAccess/backend/internal/services"
@@ -49,36 +56,50 @@ func NewChallengeHandlerWithRepos(challengeService *services.ChallengeService, a
 }
 
 type CreateChallengeRequest struct {
-	Title             string        `json:"title" binding:"required"`
-	Description       string        `json:"description" binding:"required"`
-	DescriptionFormat string        `json:"description_format"` // "markdown" or "html"
-	Category          string        `json:"category" binding:"required"`
-	Difficulty        string        `json:"difficulty" binding:"required"`
-	MaxPoints         int           `json:"max_points" binding:"required"`
-	MinPoints         int           `json:"min_points" binding:"required"`
-	Decay             int           `json:"decay" binding:"required"`
-	ScoringType       string        `json:"scoring_type"`
-	Flag              string        `json:"flag" binding:"required"`
-	Files             []string      `json:"files"`
-	Tags              []string      `json:"tags"`
-	Hints             []HintRequest `json:"hints"`
+	Title             string                `json:"title" binding:"required"`
+	Description       string                `json:"description" binding:"required"`
+	DescriptionFormat string                `json:"description_format"` // "markdown" or "html"
+	Category          string                `json:"category" binding:"required"`
+	Difficulty        string                `json:"difficulty" binding:"required"`
+	MaxPoints         int                   `json:"max_points" binding:"required"`
+	MinPoints         int                   `json:"min_points" binding:"required"`
+	Decay             int                   `json:"decay" binding:"required"`
+	ScoringType       string                `json:"scoring_type"`
+	Flag              string                `json:"flag"`  // single static flag (legacy)
+	Flags             []FlagRequest         `json:"flags"` // either flag or flags is required
+	Files             []string              `json:"files"`
+	Tags              []string              `json:"tags"`
+	Hints             []HintRequest         `json:"hints"`
+	Prerequisites     []PrerequisiteRequest `json:"prerequisites"`
+	FirstBloodBonus   []int                 `json:"first_blood_bonus"` // bonus percentage per solver place, e.g. [15, 10, 5]
+	MaxAttempts       int                   `json:"max_attempts"`      // wrong submissions allowed per team per contest, 0 for unlimited
+	GradingMode       string                `json:"grading_mode"`      // "auto" (default) or "manual"; manual challenges need no flag
+	FlagFormat        string                `json:"flag_format"`       // regex overriding the contest's flag format
+	ScheduledAt       string                `json:"scheduled_at"`      // RFC3339; once approved the challenge is released at this time
 }
 
 // UpdateChallengeRequest is used for updating challenges – flag is optional
 type UpdateChallengeRequest struct {
-	Title             string        `json:"title" binding:"required"`
-	Description       string        `json:"description" binding:"required"`
-	DescriptionFormat string        `json:"description_format"`
-	Category          string        `json:"category" binding:"required"`
-	Difficulty        string        `json:"difficulty" binding:"required"`
-	MaxPoints         int           `json:"max_points" binding:"required"`
-	MinPoints         int           `json:"min_points" binding:"required"`
-	Decay             int           `json:"decay" binding:"required"`
-	ScoringType       string        `json:"scoring_type"`
-	Flag              string        `json:"flag"` // optional on update
-	Files             []string      `json:"files"`
-	Tags              []string      `json:"tags"`
-	Hints             []HintRequest `json:"hints"`
+	Title             string                `json:"title" binding:"required"`
+	Description       string                `json:"description" binding:"required"`
+	DescriptionFormat string                `json:"description_format"`
+	Category          string                `json:"category" binding:"required"`
+	Difficulty        string                `json:"difficulty" binding:"required"`
+	MaxPoints         int                   `json:"max_points" binding:"required"`
+	MinPoints         int                   `json:"min_points" binding:"required"`
+	Decay             int                   `json:"decay" binding:"required"`
+	ScoringType       string                `json:"scoring_type"`
+	Flag              string                `json:"flag"`  // optional on update
+	Flags             []FlagRequest         `json:"flags"` // optional on update, replaces all flags when set
+	Files             []string              `json:"files"`
+	Tags              []string              `json:"tags"`
+	Hints             []HintRequest         `json:"hints"`
+	Prerequisites     []PrerequisiteRequest `json:"prerequisites"`     // omitted keeps existing rules, [] clears them
+	FirstBloodBonus   []int                 `json:"first_blood_bonus"` // omitted keeps the schedule, [] clears it
+	MaxAttempts       *int                  `json:"max_attempts"`      // omitted keeps the cap, 0 removes it
+	GradingMode       string                `json:"grading_mode"`      // omitted keeps the grading mode
+	FlagFormat        *string               `json:"flag_format"`       // omitted keeps the override, "" falls back to the contest's format
+	ScheduledAt       *string               `json:"scheduled_at"`      // omitted keeps the schedule, "" clears it
 }
 
 // HintRequest represents a hint in the create/update challenge request
@@ -88,6 +109,107 @@ type HintRequest struct {
 	Order   int    `json:"order"`
 }
 
+// FlagRequest represents an accepted flag in the create/update challenge request
+type FlagRequest struct {
+	Type  string `json:"type"` // "static" (default), "case_insensitive", "regex" or "dynamic"
+	Value string `json:"value" binding:"required"`
+}
+
+// resolveSchedule normalizes a scheduled_at value to UTC RFC3339. Publishing itself goes
+// through the review workflow; the scheduler releases approved challenges once the time passes.
+func resolveSchedule(raw string) (string, error) {
+	if raw == "" {
+		return "", nil
+	}
+	t, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return "", fmt.Errorf("scheduled_at must be an RFC3339 timestamp")
+	}
+	return t.UTC().Format(time.RFC3339), nil
+}
+
+// PrerequisiteRequest represents an unlock rule in the create/update challenge request
+type PrerequisiteRequest struct {
+	Type        string `json:"type" binding:"required"` // "challenge" or "category"
+	ChallengeID string `json:"challenge_id"`            // required challenge for type "challenge"
+	Category    string `json:"category"`                // category for type "category"
+	Count       int    `json:"count"`                   // solves needed in the category
+}
+
+func buildPrerequisites(reqs []PrerequisiteRequest) []models.ChallengePrerequisite {
+	prereqs := make([]models.ChallengePrerequisite, 0, len(reqs))
+	for _, p := range reqs {
+		prereqs = append(prereqs, models.ChallengePrerequisite{
+			Type:                p.Type,
+			RequiredChallengeID: p.ChallengeID,
+			Category:            p.Category,
+			Count:               p.Count,
+		})
+	}
+	return prereqs
+}
+
+// dynamicFlagBasePattern restricts dynamic flag bases so the generated flag stays unambiguous
+var dynamicFlagBasePattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// buildChallengeFlags turns the legacy single flag plus the flag list into stored flag entries.
+// Static and case-insensitive values are hashed; regex patterns are validated and kept as-is.
+// Dynamic flags store the base that each team's RA{base_<hmac>} flag is derived from.
+func buildChallengeFlags(legacyFlag string, reqs []FlagRequest) ([]models.ChallengeFlag, error) {
+	var flags []models.ChallengeFlag
+	if legacyFlag != "" {
+		flags = append(flags, models.ChallengeFlag{
+			Type:  models.FlagTypeStatic,
+			Value: utils.HashFlag(legacyFlag),
+		})
+	}
+
+	for i, f := range reqs {
+		flagType := f.Type
+		if flagType == "" {
+			flagType = models.FlagTypeStatic
+		}
+		if !models.IsValidFlagType(flagType) {
+			return nil, fmt.Errorf("flag %d: type must be 'static', 'case_insensitive', 'regex' or 'dynamic'", i)
+		}
+		if f.Value == "" {
+			return nil, fmt.Errorf("flag %d: value is required", i)
+		}
+
+		value := f.Value
+		switch flagType {
+		case models.FlagTypeStatic:
+			value = utils.HashFlag(f.Value)
+		case models.FlagTypeCaseInsensitive:
+			value = utils.HashFlagCaseInsensitive(f.Value)
+		case models.FlagTypeRegex:
+			if _, err := utils.CompileFlagPattern(f.Value); err != nil {
+				return nil, fmt.Errorf("flag %d: invalid regex: %v", i, err)
+			}
+		case models.FlagTypeDynamic:
+			if !dynamicFlagBasePattern.MatchString(f.Value) {
+				return nil, fmt.Errorf("flag %d: dynamic flag base may only contain letters, digits, '_' and '-'", i)
+			}
+		}
+
+		flags = append(flags, models.ChallengeFlag{
+			Type:  flagType,
+			Value: value,
+		})
+	}
+	return flags, nil
+}
+
+// primaryFlagHash returns the hash kept in the legacy flag_hash column (first static flag, if any)
+func primaryFlagHash(flags []models.ChallengeFlag) string {
+	for _, f := range flags {
+		if f.Type == models.FlagTypeStatic {
+			return f.Value
+		}
+	}
+	return ""
+}
+
 func (h *ChallengeHandler) CreateChallenge(c *gin.Context) {
 	var req CreateChallengeRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -105,14 +227,57 @@ func (h *ChallengeHandler) CreateChallenge(c *gin.Context) {
 		return
 	}
 
-	// Hash the flag before storing
-	flagHash := utils.HashFlag(req.Flag)
+	gradingMode := req.GradingMode
+	if gradingMode == "" {
+		gradingMode = models.GradingAuto
+	}
+	if !models.IsValidGradingMode(gradingMode) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "grading_mode must be 'auto' or 'manual'"})
+		return
+	}
+
+	if gradingMode == models.GradingAuto && req.Flag == "" && len(req.Flags) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "At least one flag is required"})
+		return
+	}
+
+	// Hash static flags before storing
+	flags, err := buildChallengeFlags(req.Flag, req.Flags)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	scheduledAt, err := resolveSchedule(req.ScheduledAt)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := models.ValidateFirstBloodBonus(req.FirstBloodBonus); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := models.ValidateMaxAttempts(req.MaxAttempts); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := models.ValidateFlagFormat(req.FlagFormat); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
 	// Set default scoring type
 	scoringType := req.ScoringType
 	if scoringType == "" {
 		scoringType = models.ScoringDynamic
 	}
+	if !models.IsValidScoringType(scoringType) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid scoring_type"})
+		return
+	}
 
 	// Build hints
 	var hints []models.Hint
@@ -139,14 +304,25 @@ func (h *ChallengeHandler) CreateChallenge(c *gin.Context) {
 		MinPoints:         req.MinPoints,
 		Decay:             req.Decay,
 		ScoringType:       scoringType,
-		FlagHash:          flagHash,
+		FlagHash:          primaryFlagHash(flags),
+		Flags:             flags,
 		Files:             req.Files,
 		Tags:              req.Tags,
 		Hints:             hints,
-		IsPublished:       true,
+		ScheduledAt:       scheduledAt,
+		Prerequisites:     buildPrerequisites(req.Prerequisites),
+		FirstBloodBonus:   req.FirstBloodBonus,
+		MaxAttempts:       req.MaxAttempts,
+		GradingMode:       gradingMode,
+		FlagFormat:        req.FlagFormat,
 	}
 
-	if err := h.challengeService.CreateChallenge(challenge); err != nil {
+	if err := h.challengeService.ValidatePrerequisites(challenge); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.challengeService.CreateChallenge(challenge, c.GetString("user_id")); err != nil {
 		utils.RespondWithError(c, http.StatusInternalServerError, err.Error(), err)
 		return
 	}
@@ -172,18 +348,78 @@ func (h *ChallengeHandler) UpdateChallenge(c *gin.Context) {
 		return
 	}
 
-	// Only update flag hash if a new flag is provided
-	var flagHash string
-	if req.Flag != "" {
-		flagHash = utils.HashFlag(req.Flag)
-	} else {
-		// Preserve existing flag hash
-		existing, err := h.challengeService.GetChallengeByID(id)
+	existing, err := h.challengeService.GetChallengeByID(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Challenge not found"})
+		return
+	}
+
+	// Only replace flags if new ones are provided
+	flagHash := existing.FlagHash
+	flags := existing.Flags
+	if req.Flag != "" || len(req.Flags) > 0 {
+		flags, err = buildChallengeFlags(req.Flag, req.Flags)
 		if err != nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Challenge not found"})
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		flagHash = primaryFlagHash(flags)
+	}
+
+	// Only replace prerequisites if the field is present
+	prerequisites := existing.Prerequisites
+	if req.Prerequisites != nil {
+		prerequisites = buildPrerequisites(req.Prerequisites)
+	}
+
+	// Only replace the first blood schedule if the field is present
+	firstBloodBonus := existing.FirstBloodBonus
+	if req.FirstBloodBonus != nil {
+		if err := models.ValidateFirstBloodBonus(req.FirstBloodBonus); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		firstBloodBonus = req.FirstBloodBonus
+	}
+
+	maxAttempts := existing.MaxAttempts
+	if req.MaxAttempts != nil {
+		if err := models.ValidateMaxAttempts(*req.MaxAttempts); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		maxAttempts = *req.MaxAttempts
+	}
+
+	gradingMode := existing.GradingMode
+	if req.GradingMode != "" {
+		if !models.IsValidGradingMode(req.GradingMode) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "grading_mode must be 'auto' or 'manual'"})
+			return
+		}
+		gradingMode = req.GradingMode
+	}
+	if gradingMode == models.GradingAuto && len(flags) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "At least one flag is required"})
+		return
+	}
+
+	flagFormat := existing.FlagFormat
+	if req.FlagFormat != nil {
+		if err := models.ValidateFlagFormat(*req.FlagFormat); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		flagFormat = *req.FlagFormat
+	}
+
+	scheduledAt := existing.ScheduledAt
+	if req.ScheduledAt != nil {
+		scheduledAt, err = resolveSchedule(*req.ScheduledAt)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
-		flagHash = existing.FlagHash
 	}
 
 	// Set default scoring type
@@ -191,6 +427,10 @@ func (h *ChallengeHandler) UpdateChallenge(c *gin.Context) {
 	if scoringType == "" {
 		scoringType = models.ScoringDynamic
 	}
+	if !models.IsValidScoringType(scoringType) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid scoring_type"})
+		return
+	}
 
 	// Build hints
 	var hints []models.Hint
@@ -218,12 +458,25 @@ func (h *ChallengeHandler) UpdateChallenge(c *gin.Context) {
 		Decay:             req.Decay,
 		ScoringType:       scoringType,
 		FlagHash:          flagHash,
+		Flags:             flags,
 		Files:             req.Files,
 		Tags:              req.Tags,
 		Hints:             hints,
+		ScheduledAt:       scheduledAt,
+		Prerequisites:     prerequisites,
+		FirstBloodBonus:   firstBloodBonus,
+		MaxAttempts:       maxAttempts,
+		GradingMode:       gradingMode,
+		FlagFormat:        flagFormat,
 	}
 
-	if err := h.challengeService.UpdateChallenge(id, challenge); err != nil {
+	challenge.ID = id
+	if err := h.challengeService.ValidatePrerequisites(challenge); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.challengeService.UpdateChallenge(id, challenge, c.GetString("user_id")); err != nil {
 		utils.RespondWithError(c, http.StatusInternalServerError, err.Error(), err)
 		return
 	}
@@ -244,24 +497,58 @@ func (h *ChallengeHandler) DeleteChallenge(c *gin.Context) {
 
 // ChallengeResponse is the response struct for challenges (for admin view)
 type ChallengeAdminResponse struct {
-	ID                string   `json:"id"`
-	Title             string   `json:"title"`
-	Description       string   `json:"description"`
-	DescriptionFormat string   `json:"description_format"`
-	Category          string   `json:"category"`
-	Difficulty        string   `json:"difficulty"`
-	MaxPoints         int      `json:"max_points"`
-	MinPoints         int      `json:"min_points"`
-	Decay             int      `json:"decay"`
-	ScoringType       string   `json:"scoring_type"`
-	SolveCount        int      `json:"solve_count"`
-	CurrentPoints     int      `json:"current_points"`
-	Files             []string `json:"files"`
-	Tags              []string `json:"tags"`
-	HintCount         int      `json:"hint_count"`
+	ID                string                         `json:"id"`
+	Title             string                         `json:"title"`
+	Description       string                         `json:"description"`
+	DescriptionFormat string                         `json:"description_format"`
+	Category          string                         `json:"category"`
+	Difficulty        string                         `json:"difficulty"`
+	MaxPoints         int                            `json:"max_points"`
+	MinPoints         int                            `json:"min_points"`
+	Decay             int                            `json:"decay"`
+	ScoringType       string                         `json:"scoring_type"`
+	SolveCount        int                            `json:"solve_count"`
+	CurrentPoints     int                            `json:"current_points"`
+	Files             []string                       `json:"files"`
+	Tags              []string                       `json:"tags"`
+	HintCount         int                            `json:"hint_count"`
+	ScheduledAt       string                         `json:"scheduled_at,omitempty"`
+	IsPublished       bool                           `json:"is_published"`
+	Status            string                         `json:"status"`
+	AuthorID          string                         `json:"author_id,omitempty"`
+	Flags             []FlagAdminResponse            `json:"flags"`
+	Prerequisites     []models.ChallengePrerequisite `json:"prerequisites"`
+	FirstBloodBonus   []int                          `json:"first_blood_bonus"`
+	MaxAttempts       int                            `json:"max_attempts"`
+	GradingMode       string                         `json:"grading_mode"`
+	FlagFormat        string                         `json:"flag_format,omitempty"`
+}
+
+// FlagAdminResponse describes a configured flag without revealing hashed values
+type FlagAdminResponse struct {
+	ID      string `json:"id"`
+	Type    string `json:"type"`
+	Pattern string `json:"pattern,omitempty"` // only set for regex flags
+	Base    string `json:"base,omitempty"`    // only set for dynamic flags
+}
+
+func toFlagAdminResponses(flags []models.ChallengeFlag) []FlagAdminResponse {
+	result := make([]FlagAdminResponse, 0, len(flags))
+	for _, f := range flags {
+		fr := FlagAdminResponse{ID: f.ID, Type: f.Type}
+		switch f.Type {
+		case models.FlagTypeRegex:
+			fr.Pattern = f.Value
+		case models.FlagTypeDynamic:
+			fr.Base = f.Value
+		}
+		result = append(result, fr)
+	}
+	return result
 }
 
 // GetAllChallengesWithFlags returns all challenges for admin (no flag hash exposed).
+// Authors only get the challenges they own.
 // Query param "list=1" returns challenges without description for fast manage-tab load.
 func (h *ChallengeHandler) GetAllChallengesWithFlags(c *gin.Context) {
 	var challenges []models.Challenge
@@ -276,8 +563,14 @@ func (h *ChallengeHandler) GetAllChallengesWithFlags(c *gin.Context) {
 		return
 	}
 
+	role, _ := c.Get("role")
+	userID := c.GetString("user_id")
+
 	var result []ChallengeAdminResponse
 	for _, ch := range challenges {
+		if role != models.RoleAdmin && ch.AuthorID != userID {
+			continue
+		}
 		result = append(result, ChallengeAdminResponse{
 			ID:                ch.ID,
 			Title:             ch.Title,
@@ -294,12 +587,138 @@ func (h *ChallengeHandler) GetAllChallengesWithFlags(c *gin.Context) {
 			Files:             ch.Files,
 			Tags:              ch.Tags,
 			HintCount:         len(ch.Hints),
+			ScheduledAt:       ch.ScheduledAt,
+			IsPublished:       ch.IsPublished,
+			Status:            ch.Status,
+			AuthorID:          ch.AuthorID,
+			Flags:             toFlagAdminResponses(ch.Flags),
+			Prerequisites:     ch.Prerequisites,
+			FirstBloodBonus:   ch.FirstBloodBonus,
+			MaxAttempts:       ch.MaxAttempts,
+			GradingMode:       ch.GradingMode,
+			FlagFormat:        ch.FlagFormat,
 		})
 	}
 
 	c.JSON(http.StatusOK, result)
 }
 
+// GetPrerequisiteGraph reports cycles and challenges that can never be unlocked (admin only)
+func (h *ChallengeHandler) GetPrerequisiteGraph(c *gin.Context) {
+	report, err := h.challengeService.CheckPrerequisiteGraph()
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, err.Error(), err)
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// TestFlagRequest is a dry-run flag check for one challenge
+type TestFlagRequest struct {
+	Flag   string `json:"flag" binding:"required"`
+	TeamID string `json:"team_id"` // team (or teamless user) whose dynamic flag to expect; empty checks every team
+}
+
+// TestFlag checks a flag against a challenge without recording a submission (admin only)
+// @Summary Dry-run a flag
+// @Description Runs the same verification as flag submission but does not create submissions, change solve counts or scores, award achievements or broadcast events.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Challenge ID"
+// @Param request body TestFlagRequest true "Flag to check"
+// @Success 200 {object} services.FlagTestResult
+// @Failure 404 {object} map[string]string
+// @Security ApiKeyAuth
+// @Router /admin/challenges/{id}/test-flag [post]
+func (h *ChallengeHandler) TestFlag(c *gin.Context) {
+	var req TestFlagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	result, err := h.challengeService.TestFlag(c.Param("id"), req.Flag, req.TeamID)
+	if err != nil {
+		if err.Error() == "challenge not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Challenge not found"})
+			return
+		}
+		utils.RespondWithError(c, http.StatusInternalServerError, err.Error(), err)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// TestContestFlagsRequest is a dry-run flag check for the challenges of a contest
+type TestContestFlagsRequest struct {
+	Flags  map[string]string `json:"flags" binding:"required"` // challenge ID -> expected flag
+	TeamID string            `json:"team_id"`
+}
+
+// TestContestFlags dry-runs expected flags for every challenge attached to a contest (admin only).
+// Challenges without a supplied flag are reported as untested; flags for challenges outside
+// the contest are reported separately.
+func (h *ChallengeHandler) TestContestFlags(c *gin.Context) {
+	var req TestContestFlagsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	challengeIDs, err := h.contestAdminService.GetContestChallengeIDs(c.Param("id"))
+	if err != nil {
+		if err.Error() == "contest not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Contest not found"})
+			return
+		}
+		utils.RespondWithError(c, http.StatusInternalServerError, err.Error(), err)
+		return
+	}
+
+	results, err := h.challengeService.TestFlags(challengeIDs, req.Flags, req.TeamID, c.Param("id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, err.Error(), err)
+		return
+	}
+
+	inContest := make(map[string]bool, len(challengeIDs))
+	for _, id := range challengeIDs {
+		inContest[id] = true
+	}
+	notInContest := []string{}
+	for id := range req.Flags {
+		if !inContest[id] {
+			notInContest = append(notInContest, id)
+		}
+	}
+	sort.Strings(notInContest)
+
+	passed, failed, untested, invalidFormat := 0, 0, 0, 0
+	for _, r := range results {
+		if r.InvalidFormat {
+			invalidFormat++
+		}
+		switch {
+		case !r.Tested:
+			untested++
+		case r.Correct:
+			passed++
+		default:
+			failed++
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"results":        results,
+		"passed":         passed,
+		"failed":         failed,
+		"untested":       untested,
+		"invalid_format": invalidFormat,
+		"not_in_contest": notInContest,
+	})
+}
+
 // ChallengePublicResponse is the response struct for public challenge view
 type ChallengePublicResponse struct {
 	ID                    string   `json:"id"`
@@ -318,18 +737,40 @@ type ChallengePublicResponse struct {
 	IsSolved              bool     `json:"is_solved"`
 	OfficialWriteup       string   `json:"official_writeup,omitempty"`
 	OfficialWriteupFormat string   `json:"official_writeup_format,omitempty"`
+	DynamicFlag           string   `json:"dynamic_flag,omitempty"` // flag issued to the caller's team in dynamic flag mode
+	FirstBloodBonus       []int    `json:"first_blood_bonus,omitempty"`
+	MaxAttempts           int      `json:"max_attempts,omitempty"`
+	GradingMode           string   `json:"grading_mode"`
+	FlagFormat            string   `json:"flag_format,omitempty"`        // regex flags must match, from the challenge or the contest
+	AttemptsRemaining     *int     `json:"attempts_remaining,omitempty"` // wrong submissions left for the caller's team, only set when capped
 }
 
 // GetAllChallenges returns all challenges for users (filtered by active contest/round visibility)
 // @Summary Get all challenges
-// @Description Retrieve a list of all published challenges with public details (no flags).
+// @Description Retrieve the published challenges visible to the caller with public details (no flags). The list can be filtered, sorted and paged; when more results remain, the X-Next-Cursor header holds the cursor for the next page and X-Total-Count the number of matching challenges.
 // @Tags Challenges
 // @Produce json
+// @Param category query string false "Only challenges in this category"
+// @Param difficulty query string false "Only challenges of this difficulty"
+// @Param tags query string false "Comma-separated tags, all of which must be present"
+// @Param solved query bool false "Only challenges solved (true) or not yet solved (false) by the caller's team"
+// @Param q query string false "Words that must all appear in the title or description"
+// @Param sort query string false "Sort by title, points or solves (default: category, then title)"
+// @Param order query string false "asc (default) or desc"
+// @Param limit query int false "Page size, at most 100 (default: no paging)"
+// @Param cursor query string false "X-Next-Cursor from the previous page"
 // @Success 200 {array} ChallengePublicResponse
+// @Failure 400 {object} map[string]string
 // @Failure 500 {object} map[string]string
 // @Security ApiKeyAuth
 // @Router /challenges [get]
 func (h *ChallengeHandler) GetAllChallenges(c *gin.Context) {
+	query, err := parseChallengeListQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	// Determine current user and their team
 	var userID string
 	var teamID *string
@@ -351,7 +792,6 @@ func (h *ChallengeHandler) GetAllChallenges(c *gin.Context) {
 	}
 
 	var challenges []models.Challenge
-	var err error
 	if h.contestAdminService != nil {
 		challenges, err = h.contestAdminService.GetVisibleChallenges(time.Now(), teamID)
 		if err != nil {
@@ -371,31 +811,27 @@ func (h *ChallengeHandler) GetAllChallenges(c *gin.Context) {
 	if activeContestID != "" && h.contestSolveRepo != nil {
 		contestSolveCounts, _ = h.contestSolveRepo.GetContestSolveCounts(activeContestID)
 	}
+	contestScoring := h.challengeService.ContestScoringType(activeContestID)
+
+	// Pre-fetch what the user or their team has solved, in the active contest when there is one
+	var solved map[string]bool
+	if h.submissionRepo != nil && userID != "" {
+		solvedTeamID := ""
+		if teamID != nil {
+			solvedTeamID = *teamID
+		}
+		solved, err = h.submissionRepo.GetSolvedChallengeIDs(userID, solvedTeamID, activeContestID)
+		if err != nil {
+			utils.RespondWithError(c, http.StatusInternalServerError, err.Error(), err)
+			return
+		}
+	}
 
-	var result []ChallengePublicResponse
+	result := []ChallengePublicResponse{}
 	for _, ch := range challenges {
-		isSolved := false
-		if h.submissionRepo != nil && userID != "" {
-			// Use contest-scoped solved check when there's an active contest
-			if activeContestID != "" {
-				if sub, _ := h.submissionRepo.FindByChallengeAndUserInContest(ch.ID, userID, activeContestID); sub != nil {
-					isSolved = true
-				}
-				if !isSolved && teamID != nil && *teamID != "" {
-					if teamSub, _ := h.submissionRepo.FindByChallengeAndTeamInContest(ch.ID, *teamID, activeContestID); teamSub != nil {
-						isSolved = true
-					}
-				}
-			} else {
-				if sub, _ := h.submissionRepo.FindByChallengeAndUser(ch.ID, userID); sub != nil {
-					isSolved = true
-				}
-				if !isSolved && teamID != nil && *teamID != "" {
-					if teamSub, _ := h.submissionRepo.FindByChallengeAndTeam(ch.ID, *teamID); teamSub != nil {
-						isSolved = true
-					}
-				}
-			}
+		isSolved := solved[ch.ID]
+		if !query.Matches(&ch, isSolved) {
+			continue
 		}
 
 		// Use contest-specific solve count and points when in a contest
@@ -403,7 +839,7 @@ func (h *ChallengeHandler) GetAllChallenges(c *gin.Context) {
 		solveCount := ch.SolveCount
 		if activeContestID != "" && contestSolveCounts != nil {
 			csc := contestSolveCounts[ch.ID]
-			currentPoints = ch.PointsForSolveCount(csc)
+			currentPoints = ch.PointsInContest(contestScoring, csc)
 			solveCount = csc
 		}
 
@@ -421,11 +857,79 @@ func (h *ChallengeHandler) GetAllChallenges(c *gin.Context) {
 			Files:             ch.Files,
 			Tags:              ch.Tags,
 			HintCount:         len(ch.Hints),
+			FirstBloodBonus:   ch.FirstBloodBonus,
+			MaxAttempts:       ch.MaxAttempts,
+			GradingMode:       ch.GradingMode,
 			IsSolved:          isSolved,
 		})
 	}
 
-	c.JSON(http.StatusOK, result)
+	sort.SliceStable(result, func(i, j int) bool {
+		return query.Less(challengeListKey(&result[i]), challengeListKey(&result[j]))
+	})
+	keys := make([]models.ChallengeListKey, len(result))
+	for i := range result {
+		keys[i] = challengeListKey(&result[i])
+	}
+	start, end, next, err := query.Page(keys)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("X-Total-Count", strconv.Itoa(len(result)))
+	if next != "" {
+		c.Header("X-Next-Cursor", next)
+	}
+	c.JSON(http.StatusOK, result[start:end])
+}
+
+// parseChallengeListQuery reads the filter, sort and paging parameters of the challenge list
+func parseChallengeListQuery(c *gin.Context) (models.ChallengeListQuery, error) {
+	query := models.ChallengeListQuery{
+		Category:   strings.TrimSpace(c.Query("category")),
+		Difficulty: strings.TrimSpace(c.Query("difficulty")),
+		Search:     strings.TrimSpace(c.Query("q")),
+		Sort:       c.Query("sort"),
+		Cursor:     c.Query("cursor"),
+	}
+	for _, tag := range strings.Split(c.Query("tags"), ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			query.Tags = append(query.Tags, tag)
+		}
+	}
+	if v := c.Query("solved"); v != "" {
+		solved, err := strconv.ParseBool(v)
+		if err != nil {
+			return query, errors.New("solved must be true or false")
+		}
+		query.Solved = &solved
+	}
+	switch strings.ToLower(c.Query("order")) {
+	case "", "asc":
+	case "desc":
+		query.Desc = true
+	default:
+		return query, errors.New("order must be asc or desc")
+	}
+	if v := c.Query("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 1 {
+			return query, errors.New("limit must be a positive number")
+		}
+		query.Limit = limit
+	}
+	return query, query.Validate()
+}
+
+func challengeListKey(ch *ChallengePublicResponse) models.ChallengeListKey {
+	return models.ChallengeListKey{
+		ID:       ch.ID,
+		Category: ch.Category,
+		Title:    ch.Title,
+		Points:   ch.CurrentPoints,
+		Solves:   ch.SolveCount,
+	}
 }
 
 func (h *ChallengeHandler) GetChallengeByID(c *gin.Context) {
@@ -501,7 +1005,7 @@ func (h *ChallengeHandler) GetChallengeByID(c *gin.Context) {
 	solveCount := challenge.SolveCount
 	if activeContestID != "" && h.contestSolveRepo != nil {
 		csc, _ := h.contestSolveRepo.GetContestSolveCount(activeContestID, challenge.ID)
-		currentPoints = challenge.PointsForSolveCount(csc)
+		currentPoints = challenge.PointsInContest(h.challengeService.ContestScoringType(activeContestID), csc)
 		solveCount = csc
 	}
 
@@ -520,8 +1024,32 @@ func (h *ChallengeHandler) GetChallengeByID(c *gin.Context) {
 		Files:             challenge.Files,
 		Tags:              challenge.Tags,
 		HintCount:         len(challenge.Hints),
+		FirstBloodBonus:   challenge.FirstBloodBonus,
+		MaxAttempts:       challenge.MaxAttempts,
+		GradingMode:       challenge.GradingMode,
 		IsSolved:          isSolved,
 	}
+	if challenge.GradingMode != models.GradingManual {
+		response.FlagFormat = h.challengeService.FlagFormatFor(challenge, activeContestID)
+	}
+	// Challenges in dynamic flag mode hand each team its own flag
+	if userIDStr, exists := c.Get("user_id"); exists {
+		ownerID := userIDStr.(string)
+		if h.teamRepo != nil {
+			if team, err := h.teamRepo.FindTeamByMemberID(ownerID); err == nil && team != nil {
+				ownerID = team.ID
+			}
+		}
+		if ownerID != "" {
+			response.DynamicFlag = h.challengeService.GetDynamicFlag(challenge, ownerID)
+		}
+		if userID := userIDStr.(string); userID != "" && !isSolved {
+			if remaining, err := h.challengeService.GetAttemptsRemaining(challenge, userID, activeContestID); err == nil {
+				response.AttemptsRemaining = remaining
+			}
+		}
+	}
+
 	// Include official writeup only when contest has ended and it is published
 	if challenge.OfficialWriteupPublished && challenge.OfficialWriteup != "" {
 		ended := true
@@ -662,6 +1190,17 @@ func (h *ChallengeHandler) SubmitFlag(c *gin.Context) {
 
 	result, err := h.challengeService.SubmitFlag(userID, challengeID, req.Flag, clientIP, contestID)
 	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrAttemptLimitReached):
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error(), "attempts_remaining": 0})
+			return
+		case errors.Is(err, services.ErrAnswerPending):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		case errors.Is(err, services.ErrAnswerTooLong):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		utils.RespondWithError(c, http.StatusInternalServerError, err.Error(), err)
 		return
 	}
@@ -671,6 +1210,29 @@ func (h *ChallengeHandler) SubmitFlag(c *gin.Context) {
 		"already_solved": result.AlreadySolved,
 	}
 
+	// A flag in the wrong format was never checked, so it does not use up the rate limit
+	if result.InvalidFormat {
+		middleware.RefundRateLimit(c)
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"correct":        false,
+			"invalid_format": true,
+			"error":          result.Message,
+			"flag_format":    result.FlagFormat,
+		})
+		return
+	}
+
+	if !result.IsCorrect && result.Message != "" {
+		response["message"] = result.Message
+	}
+	if !result.IsCorrect && result.AttemptsRemaining != nil {
+		response["attempts_remaining"] = *result.AttemptsRemaining
+	}
+	if result.Pending {
+		response["pending"] = true
+		response["manual_submission_id"] = result.ManualSubmissionID
+	}
+
 	if result.IsCorrect {
 		response["message"] = result.Message
 		if response["message"] == "" {
@@ -681,18 +1243,24 @@ func (h *ChallengeHandler) SubmitFlag(c *gin.Context) {
 		if result.TeamName != "" {
 			response["team_name"] = result.TeamName
 		}
+		if result.FirstBloodPlace > 0 {
+			response["first_blood_place"] = result.FirstBloodPlace
+			response["first_blood_bonus"] = result.FirstBloodBonus
+		}
 
 		// Broadcast solve event via WebSocket
 		if h.wsHub != nil {
 			challengeObjID := challengeID
 			username, _ := c.Get("username")
 			h.wsHub.BroadcastMessage("solve_feed", gin.H{
-				"user_id":      userIDStr,
-				"username":     username,
-				"challenge_id": challengeID,
-				"points":       result.Points,
-				"solve_count":  result.SolveCount,
-				"team_name":    result.TeamName,
+				"user_id":           userIDStr,
+				"username":          username,
+				"challenge_id":      challengeID,
+				"points":            result.Points,
+				"solve_count":       result.SolveCount,
+				"team_name":         result.TeamName,
+				"first_blood_place": result.FirstBloodPlace,
+				"first_blood_bonus": result.FirstBloodBonus,
 			})
 			h.wsHub.BroadcastMessage("scoreboard_update", gin.H{
 				"updated": true,
@@ -709,6 +1277,66 @@ func (h *ChallengeHandler) SubmitFlag(c *gin.Context) {
 	c.JSON(http.StatusOK, response)
 }
 
+type ResetAttemptsRequest struct {
+	TeamID    string `json:"team_id"`    // team whose counter is reset
+	UserID    string `json:"user_id"`    // alternative to team_id for players without a team
+	ContestID string `json:"contest_id"` // defaults to the active contest
+}
+
+// ResetAttempts clears a team's wrong-attempt counter on a challenge (admin only)
+// @Summary Reset a team's attempt counter
+// @Description Wrong submissions made before the reset no longer count against the challenge's max_attempts. The submissions themselves are kept.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Challenge ID"
+// @Param request body ResetAttemptsRequest true "Team and contest"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Security ApiKeyAuth
+// @Router /admin/challenges/{id}/attempts/reset [post]
+func (h *ChallengeHandler) ResetAttempts(c *gin.Context) {
+	var req ResetAttemptsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	ownerID := req.TeamID
+	if ownerID == "" {
+		ownerID = req.UserID
+	}
+	if ownerID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "team_id or user_id is required"})
+		return
+	}
+
+	contestID := req.ContestID
+	if contestID == "" && h.contestAdminService != nil {
+		if cfg, err := h.contestAdminService.GetActiveContestConfig(); err == nil && cfg != nil {
+			contestID = cfg.ContestID
+		}
+	}
+
+	challengeID := c.Param("id")
+	if err := h.challengeService.ResetAttempts(challengeID, ownerID, contestID, c.GetString("user_id")); err != nil {
+		if err.Error() == "challenge not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		utils.RespondWithError(c, http.StatusInternalServerError, err.Error(), err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":      "Attempt counter reset",
+		"challenge_id": challengeID,
+		"owner_id":     ownerID,
+		"contest_id":   contestID,
+	})
+}
+
 // SolveEntryResponse represents a single solve for the challenge solves endpoint
 type SolveEntryResponse struct {
 	UserID   string    `json:"user_id"`
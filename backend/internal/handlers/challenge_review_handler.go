@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Uttam-Mahata/RootAccess/backend/internal/models"
+	"github.com/Uttam-Mahata/RootAccess/backend/internal/services"
+	"github.com/Uttam-Mahata/RootAccess/backend/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type ChallengeReviewHandler struct {
+	reviewService *services.ChallengeReviewService
+}
+
+func NewChallengeReviewHandler(reviewService *services.ChallengeReviewService) *ChallengeReviewHandler {
+	return &ChallengeReviewHandler{
+		reviewService: reviewService,
+	}
+}
+
+type ChallengeTransitionRequest struct {
+	Action  string `json:"action" binding:"required"` // submit, approve, request_changes, publish, unpublish or reopen
+	Comment string `json:"comment"`                   // reviewer comment, required for request_changes
+}
+
+// ReviewQueueItem is a challenge summary in the review queue
+type ReviewQueueItem struct {
+	ID          string `json:"id"`
+	Title       string `json:"title"`
+	Category    string `json:"category"`
+	Difficulty  string `json:"difficulty"`
+	Status      string `json:"status"`
+	AuthorID    string `json:"author_id,omitempty"`
+	ScheduledAt string `json:"scheduled_at,omitempty"`
+}
+
+// TransitionChallenge moves a challenge through the authoring workflow
+// @Summary Apply a workflow action to a challenge
+// @Description Challenges move draft -> in_review -> approved -> published. Approving and requesting changes must be done by an admin other than the author; only approved challenges can be published. Authors may only submit and reopen their own challenges.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Challenge ID"
+// @Param request body ChallengeTransitionRequest true "Workflow action"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Security ApiKeyAuth
+// @Router /admin/challenges/{id}/workflow [post]
+func (h *ChallengeReviewHandler) TransitionChallenge(c *gin.Context) {
+	var req ChallengeTransitionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	if role, _ := c.Get("role"); role != models.RoleAdmin && !models.AuthorMayApply(req.Action) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins can approve, request changes on or publish challenges"})
+		return
+	}
+
+	challenge, err := h.reviewService.Transition(c.Param("id"), req.Action, c.GetString("user_id"), req.Comment)
+	if err != nil {
+		switch {
+		case err.Error() == "challenge not found":
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrSelfReview):
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":      "Challenge status updated",
+		"challenge_id": challenge.ID,
+		"status":       challenge.Status,
+		"is_published": challenge.IsPublished,
+	})
+}
+
+// GetWorkflow returns the workflow history of a challenge, oldest first
+func (h *ChallengeReviewHandler) GetWorkflow(c *gin.Context) {
+	history, err := h.reviewService.GetHistory(c.Param("id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, err.Error(), err)
+		return
+	}
+	c.JSON(http.StatusOK, history)
+}
+
+// GetReviewQueue lists challenges in a workflow state (in_review by default)
+func (h *ChallengeReviewHandler) GetReviewQueue(c *gin.Context) {
+	challenges, err := h.reviewService.GetQueue(c.DefaultQuery("status", models.ChallengeStatusInReview))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	queue := make([]ReviewQueueItem, 0, len(challenges))
+	for _, ch := range challenges {
+		queue = append(queue, ReviewQueueItem{
+			ID:          ch.ID,
+			Title:       ch.Title,
+			Category:    ch.Category,
+			Difficulty:  ch.Difficulty,
+			Status:      ch.Status,
+			AuthorID:    ch.AuthorID,
+			ScheduledAt: ch.ScheduledAt,
+		})
+	}
+	c.JSON(http.StatusOK, queue)
+}
@@ -0,0 +1,160 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/Uttam-Mahata/RootAccess/backend/internal/models"
+	"github.com/Uttam-Mahata/RootAccess/backend/internal/services"
+	"github.com/Uttam-Mahata/RootAccess/backend/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type ChallengeRevisionHandler struct {
+	challengeService *services.ChallengeService
+}
+
+func NewChallengeRevisionHandler(challengeService *services.ChallengeService) *ChallengeRevisionHandler {
+	return &ChallengeRevisionHandler{
+		challengeService: challengeService,
+	}
+}
+
+// canSeeFlags reports whether the caller may see flag hashes and values in history.
+// Only full admins do; other staff roles get redacted snapshots and diffs.
+func canSeeFlags(c *gin.Context) bool {
+	role, _ := c.Get("role")
+	return role == models.RoleAdmin
+}
+
+func revisionResponse(c *gin.Context, rev models.ChallengeRevision) models.ChallengeRevision {
+	if !canSeeFlags(c) {
+		rev.Snapshot = rev.Snapshot.Redacted()
+	}
+	return rev
+}
+
+// GetRevisions lists the revision history of a challenge, newest first
+// @Summary List challenge revisions
+// @Description Every create, update and rollback of a challenge is stored as an immutable revision.
+// @Tags Admin
+// @Produce json
+// @Param id path string true "Challenge ID"
+// @Success 200 {array} models.ChallengeRevision
+// @Failure 403 {object} map[string]string
+// @Security ApiKeyAuth
+// @Router /admin/challenges/{id}/revisions [get]
+func (h *ChallengeRevisionHandler) GetRevisions(c *gin.Context) {
+	revisions, err := h.challengeService.GetRevisions(c.Param("id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, err.Error(), err)
+		return
+	}
+
+	response := make([]models.ChallengeRevision, 0, len(revisions))
+	for _, rev := range revisions {
+		response = append(response, revisionResponse(c, rev))
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// GetRevision returns a single revision of a challenge
+func (h *ChallengeRevisionHandler) GetRevision(c *gin.Context) {
+	number, err := strconv.Atoi(c.Param("revision"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid revision number"})
+		return
+	}
+
+	rev, err := h.challengeService.GetRevision(c.Param("id"), number)
+	if err != nil {
+		if err.Error() == "revision not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		utils.RespondWithError(c, http.StatusInternalServerError, err.Error(), err)
+		return
+	}
+
+	c.JSON(http.StatusOK, revisionResponse(c, *rev))
+}
+
+// DiffRevisions lists the fields that changed between two revisions
+// @Summary Diff challenge revisions
+// @Tags Admin
+// @Produce json
+// @Param id path string true "Challenge ID"
+// @Param from query int true "Older revision number"
+// @Param to query int true "Newer revision number"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Security ApiKeyAuth
+// @Router /admin/challenges/{id}/revisions/diff [get]
+func (h *ChallengeRevisionHandler) DiffRevisions(c *gin.Context) {
+	from, errFrom := strconv.Atoi(c.Query("from"))
+	to, errTo := strconv.Atoi(c.Query("to"))
+	if errFrom != nil || errTo != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from and to must be revision numbers"})
+		return
+	}
+
+	changes, err := h.challengeService.DiffRevisions(c.Param("id"), from, to)
+	if err != nil {
+		if err.Error() == "revision not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		utils.RespondWithError(c, http.StatusInternalServerError, err.Error(), err)
+		return
+	}
+	if !canSeeFlags(c) {
+		changes = models.RedactFlagChanges(changes)
+	}
+	if changes == nil {
+		changes = []models.ChallengeFieldChange{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"from":    from,
+		"to":      to,
+		"changes": changes,
+	})
+}
+
+// RollbackChallenge restores a challenge to the state of an earlier revision
+// @Summary Roll back a challenge
+// @Description Restores the editable fields of a revision. The rollback is recorded as a new revision.
+// @Tags Admin
+// @Produce json
+// @Param id path string true "Challenge ID"
+// @Param revision path int true "Revision number to restore"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Security ApiKeyAuth
+// @Router /admin/challenges/{id}/revisions/{revision}/rollback [post]
+func (h *ChallengeRevisionHandler) RollbackChallenge(c *gin.Context) {
+	number, err := strconv.Atoi(c.Param("revision"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid revision number"})
+		return
+	}
+
+	challenge, err := h.challengeService.RollbackChallenge(c.Param("id"), number, c.GetString("user_id"))
+	if err != nil {
+		switch err.Error() {
+		case "revision not found", "challenge not found":
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":      "Challenge rolled back successfully",
+		"challenge_id": challenge.ID,
+		"revision":     number,
+	})
+}
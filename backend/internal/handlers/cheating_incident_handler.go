@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/Uttam-Mahata/RootAccess/backend/internal/services"
+	"github.com/Uttam-Mahata/RootAccess/backend/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type CheatingIncidentHandler struct {
+	incidentService *services.CheatingIncidentService
+}
+
+func NewCheatingIncidentHandler(incidentService *services.CheatingIncidentService) *CheatingIncidentHandler {
+	return &CheatingIncidentHandler{
+		incidentService: incidentService,
+	}
+}
+
+// GetIncidents returns paginated cheating incidents (admin only)
+// @Summary Get cheating incidents
+// @Description Retrieve incidents such as teams submitting another team's dynamic flag.
+// @Tags Admin
+// @Produce json
+// @Param status query string false "Filter by status (open, confirmed, dismissed)"
+// @Param limit query int false "Number of incidents per page" default(50)
+// @Param page query int false "Page number" default(1)
+// @Success 200 {object} map[string]interface{}
+// @Failure 403 {object} map[string]string
+// @Security ApiKeyAuth
+// @Router /admin/cheating-incidents [get]
+func (h *CheatingIncidentHandler) GetIncidents(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	status := c.Query("status")
+
+	incidents, total, err := h.incidentService.GetIncidents(status, limit, page)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, err.Error(), err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"incidents": incidents,
+		"total":     total,
+		"page":      page,
+		"limit":     limit,
+	})
+}
+
+type ReviewIncidentRequest struct {
+	Status string `json:"status" binding:"required"` // "open", "confirmed" or "dismissed"
+	Note   string `json:"note"`
+}
+
+// ReviewIncident sets the review status of a cheating incident (admin only)
+func (h *CheatingIncidentHandler) ReviewIncident(c *gin.Context) {
+	var req ReviewIncidentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	reviewerID, _ := c.Get("user_id")
+	incident, err := h.incidentService.ReviewIncident(c.Param("id"), req.Status, reviewerID.(string), req.Note)
+	if err != nil {
+		if err.Error() == "incident not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, incident)
+}
@@ -4,6 +4,7 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/Uttam-Mahata/RootAccess/backend/internal/models"
 	"github.com/Uttam-Mahata/RootAccess/backend/internal/services"
 	"github.com/Uttam-Mahata/RootAccess/backend/internal/utils"
 	"github.com/gin-gonic/gin"
@@ -61,6 +62,11 @@ type CreateContestRequest struct {
 	EndTime              string `json:"end_time" binding:"required"`
 	FreezeTime           string `json:"freeze_time"`
 	ScoreboardVisibility string `json:"scoreboard_visibility"`
+	FlagFormat           string `json:"flag_format"`     // regex submissions must match, e.g. RootAccess\{[^}]+\}
+	ScoringType          string `json:"scoring_type"`    // overrides every challenge's scoring strategy, "" keeps them
+	RankingMode          string `json:"ranking_mode"`    // "points" (default) or "icpc"
+	PenaltyMinutes       *int   `json:"penalty_minutes"` // ICPC penalty per wrong submission, 20 when omitted
+	TieBreaker           string `json:"tie_breaker"`     // "earliest", "fewer_hints", "fewer_wrong" or "alphabetical" (default)
 }
 
 // CreateContest creates a new contest
@@ -101,7 +107,7 @@ func (h *ContestAdminHandler) CreateContest(c *gin.Context) {
 		freezeTime = &ft
 	}
 
-	contest, err := h.contestAdminService.CreateContest(req.Name, req.Description, startTime, endTime, freezeTime, req.ScoreboardVisibility)
+	contest, err := h.contestAdminService.CreateContest(req.Name, req.Description, startTime, endTime, freezeTime, req.ScoreboardVisibility, req.FlagFormat, req.ScoringType, req.RankingMode, penaltyMinutes(req.PenaltyMinutes), req.TieBreaker)
 	if err != nil {
 		utils.RespondWithError(c, http.StatusBadRequest, err.Error(), err)
 		return
@@ -109,6 +115,14 @@ func (h *ContestAdminHandler) CreateContest(c *gin.Context) {
 	c.JSON(http.StatusCreated, contest)
 }
 
+// penaltyMinutes defaults an omitted ICPC penalty
+func penaltyMinutes(minutes *int) int {
+	if minutes == nil {
+		return models.DefaultPenaltyMinutes
+	}
+	return *minutes
+}
+
 // UpdateContestEntityRequest represents update contest entity request
 type UpdateContestEntityRequest struct {
 	Name                 string `json:"name" binding:"required"`
@@ -118,6 +132,11 @@ type UpdateContestEntityRequest struct {
 	IsActive             bool   `json:"is_active"`
 	FreezeTime           string `json:"freeze_time"`
 	ScoreboardVisibility string `json:"scoreboard_visibility"`
+	FlagFormat           string `json:"flag_format"`  // "" removes the format
+	ScoringType          string `json:"scoring_type"` // "" removes the override
+	RankingMode          string `json:"ranking_mode"`
+	PenaltyMinutes       *int   `json:"penalty_minutes"` // 20 when omitted
+	TieBreaker           string `json:"tie_breaker"`
 }
 
 // UpdateContest updates a contest
@@ -160,7 +179,7 @@ func (h *ContestAdminHandler) UpdateContest(c *gin.Context) {
 		freezeTime = &ft
 	}
 
-	contest, err := h.contestAdminService.UpdateContest(id, req.Name, req.Description, startTime, endTime, req.IsActive, freezeTime, req.ScoreboardVisibility)
+	contest, err := h.contestAdminService.UpdateContest(id, req.Name, req.Description, startTime, endTime, req.IsActive, freezeTime, req.ScoreboardVisibility, req.FlagFormat, req.ScoringType, req.RankingMode, penaltyMinutes(req.PenaltyMinutes), req.TieBreaker)
 	if err != nil {
 		utils.RespondWithError(c, http.StatusBadRequest, err.Error(), err)
 		return
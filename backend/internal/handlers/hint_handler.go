@@ -9,12 +9,14 @@ import (
 )
 
 type HintHandler struct {
-	hintService *services.HintService
+	hintService         *services.HintService
+	contestAdminService *services.ContestAdminService
 }
 
-func NewHintHandler(hintService *services.HintService) *HintHandler {
+func NewHintHandler(hintService *services.HintService, contestAdminService *services.ContestAdminService) *HintHandler {
 	return &HintHandler{
-		hintService: hintService,
+		hintService:         hintService,
+		contestAdminService: contestAdminService,
 	}
 }
 
@@ -74,7 +76,15 @@ func (h *HintHandler) RevealHint(c *gin.Context) {
 
 	userID := userIDStr.(string)
 
-	hint, err := h.hintService.RevealHint(challengeID, hintID, userID)
+	// Charge the cost to the active contest
+	contestID := ""
+	if h.contestAdminService != nil {
+		if cfg, err := h.contestAdminService.GetActiveContestConfig(); err == nil && cfg != nil {
+			contestID = cfg.ContestID
+		}
+	}
+
+	hint, err := h.hintService.RevealHint(challengeID, hintID, userID, contestID)
 	if err != nil {
 		utils.RespondWithError(c, http.StatusBadRequest, err.Error(), err)
 		return
@@ -0,0 +1,147 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Uttam-Mahata/RootAccess/backend/internal/services"
+	"github.com/Uttam-Mahata/RootAccess/backend/internal/utils"
+	websocketPkg "github.com/Uttam-Mahata/RootAccess/backend/internal/websocket"
+	"github.com/gin-gonic/gin"
+)
+
+type JudgingHandler struct {
+	judgingService *services.JudgingService
+	wsHub          websocketPkg.Hub
+}
+
+func NewJudgingHandler(judgingService *services.JudgingService, wsHub websocketPkg.Hub) *JudgingHandler {
+	return &JudgingHandler{
+		judgingService: judgingService,
+		wsHub:          wsHub,
+	}
+}
+
+type JudgeSubmissionRequest struct {
+	Verdict  string `json:"verdict" binding:"required"` // accept, reject or partial
+	Points   int    `json:"points"`                     // partial credit, below the challenge's current points
+	Feedback string `json:"feedback"`                   // shown to the team
+}
+
+// GetQueue lists answers to manually graded challenges
+// @Summary Get the judging queue
+// @Description Answers to manually graded challenges in a state (pending by default), oldest first. Filter with contest_id and challenge_id.
+// @Tags Admin
+// @Produce json
+// @Param status query string false "pending, accepted, rejected or partial"
+// @Param contest_id query string false "Contest ID"
+// @Param challenge_id query string false "Challenge ID"
+// @Success 200 {array} models.ManualSubmission
+// @Failure 400 {object} map[string]string
+// @Security ApiKeyAuth
+// @Router /admin/judging [get]
+func (h *JudgingHandler) GetQueue(c *gin.Context) {
+	entries, err := h.judgingService.GetQueue(c.Query("status"), c.Query("contest_id"), c.Query("challenge_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, entries)
+}
+
+// GetSubmission returns one answer in the judging queue
+func (h *JudgingHandler) GetSubmission(c *gin.Context) {
+	entry, err := h.judgingService.GetSubmission(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, entry)
+}
+
+// JudgeSubmission gives a verdict on a pending answer
+// @Summary Judge an answer
+// @Description Accepting scores the answer like a flag solve at the time it was submitted; rejecting counts as a wrong attempt; partial awards the given points.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Manual submission ID"
+// @Param request body JudgeSubmissionRequest true "Verdict"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Security ApiKeyAuth
+// @Router /admin/judging/{id} [post]
+func (h *JudgingHandler) JudgeSubmission(c *gin.Context) {
+	var req JudgeSubmissionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	entry, result, err := h.judgingService.Judge(c.Param("id"), req.Verdict, req.Points, req.Feedback, c.GetString("user_id"))
+	if err != nil {
+		switch {
+		case err.Error() == "submission not found" || err.Error() == "challenge not found":
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrAlreadyJudged):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	response := gin.H{
+		"submission":     entry,
+		"already_solved": result.AlreadySolved,
+	}
+	solved := result.IsCorrect && !result.AlreadySolved
+	if solved {
+		response["points"] = result.Points
+		response["solve_count"] = result.SolveCount
+		if result.FirstBloodPlace > 0 {
+			response["first_blood_place"] = result.FirstBloodPlace
+			response["first_blood_bonus"] = result.FirstBloodBonus
+		}
+
+		if h.wsHub != nil {
+			h.wsHub.BroadcastMessage("solve_feed", gin.H{
+				"user_id":           entry.UserID,
+				"username":          entry.Username,
+				"challenge_id":      entry.ChallengeID,
+				"points":            result.Points,
+				"solve_count":       result.SolveCount,
+				"team_name":         result.TeamName,
+				"first_blood_place": result.FirstBloodPlace,
+				"first_blood_bonus": result.FirstBloodBonus,
+			})
+		}
+	}
+	if h.wsHub != nil && (solved || entry.PointsAwarded > 0) {
+		h.wsHub.BroadcastMessage("scoreboard_update", gin.H{
+			"updated": true,
+		})
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// GetOwnAnswers lists the answers the caller's team gave to a manually graded challenge,
+// with their verdicts and feedback
+// @Summary Get your answers to a manually graded challenge
+// @Tags Challenges
+// @Produce json
+// @Param id path string true "Challenge ID"
+// @Success 200 {array} models.ManualSubmission
+// @Security ApiKeyAuth
+// @Router /challenges/{id}/answers [get]
+func (h *JudgingHandler) GetOwnAnswers(c *gin.Context) {
+	entries, err := h.judgingService.GetOwnAnswers(c.Param("id"), c.GetString("user_id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, err.Error(), err)
+		return
+	}
+	c.JSON(http.StatusOK, entries)
+}
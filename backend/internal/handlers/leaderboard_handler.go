@@ -33,7 +33,7 @@ func (h *LeaderboardHandler) GetCategoryLeaderboard(c *gin.Context) {
 		return
 	}
 	contestID := c.Query("contest_id")
-	scoreboard, err := h.scoreboardService.GetScoreboard(contestID)
+	scoreboard, err := h.scoreboardService.GetScoreboard(contestID, nil)
 	if err != nil {
 		utils.RespondWithError(c, http.StatusInternalServerError, err.Error(), err)
 		return
@@ -69,7 +69,7 @@ func (h *LeaderboardHandler) GetTimeBasedLeaderboard(c *gin.Context) {
 
 	_ = since // Will be used for filtered queries
 	contestID := c.Query("contest_id")
-	scoreboard, err := h.scoreboardService.GetScoreboard(contestID)
+	scoreboard, err := h.scoreboardService.GetScoreboard(contestID, nil)
 	if err != nil {
 		utils.RespondWithError(c, http.StatusInternalServerError, err.Error(), err)
 		return
@@ -13,6 +13,7 @@ type ProfileHandler struct {
 	submissionRepo *repositories.SubmissionRepository
 	challengeRepo  *repositories.ChallengeRepository
 	teamRepo       *repositories.TeamRepository
+	ledgerRepo     *repositories.ScoreLedgerRepository
 }
 
 func NewProfileHandler(
@@ -20,12 +21,14 @@ func NewProfileHandler(
 	submissionRepo *repositories.SubmissionRepository,
 	challengeRepo *repositories.ChallengeRepository,
 	teamRepo *repositories.TeamRepository,
+	ledgerRepo *repositories.ScoreLedgerRepository,
 ) *ProfileHandler {
 	return &ProfileHandler{
 		userRepo:       userRepo,
 		submissionRepo: submissionRepo,
 		challengeRepo:  challengeRepo,
 		teamRepo:       teamRepo,
+		ledgerRepo:     ledgerRepo,
 	}
 }
 
@@ -97,13 +100,20 @@ func (h *ProfileHandler) GetUserProfile(c *gin.Context) {
 		submissions = []models.Submission{} // Empty if error
 	}
 
+	// Points come from the score ledger, so they match the scoreboards
+	ledger, err := h.ledgerRepo.GetByUser(user.ID, "")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch score"})
+		return
+	}
+	challengePoints := models.LedgerChallengePoints(ledger)
+
 	// Get total submission count
 	totalSubmissions, _ := h.submissionRepo.GetUserSubmissionCount(user.ID)
 
 	// Build solved challenges list and calculate stats
 	var solvedChallenges []SolvedChallenge
 	categoryStatsMap := make(map[string]*CategoryStats)
-	totalPoints := 0
 	seenChallenges := make(map[string]bool) // To avoid duplicates
 
 	for _, sub := range submissions {
@@ -118,8 +128,7 @@ func (h *ProfileHandler) GetUserProfile(c *gin.Context) {
 			continue
 		}
 
-		points := challenge.CurrentPoints()
-		totalPoints += points
+		points := challengePoints[challengeID]
 
 		solvedChallenges = append(solvedChallenges, SolvedChallenge{
 			ID:         challengeID,
@@ -160,7 +169,7 @@ func (h *ProfileHandler) GetUserProfile(c *gin.Context) {
 		JoinedAt:         user.CreatedAt.Format("2006-01-02T15:04:05Z"),
 		TeamID:           teamID,
 		TeamName:         teamName,
-		TotalPoints:      totalPoints,
+		TotalPoints:      models.LedgerTotal(ledger),
 		SolveCount:       len(solvedChallenges),
 		TotalSubmissions: totalSubmissions,
 		SolvedChallenges: solvedChallenges,
@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/Uttam-Mahata/RootAccess/backend/internal/services"
+	"github.com/Uttam-Mahata/RootAccess/backend/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type ReconciliationHandler struct {
+	reconciliationService *services.ReconciliationService
+}
+
+func NewReconciliationHandler(reconciliationService *services.ReconciliationService) *ReconciliationHandler {
+	return &ReconciliationHandler{reconciliationService: reconciliationService}
+}
+
+type ApplyReconciliationRequest struct {
+	Confirm bool `json:"confirm"` // must be true; preview the changes with GET first
+}
+
+// PreviewReconciliation lists the counters that drifted from the raw submissions
+// @Summary Preview score reconciliation
+// @Description Recompute challenge solve counts, per-contest solve counts, the score ledger and team scores from the raw submissions and list what differs. Nothing is changed.
+// @Tags Admin
+// @Produce json
+// @Success 200 {object} models.ReconciliationReport
+// @Security ApiKeyAuth
+// @Router /admin/reconciliation [get]
+func (h *ReconciliationHandler) PreviewReconciliation(c *gin.Context) {
+	report, err := h.reconciliationService.Preview()
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, err.Error(), err)
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// ApplyReconciliation repairs the counters that drifted from the raw submissions
+// @Summary Apply score reconciliation
+// @Description Repair every counter the preview lists, record the score ledger corrections and flush the cached scoreboards. Requires confirm to be true.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param request body ApplyReconciliationRequest true "Confirmation"
+// @Success 200 {object} models.ReconciliationReport
+// @Failure 400 {object} map[string]string
+// @Security ApiKeyAuth
+// @Router /admin/reconciliation [post]
+func (h *ReconciliationHandler) ApplyReconciliation(c *gin.Context) {
+	var req ApplyReconciliationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+	if !req.Confirm {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Set confirm to true to apply the reconciliation"})
+		return
+	}
+
+	report, err := h.reconciliationService.Apply(c.GetString("user_id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, err.Error(), err)
+		return
+	}
+	c.Set("audit_details", fmt.Sprintf("Reconciled scores: %d counters repaired, %d score ledger corrections",
+		len(report.Drifts), len(report.LedgerEntries)))
+	c.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"time"
+
+	"github.com/Uttam-Mahata/RootAccess/backend/internal/services"
+	"github.com/Uttam-Mahata/RootAccess/backend/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type SchedulerHandler struct {
+	scheduler *services.ChallengeScheduler
+	token     string
+}
+
+func NewSchedulerHandler(scheduler *services.ChallengeScheduler, token string) *SchedulerHandler {
+	return &SchedulerHandler{
+		scheduler: scheduler,
+		token:     token,
+	}
+}
+
+// Tick runs one scheduler pass for external triggers (cron, EventBridge via HTTP).
+// Requires the X-Scheduler-Token header to match SCHEDULER_TOKEN; disabled when the token is unset.
+func (h *SchedulerHandler) Tick(c *gin.Context) {
+	if h.token == "" {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Not found"})
+		return
+	}
+	if subtle.ConstantTimeCompare([]byte(c.GetHeader("X-Scheduler-Token")), []byte(h.token)) != 1 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid scheduler token"})
+		return
+	}
+	h.runTick(c, services.SchedulerActor)
+}
+
+// AdminTick lets an admin trigger a scheduler pass manually
+func (h *SchedulerHandler) AdminTick(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	actor, _ := userID.(string)
+	if actor == "" {
+		actor = services.SchedulerActor
+	}
+	h.runTick(c, actor)
+}
+
+func (h *SchedulerHandler) runTick(c *gin.Context, actor string) {
+	released, err := h.scheduler.Tick(time.Now(), actor)
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, err.Error(), err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"released": released,
+		"count":    len(released),
+	})
+}
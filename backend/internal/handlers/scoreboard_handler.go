@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"time"
 
@@ -26,10 +27,11 @@ func NewScoreboardHandler(scoreboardService *services.ScoreboardService, contest
 
 // GetScoreboard returns the individual scoreboard for a contest
 // @Summary Get individual scoreboard
-// @Description Retrieve the current leaderboard for individual users in a contest, sorted by points.
+// @Description Retrieve the current leaderboard for individual users in a contest, sorted by points. With as_of, the leaderboard as it stood at that moment, rounded down to the minute, counting only the solves, hint reveals and adjustments recorded by then at the point values of the time.
 // @Tags Scoreboard
 // @Produce json
 // @Param contest_id query string true "Contest ID"
+// @Param as_of query string false "RFC3339 timestamp to compute the scoreboard as of"
 // @Success 200 {array} services.UserScore
 // @Failure 400 {object} map[string]string
 // @Failure 403 {object} map[string]string
@@ -53,7 +55,12 @@ func (h *ScoreboardHandler) GetScoreboard(c *gin.Context) {
 		}
 	}
 
-	scores, err := h.scoreboardService.GetScoreboard(contestID)
+	asOf, ok := parseAsOf(c)
+	if !ok {
+		return
+	}
+
+	scores, err := h.scoreboardService.GetScoreboard(contestID, asOf)
 	if err != nil {
 		utils.RespondWithError(c, http.StatusInternalServerError, err.Error(), err)
 		return
@@ -64,10 +71,11 @@ func (h *ScoreboardHandler) GetScoreboard(c *gin.Context) {
 
 // GetTeamScoreboard returns the team scoreboard for a contest
 // @Summary Get team scoreboard
-// @Description Retrieve the current leaderboard for teams in a contest, sorted by points.
+// @Description Retrieve the current leaderboard for teams in a contest, sorted by points. With as_of, the leaderboard as it stood at that moment, rounded down to the minute, counting only the solves, hint reveals and adjustments recorded by then at the point values of the time.
 // @Tags Scoreboard
 // @Produce json
 // @Param contest_id query string true "Contest ID"
+// @Param as_of query string false "RFC3339 timestamp to compute the scoreboard as of"
 // @Success 200 {object} map[string]interface{}
 // @Failure 400 {object} map[string]string
 // @Failure 403 {object} map[string]string
@@ -91,7 +99,12 @@ func (h *ScoreboardHandler) GetTeamScoreboard(c *gin.Context) {
 		}
 	}
 
-	scores, err := h.scoreboardService.GetTeamScoreboard(contestID)
+	asOf, ok := parseAsOf(c)
+	if !ok {
+		return
+	}
+
+	scores, err := h.scoreboardService.GetTeamScoreboard(contestID, asOf)
 	if err != nil {
 		utils.RespondWithError(c, http.StatusInternalServerError, err.Error(), err)
 		return
@@ -150,6 +163,76 @@ func (h *ScoreboardHandler) GetTeamStatistics(c *gin.Context) {
 	})
 }
 
+// GetMyScoreBreakdown itemizes the caller's score on a contest's individual scoreboard
+// @Summary Get my score breakdown
+// @Description Solves, first blood bonuses, partial credit, hint costs and adjustments that make up the caller's score.
+// @Tags Scoreboard
+// @Produce json
+// @Param contest_id query string true "Contest ID"
+// @Success 200 {object} models.ScoreBreakdown
+// @Failure 400 {object} map[string]string
+// @Security ApiKeyAuth
+// @Router /scoreboard/breakdown [get]
+func (h *ScoreboardHandler) GetMyScoreBreakdown(c *gin.Context) {
+	contestID, ok := h.breakdownContest(c)
+	if !ok {
+		return
+	}
+
+	breakdown, err := h.scoreboardService.GetUserScoreBreakdown(contestID, c.GetString("user_id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, err.Error(), err)
+		return
+	}
+	c.JSON(http.StatusOK, breakdown)
+}
+
+// GetMyTeamScoreBreakdown itemizes the score of the caller's team on a contest's team scoreboard
+// @Summary Get my team's score breakdown
+// @Description Solves, first blood bonuses, partial credit, hint costs and adjustments that make up the team's score.
+// @Tags Scoreboard
+// @Produce json
+// @Param contest_id query string true "Contest ID"
+// @Success 200 {object} models.ScoreBreakdown
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Security ApiKeyAuth
+// @Router /scoreboard/teams/breakdown [get]
+func (h *ScoreboardHandler) GetMyTeamScoreBreakdown(c *gin.Context) {
+	contestID, ok := h.breakdownContest(c)
+	if !ok {
+		return
+	}
+
+	breakdown, err := h.scoreboardService.GetMemberTeamScoreBreakdown(contestID, c.GetString("user_id"))
+	if err != nil {
+		if errors.Is(err, services.ErrNotInTeam) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		utils.RespondWithError(c, http.StatusInternalServerError, err.Error(), err)
+		return
+	}
+	c.JSON(http.StatusOK, breakdown)
+}
+
+// breakdownContest reads and checks the contest_id of a breakdown request. Breakdowns
+// follow the scoreboard's freeze but not its visibility: callers only see their own score.
+func (h *ScoreboardHandler) breakdownContest(c *gin.Context) (string, bool) {
+	contestID := c.Query("contest_id")
+	if contestID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "contest_id is required"})
+		return "", false
+	}
+	if h.contestEntityRepo != nil {
+		if _, err := h.contestEntityRepo.FindByID(contestID); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "contest not found"})
+			return "", false
+		}
+	}
+	return contestID, true
+}
+
 // GetScoreboardContests returns contests that should appear on the scoreboard
 // @Summary Get scoreboard contests
 // @Description Retrieve contests eligible for scoreboard display (running + ended)
@@ -208,6 +291,20 @@ func (h *ScoreboardHandler) GetScoreboardContests(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"contests": result})
 }
 
+// parseAsOf reads the optional as_of timestamp of a scoreboard request
+func parseAsOf(c *gin.Context) (*time.Time, bool) {
+	param := c.Query("as_of")
+	if param == "" {
+		return nil, true
+	}
+	asOf, err := time.Parse(time.RFC3339, param)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "as_of must be an RFC3339 timestamp"})
+		return nil, false
+	}
+	return &asOf, true
+}
+
 func parseInt(s string) int {
 	var result int
 	for _, char := range s {
@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/Uttam-Mahata/RootAccess/backend/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// ScoringHandler exposes the registered scoring strategies to challenge authors
+type ScoringHandler struct{}
+
+func NewScoringHandler() *ScoringHandler {
+	return &ScoringHandler{}
+}
+
+// ScoringStrategyResponse describes a registered scoring strategy
+type ScoringStrategyResponse struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// ListStrategies returns every scoring strategy a challenge or contest can use
+// @Summary List scoring strategies
+// @Tags Admin Scoring
+// @Produce json
+// @Success 200 {array} ScoringStrategyResponse
+// @Security ApiKeyAuth
+// @Router /admin/scoring/strategies [get]
+func (h *ScoringHandler) ListStrategies(c *gin.Context) {
+	strategies := models.ScoringStrategies()
+	response := make([]ScoringStrategyResponse, 0, len(strategies))
+	for _, s := range strategies {
+		response = append(response, ScoringStrategyResponse{Name: s.Name(), Description: s.Description()})
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// PreviewCurve returns the points a challenge would be worth after 0..solves solves
+// @Summary Preview a scoring curve
+// @Tags Admin Scoring
+// @Produce json
+// @Param scoring_type query string true "Scoring strategy"
+// @Param max_points query int true "Initial value"
+// @Param min_points query int false "Minimum value"
+// @Param decay query int false "Strategy decay parameter"
+// @Param solves query int false "Number of solves to preview (default 50)"
+// @Success 200 {object} map[string]interface{}
+// @Security ApiKeyAuth
+// @Router /admin/scoring/preview [get]
+func (h *ScoringHandler) PreviewCurve(c *gin.Context) {
+	scoringType := c.Query("scoring_type")
+	if !models.IsValidScoringType(scoringType) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid scoring_type"})
+		return
+	}
+
+	var params models.ScoringParams
+	solves := 50
+	for _, q := range []struct {
+		name string
+		dst  *int
+	}{
+		{"max_points", &params.MaxPoints},
+		{"min_points", &params.MinPoints},
+		{"decay", &params.Decay},
+		{"solves", &solves},
+	} {
+		raw := c.Query(q.name)
+		if raw == "" {
+			continue
+		}
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid " + q.name})
+			return
+		}
+		*q.dst = n
+	}
+	if err := models.ValidateScoringParams(params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if solves < 0 || solves > models.MaxCurvePreviewSolves {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "solves must be between 0 and " + strconv.Itoa(models.MaxCurvePreviewSolves)})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"scoring_type": scoringType,
+		"params":       params,
+		"curve":        models.PointsCurve(models.ScoringStrategyFor(scoringType), params, solves),
+	})
+}
@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/Uttam-Mahata/RootAccess/backend/internal/models"
+	"github.com/Uttam-Mahata/RootAccess/backend/internal/services"
+	"github.com/Uttam-Mahata/RootAccess/backend/internal/utils"
+	websocketPkg "github.com/Uttam-Mahata/RootAccess/backend/internal/websocket"
+	"github.com/gin-gonic/gin"
+)
+
+type SubmissionHandler struct {
+	invalidationService *services.SubmissionInvalidationService
+	wsHub               websocketPkg.Hub
+}
+
+func NewSubmissionHandler(invalidationService *services.SubmissionInvalidationService, wsHub websocketPkg.Hub) *SubmissionHandler {
+	return &SubmissionHandler{
+		invalidationService: invalidationService,
+		wsHub:               wsHub,
+	}
+}
+
+type InvalidateSubmissionRequest struct {
+	Reason string `json:"reason" binding:"required"` // shown to the team and written to the audit log
+}
+
+// GetInvalidatedSubmissions lists invalidated submissions
+// @Summary List invalidated submissions
+// @Tags Admin
+// @Produce json
+// @Param contest_id query string false "Contest ID"
+// @Success 200 {array} models.Submission
+// @Security ApiKeyAuth
+// @Router /admin/submissions/invalidated [get]
+func (h *SubmissionHandler) GetInvalidatedSubmissions(c *gin.Context) {
+	subs, err := h.invalidationService.ListInvalidated(c.Query("contest_id"))
+	if err != nil {
+		utils.RespondWithError(c, http.StatusInternalServerError, err.Error(), err)
+		return
+	}
+	if subs == nil {
+		subs = []models.Submission{}
+	}
+	c.JSON(http.StatusOK, subs)
+}
+
+// InvalidateSubmission takes back a solve, e.g. after catching cheating
+// @Summary Invalidate a submission
+// @Description The solve stops counting on every scoreboard, the challenge's solve counts are rebuilt and the score ledger records the points taken back. The team is notified. Reversible with the restore endpoint.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Submission ID"
+// @Param request body InvalidateSubmissionRequest true "Reason"
+// @Success 200 {object} models.Submission
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Security ApiKeyAuth
+// @Router /admin/submissions/{id}/invalidate [post]
+func (h *SubmissionHandler) InvalidateSubmission(c *gin.Context) {
+	var req InvalidateSubmissionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.RespondWithError(c, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	sub, err := h.invalidationService.Invalidate(c.Param("id"), req.Reason, c.GetString("user_id"))
+	if err != nil {
+		h.respondWithError(c, err)
+		return
+	}
+
+	c.Set("audit_details", fmt.Sprintf("Invalidated submission %s on challenge %s by user %s (team %s): %s",
+		sub.ID, sub.ChallengeID, sub.UserID, sub.TeamID, sub.InvalidationReason))
+	h.notify(sub, "submission_invalidated", gin.H{
+		"submission_id": sub.ID,
+		"challenge_id":  sub.ChallengeID,
+		"contest_id":    sub.ContestID,
+		"reason":        sub.InvalidationReason,
+	})
+	c.JSON(http.StatusOK, sub)
+}
+
+// RestoreSubmission undoes an invalidation
+// @Summary Restore an invalidated submission
+// @Tags Admin
+// @Produce json
+// @Param id path string true "Submission ID"
+// @Success 200 {object} models.Submission
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Security ApiKeyAuth
+// @Router /admin/submissions/{id}/restore [post]
+func (h *SubmissionHandler) RestoreSubmission(c *gin.Context) {
+	sub, err := h.invalidationService.Restore(c.Param("id"), c.GetString("user_id"))
+	if err != nil {
+		h.respondWithError(c, err)
+		return
+	}
+
+	c.Set("audit_details", fmt.Sprintf("Restored submission %s on challenge %s by user %s (team %s)",
+		sub.ID, sub.ChallengeID, sub.UserID, sub.TeamID))
+	h.notify(sub, "submission_restored", gin.H{
+		"submission_id": sub.ID,
+		"challenge_id":  sub.ChallengeID,
+		"contest_id":    sub.ContestID,
+	})
+	c.JSON(http.StatusOK, sub)
+}
+
+func (h *SubmissionHandler) respondWithError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrSubmissionNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrAlreadyInvalidated), errors.Is(err, services.ErrNotInvalidated),
+		errors.Is(err, services.ErrSolvedSinceInvalidate):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrSubmissionNotSolve), errors.Is(err, services.ErrInvalidationReason):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		utils.RespondWithError(c, http.StatusInternalServerError, err.Error(), err)
+	}
+}
+
+// notify tells the affected team (or user) and refreshes open scoreboards
+func (h *SubmissionHandler) notify(sub *models.Submission, msgType string, payload gin.H) {
+	if h.wsHub == nil {
+		return
+	}
+	for _, userID := range h.invalidationService.Recipients(sub) {
+		h.wsHub.SendToUser(userID, msgType, payload)
+	}
+	h.wsHub.BroadcastMessage("scoreboard_update", gin.H{
+		"updated": true,
+	})
+}
@@ -36,6 +36,10 @@ func AuditMiddleware(auditService *services.AuditLogService) gin.HandlerFunc {
 		action := method + " " + c.FullPath()
 		resource := c.FullPath()
 		details := "Path: " + c.Request.URL.Path
+		// Handlers can describe what they did, e.g. the reason an admin gave
+		if extra := c.GetString("audit_details"); extra != "" {
+			details += "; " + extra
+		}
 
 		auditService.Log(userID, usernameStr, action, resource, details, utils.GetClientIP(c))
 	}
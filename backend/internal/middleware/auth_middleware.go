@@ -5,6 +5,8 @@ import (
 	"net/http"
 
 	"github.com/Uttam-Mahata/RootAccess/backend/internal/config"
+	"github.com/Uttam-Mahata/RootAccess/backend/internal/models"
+	"github.com/Uttam-Mahata/RootAccess/backend/internal/repositories"
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
 )
@@ -59,7 +61,7 @@ func AuthMiddleware(cfg *config.Config) gin.HandlerFunc {
 func AdminMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		role, exists := c.Get("role")
-		if !exists || role != "admin" {
+		if !exists || role != models.RoleAdmin {
 			c.JSON(http.StatusForbidden, gin.H{"error": "Admin access required"})
 			c.Abort()
 			return
@@ -67,3 +69,60 @@ func AdminMiddleware() gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// StaffMiddleware admits admins and challenge authors. Routes behind it that act on a
+// challenge must also use ChallengeOwnerMiddleware so authors only reach their own.
+func StaffMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role, _ := c.Get("role")
+		if role != models.RoleAdmin && role != models.RoleAuthor {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Admin access required"})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// ChallengeOwnerMiddleware limits authors to the challenge named by the :id route parameter
+// when they own it. Admins pass through.
+func ChallengeOwnerMiddleware(challengeRepo *repositories.ChallengeRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if role, _ := c.Get("role"); role == models.RoleAdmin {
+			c.Next()
+			return
+		}
+		challenge, err := challengeRepo.GetChallengeByID(c.Param("id"))
+		if err != nil || challenge.AuthorID != c.GetString("user_id") {
+			// Not found either way, so authors cannot probe for other challenges
+			c.JSON(http.StatusNotFound, gin.H{"error": "Challenge not found"})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// ChallengeEditMiddleware keeps authors without admin rights from changing a published
+// challenge, which would put content live that nobody signed off. Runs after
+// ChallengeOwnerMiddleware on the routes that change a challenge's content.
+func ChallengeEditMiddleware(challengeRepo *repositories.ChallengeRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if role, _ := c.Get("role"); role == models.RoleAdmin {
+			c.Next()
+			return
+		}
+		challenge, err := challengeRepo.GetChallengeByID(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Challenge not found"})
+			c.Abort()
+			return
+		}
+		if !models.AuthorMayEdit(challenge.Status) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Published challenges need a new review before they change; ask an admin to unpublish it first"})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRoleMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		role      string
+		wantStaff int
+		wantAdmin int
+	}{
+		{"admin", http.StatusOK, http.StatusOK},
+		{"author", http.StatusOK, http.StatusForbidden},
+		{"user", http.StatusForbidden, http.StatusForbidden},
+		{"", http.StatusForbidden, http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		router := gin.New()
+		setRole := func(c *gin.Context) {
+			if tt.role != "" {
+				c.Set("role", tt.role)
+			}
+		}
+		ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+		router.GET("/staff", setRole, StaffMiddleware(), ok)
+		router.GET("/admin", setRole, AdminMiddleware(), ok)
+
+		for path, want := range map[string]int{"/staff": tt.wantStaff, "/admin": tt.wantAdmin} {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+			if w.Code != want {
+				t.Errorf("role %q on %s: status %d, want %d", tt.role, path, w.Code, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/Uttam-Mahata/RootAccess/backend/internal/database"
+	"github.com/Uttam-Mahata/RootAccess/backend/internal/models"
+	"github.com/Uttam-Mahata/RootAccess/backend/internal/repositories"
+	"github.com/gin-gonic/gin"
+	_ "modernc.org/sqlite"
+)
+
+func TestChallengeEditMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	db, err := sql.Open("sqlite", "file:"+filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("sql.Open() error = %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	database.BootstrapSchema(db)
+
+	for id, status := range map[string]string{
+		"live":     models.ChallengeStatusPublished,
+		"approved": models.ChallengeStatusApproved,
+		"draft":    models.ChallengeStatusDraft,
+	} {
+		_, err := db.Exec(`INSERT INTO challenges (id, title, description, description_format, category, difficulty,
+			max_points, min_points, decay, scoring_type, flag_hash, files, tags, scheduled_at, contest_id,
+			official_writeup, official_writeup_format, status, author_id)
+			VALUES (?, ?, '', 'markdown', 'web', 'easy', 500, 100, 10, 'dynamic', '', '[]', '[]', '', '', '', 'markdown', ?, 'author-1')`, id, id, status)
+		if err != nil {
+			t.Fatalf("creating challenge %s: %v", id, err)
+		}
+	}
+	repo := repositories.NewChallengeRepository(db)
+
+	tests := []struct {
+		role      string
+		challenge string
+		want      int
+	}{
+		// An author cannot change live content without a new sign-off
+		{models.RoleAuthor, "live", http.StatusForbidden},
+		{models.RoleAuthor, "approved", http.StatusOK},
+		{models.RoleAuthor, "draft", http.StatusOK},
+		{models.RoleAdmin, "live", http.StatusOK},
+		{models.RoleAuthor, "missing", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		router := gin.New()
+		setRole := func(c *gin.Context) {
+			c.Set("role", tt.role)
+			c.Set("user_id", "author-1")
+		}
+		router.PUT("/challenges/:id", setRole, ChallengeOwnerMiddleware(repo), ChallengeEditMiddleware(repo), func(c *gin.Context) {
+			c.Status(http.StatusOK)
+		})
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("PUT", "/challenges/"+tt.challenge, nil))
+		if w.Code != tt.want {
+			t.Errorf("%s editing %s challenge: status %d, want %d", tt.role, tt.challenge, w.Code, tt.want)
+		}
+	}
+}
@@ -22,6 +22,15 @@ var flagSubmitLimiter = &localLimiter{
 	attempts: make(map[string][]time.Time),
 }
 
+// rateLimitRefundKey marks a request that should not count against RateLimitMiddleware
+const rateLimitRefundKey = "rate_limit_refund"
+
+// RefundRateLimit tells RateLimitMiddleware not to count the current request, e.g. a
+// flag rejected for its format before it was checked
+func RefundRateLimit(c *gin.Context) {
+	c.Set(rateLimitRefundKey, true)
+}
+
 // RateLimitMiddleware limits requests per user per challenge.
 // Uses Redis (distributed) with in-memory fallback for local dev.
 func RateLimitMiddleware(maxAttempts int, window time.Duration) gin.HandlerFunc {
@@ -42,6 +51,7 @@ func RateLimitMiddleware(maxAttempts int, window time.Duration) gin.HandlerFunc
 		key := userID.(string) + ":" + challengeID
 
 		if rdb := getRateLimitRedis(); rdb != nil {
+			checkedAt := time.Now()
 			limited, remaining, retryAfter := checkRedisRateLimit(rdb, "rl:flag:"+key, maxAttempts, window)
 			setRateLimitHeaders(c, maxAttempts, remaining)
 			if limited {
@@ -54,6 +64,9 @@ func RateLimitMiddleware(maxAttempts int, window time.Duration) gin.HandlerFunc
 				return
 			}
 			c.Next()
+			if c.GetBool(rateLimitRefundKey) {
+				refundRedisRateLimit(rdb, "rl:flag:"+key, window, checkedAt)
+			}
 			return
 		}
 
@@ -70,6 +83,9 @@ func RateLimitMiddleware(maxAttempts int, window time.Duration) gin.HandlerFunc
 			return
 		}
 		c.Next()
+		if c.GetBool(rateLimitRefundKey) {
+			refundLocalRateLimit(flagSubmitLimiter, key)
+		}
 	}
 }
 
@@ -149,6 +165,22 @@ func checkLocalRateLimit(limiter *localLimiter, key string, maxAttempts int, win
 	return false, remaining, 0
 }
 
+// refundRedisRateLimit takes back an increment made by checkRedisRateLimit at checkedAt
+func refundRedisRateLimit(rdb *redis.Client, key string, window time.Duration, checkedAt time.Time) {
+	bucket := checkedAt.Unix() / int64(window.Seconds())
+	rdb.Decr(context.Background(), fmt.Sprintf("%s:%d", key, bucket))
+}
+
+// refundLocalRateLimit drops the most recent attempt recorded by checkLocalRateLimit
+func refundLocalRateLimit(limiter *localLimiter, key string) {
+	limiter.mu.Lock()
+	defer limiter.mu.Unlock()
+
+	if attempts := limiter.attempts[key]; len(attempts) > 0 {
+		limiter.attempts[key] = attempts[:len(attempts)-1]
+	}
+}
+
 func setRateLimitHeaders(c *gin.Context, limit, remaining int) {
 	c.Header("X-RateLimit-Limit", fmt.Sprintf("%d", limit))
 	c.Header("X-RateLimit-Remaining", fmt.Sprintf("%d", remaining))
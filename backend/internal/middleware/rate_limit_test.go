@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRateLimitMiddlewareRefund(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	// Reset the global local limiter for testing (no Redis in tests)
+	flagSubmitLimiter = &localLimiter{
+		attempts: make(map[string][]time.Time),
+	}
+
+	maxAttempts := 2
+	middleware := RateLimitMiddleware(maxAttempts, time.Minute)
+
+	router := gin.New()
+	router.POST("/challenges/:id/submit", func(c *gin.Context) {
+		c.Set("user_id", "u1")
+	}, middleware, func(c *gin.Context) {
+		if c.Query("refund") == "1" {
+			RefundRateLimit(c)
+		}
+		c.Status(http.StatusOK)
+	})
+
+	submit := func(refund bool) int {
+		url := "/challenges/c1/submit"
+		if refund {
+			url += "?refund=1"
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("POST", url, nil))
+		return w.Code
+	}
+
+	t.Run("refunded requests do not count", func(t *testing.T) {
+		for i := 0; i < 5; i++ {
+			if code := submit(true); code == http.StatusTooManyRequests {
+				t.Fatalf("Refunded request %d should not be rate limited", i+1)
+			}
+		}
+	})
+
+	t.Run("other requests still count", func(t *testing.T) {
+		for i := 0; i < maxAttempts; i++ {
+			if code := submit(false); code == http.StatusTooManyRequests {
+				t.Fatalf("Request %d should not be rate limited", i+1)
+			}
+		}
+		if code := submit(false); code != http.StatusTooManyRequests {
+			t.Errorf("Request over the limit should be rate limited, got status %d", code)
+		}
+	})
+}
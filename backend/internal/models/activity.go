@@ -34,6 +34,7 @@ type AdminAnalytics struct {
 	TotalChallenges     int                   `json:"total_challenges"`
 	TotalSubmissions    int                   `json:"total_submissions"`
 	TotalCorrect        int                   `json:"total_correct"`
+	TotalInvalidFormat  int                   `json:"total_invalid_format"` // of TotalSubmissions, those rejected for their format
 	SuccessRate         float64               `json:"success_rate"`
 	ChallengePopularity []ChallengePopularity `json:"challenge_popularity"`
 	CategoryBreakdown   map[string]int        `json:"category_breakdown"`
@@ -67,6 +68,16 @@ type ChallengePopularity struct {
 	SuccessRate  float64 `json:"success_rate"`
 }
 
+// ChallengeSolveStats summarizes how players fared on a single challenge
+type ChallengeSolveStats struct {
+	ChallengePopularity
+	WrongAttempts int             `json:"wrong_attempts"`
+	UniqueSolvers int             `json:"unique_solvers"` // teams, plus users who solved without a team
+	FirstSolveAt  *time.Time      `json:"first_solve_at,omitempty"`
+	LatestSolveAt *time.Time      `json:"latest_solve_at,omitempty"`
+	Feedback      FeedbackSummary `json:"feedback"`
+}
+
 type RecentActivityEntry struct {
 	UserID        string    `json:"user_id"`
 	Username      string    `json:"username"`
@@ -0,0 +1,34 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+// AttemptReset clears a team's wrong-submission count on a challenge. Only wrong
+// submissions made after the latest reset count against the challenge's MaxAttempts.
+type AttemptReset struct {
+	ID          string    `json:"id"`
+	ChallengeID string    `json:"challenge_id"`
+	OwnerID     string    `json:"owner_id"` // team ID, or user ID for players without a team
+	ContestID   string    `json:"contest_id,omitempty"`
+	ResetBy     string    `json:"reset_by"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// ValidateMaxAttempts checks a per-challenge attempt cap; 0 means unlimited
+func ValidateMaxAttempts(maxAttempts int) error {
+	if maxAttempts < 0 {
+		return errors.New("max_attempts cannot be negative")
+	}
+	return nil
+}
+
+// AttemptsRemaining returns how many wrong submissions are left under a cap of maxAttempts
+// after used of them. Callers check maxAttempts > 0 first; a cap of 0 is unlimited.
+func AttemptsRemaining(maxAttempts, used int) int {
+	if used >= maxAttempts {
+		return 0
+	}
+	return maxAttempts - used
+}
@@ -0,0 +1,32 @@
+package models
+
+import "testing"
+
+func TestAttemptsRemaining(t *testing.T) {
+	tests := []struct {
+		maxAttempts, used, want int
+	}{
+		{5, 0, 5},
+		{5, 3, 2},
+		{5, 5, 0},
+		{5, 7, 0}, // cap lowered after the team already used more
+		{1, 0, 1},
+	}
+
+	for _, tt := range tests {
+		if got := AttemptsRemaining(tt.maxAttempts, tt.used); got != tt.want {
+			t.Errorf("AttemptsRemaining(%d, %d) = %d, want %d", tt.maxAttempts, tt.used, got, tt.want)
+		}
+	}
+}
+
+func TestValidateMaxAttempts(t *testing.T) {
+	for _, n := range []int{0, 1, 100} {
+		if err := ValidateMaxAttempts(n); err != nil {
+			t.Errorf("ValidateMaxAttempts(%d) = %v, want nil", n, err)
+		}
+	}
+	if err := ValidateMaxAttempts(-1); err == nil {
+		t.Error("ValidateMaxAttempts(-1) = nil, want error")
+	}
+}
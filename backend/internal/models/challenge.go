@@ -1,9 +1,5 @@
 package models
 
-import (
-	"math"
-)
-
 const (
 	ScoringStatic  = "static"
 	ScoringLinear  = "linear"
@@ -11,91 +7,59 @@ const (
 )
 
 type Challenge struct {
-	ID                       string   `json:"id"`
-	Title                    string   `json:"title"`
-	Description              string   `json:"description"`
-	DescriptionFormat        string   `json:"description_format"`
-	Category                 string   `json:"category"`
-	Difficulty               string   `json:"difficulty"`
-	MaxPoints                int      `json:"max_points"`
-	MinPoints                int      `json:"min_points"`
-	Decay                    int      `json:"decay"`
-	ScoringType              string   `json:"scoring_type"`
-	SolveCount               int      `json:"solve_count"`
-	FlagHash                 string   `json:"-"`
-	Files                    []string `json:"files"`
-	Tags                     []string `json:"tags"`
-	ScheduledAt              string   `json:"scheduled_at,omitempty"`
-	IsPublished              bool     `json:"is_published"`
-	Hints                    []Hint   `json:"hints,omitempty"`
-	ContestID                string   `json:"contest_id,omitempty"`
-	OfficialWriteup          string   `json:"official_writeup,omitempty"`
-	OfficialWriteupFormat    string   `json:"official_writeup_format,omitempty"`
-	OfficialWriteupPublished bool     `json:"official_writeup_published"`
+	ID                       string                  `json:"id"`
+	Title                    string                  `json:"title"`
+	Description              string                  `json:"description"`
+	DescriptionFormat        string                  `json:"description_format"`
+	Category                 string                  `json:"category"`
+	Difficulty               string                  `json:"difficulty"`
+	MaxPoints                int                     `json:"max_points"`
+	MinPoints                int                     `json:"min_points"`
+	Decay                    int                     `json:"decay"`
+	ScoringType              string                  `json:"scoring_type"`
+	SolveCount               int                     `json:"solve_count"`
+	FlagHash                 string                  `json:"-"`
+	Flags                    []ChallengeFlag         `json:"-"`
+	Files                    []string                `json:"files"`
+	Tags                     []string                `json:"tags"`
+	ScheduledAt              string                  `json:"scheduled_at,omitempty"`
+	IsPublished              bool                    `json:"is_published"`
+	Status                   string                  `json:"status"`
+	AuthorID                 string                  `json:"author_id,omitempty"`
+	Hints                    []Hint                  `json:"hints,omitempty"`
+	ContestID                string                  `json:"contest_id,omitempty"`
+	OfficialWriteup          string                  `json:"official_writeup,omitempty"`
+	OfficialWriteupFormat    string                  `json:"official_writeup_format,omitempty"`
+	OfficialWriteupPublished bool                    `json:"official_writeup_published"`
+	Prerequisites            []ChallengePrerequisite `json:"prerequisites,omitempty"`
+	FirstBloodBonus          []int                   `json:"first_blood_bonus,omitempty"` // bonus percentage per solver place
+	MaxAttempts              int                     `json:"max_attempts"`                // wrong submissions allowed per team per contest, 0 for unlimited
+	GradingMode              string                  `json:"grading_mode"`                // auto or manual
+	FlagFormat               string                  `json:"flag_format,omitempty"`       // overrides the contest's flag format
+}
+
+// ScoringParams returns the settings the challenge's scoring strategy works from
+func (c *Challenge) ScoringParams() ScoringParams {
+	return ScoringParams{MaxPoints: c.MaxPoints, MinPoints: c.MinPoints, Decay: c.Decay}
 }
 
+// CurrentPoints is the challenge's value for its global SolveCount under its own scoring strategy
 func (c *Challenge) CurrentPoints() int {
-	switch c.ScoringType {
-	case ScoringStatic:
-		return c.MaxPoints
-	case ScoringLinear:
-		return c.linearPoints()
-	case ScoringDynamic:
-		return c.dynamicPoints()
-	default:
-		return c.dynamicPoints()
-	}
+	return c.PointsForSolveCount(c.SolveCount)
 }
 
 // PointsForSolveCount computes the current point value using a custom solve count
 // instead of the challenge's global SolveCount. Used for contest-specific scoring.
 func (c *Challenge) PointsForSolveCount(solveCount int) int {
-	orig := c.SolveCount
-	c.SolveCount = solveCount
-	pts := c.CurrentPoints()
-	c.SolveCount = orig
-	return pts
+	return ScoringStrategyFor(c.ScoringType).Points(c.ScoringParams(), solveCount)
 }
 
-func (c *Challenge) dynamicPoints() int {
-	if c.SolveCount <= 0 {
-		return c.MaxPoints
-	}
-
-	decay := c.Decay
-	if decay <= 0 {
-		decay = 10
-	}
-
-	decaySquared := float64(decay * decay)
-	solvesSquared := float64(c.SolveCount * c.SolveCount)
-
-	value := ((float64(c.MinPoints)-float64(c.MaxPoints))/decaySquared)*solvesSquared + float64(c.MaxPoints)
-
-	points := int(math.Round(value))
-	if points < c.MinPoints {
-		return c.MinPoints
+// PointsInContest is PointsForSolveCount under a contest's scoring override;
+// an empty override keeps the challenge's own strategy
+func (c *Challenge) PointsInContest(scoringOverride string, solveCount int) int {
+	scoringType := c.ScoringType
+	if scoringOverride != "" {
+		scoringType = scoringOverride
 	}
-
-	return points
-}
-
-func (c *Challenge) linearPoints() int {
-	if c.SolveCount <= 0 {
-		return c.MaxPoints
-	}
-
-	decay := c.Decay
-	if decay <= 0 {
-		decay = 10
-	}
-
-	decrease := float64(c.MaxPoints-c.MinPoints) * float64(c.SolveCount) / float64(decay)
-	points := int(math.Round(float64(c.MaxPoints) - decrease))
-
-	if points < c.MinPoints {
-		return c.MinPoints
-	}
-
-	return points
+	return ScoringStrategyFor(scoringType).Points(c.ScoringParams(), solveCount)
 }
@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// ChallengeAttachment is a file uploaded for a challenge and kept in the storage provider
+type ChallengeAttachment struct {
+	ID          string    `json:"id"`
+	ChallengeID string    `json:"challenge_id"`
+	Filename    string    `json:"filename"`
+	StorageKey  string    `json:"-"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	SHA256      string    `json:"sha256"`
+	UploadedBy  string    `json:"uploaded_by,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
@@ -0,0 +1,102 @@
+package models
+
+import (
+	"math"
+	"time"
+)
+
+// Ratings are on a 1-5 scale
+const (
+	FeedbackMinRating        = 1
+	FeedbackMaxRating        = 5
+	FeedbackMaxCommentLength = 2000
+)
+
+// ChallengeFeedback is a solver's rating of a challenge. A user has at most one entry per
+// challenge; submitting again replaces it.
+type ChallengeFeedback struct {
+	ID               string    `json:"id"`
+	ChallengeID      string    `json:"challenge_id"`
+	UserID           string    `json:"user_id"`
+	Username         string    `json:"username,omitempty"`
+	TeamID           string    `json:"team_id,omitempty"`
+	ContestID        string    `json:"contest_id,omitempty"` // contest in which the challenge was solved
+	DifficultyRating int       `json:"difficulty_rating"`
+	QualityRating    int       `json:"quality_rating"`
+	Comment          string    `json:"comment,omitempty"`
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+// FeedbackSummary aggregates ratings. Distributions are indexed by rating - 1.
+type FeedbackSummary struct {
+	ResponseCount          int     `json:"response_count"`
+	AverageDifficulty      float64 `json:"average_difficulty"`
+	AverageQuality         float64 `json:"average_quality"`
+	DifficultyDistribution [5]int  `json:"difficulty_distribution"`
+	QualityDistribution    [5]int  `json:"quality_distribution"`
+	PerceivedDifficulty    string  `json:"perceived_difficulty,omitempty"`
+}
+
+// ChallengeFeedbackStats places solver ratings next to the official difficulty
+type ChallengeFeedbackStats struct {
+	ChallengeID string `json:"challenge_id"`
+	Title       string `json:"title"`
+	Category    string `json:"category"`
+	Difficulty  string `json:"difficulty"`
+	FeedbackSummary
+}
+
+// ContestFeedbackStats aggregates feedback for the challenges solved in a contest
+type ContestFeedbackStats struct {
+	ContestID string `json:"contest_id"`
+	FeedbackSummary
+	Challenges []ChallengeFeedbackStats `json:"challenges"`
+}
+
+func IsValidFeedbackRating(rating int) bool {
+	return rating >= FeedbackMinRating && rating <= FeedbackMaxRating
+}
+
+// SummarizeFeedback averages ratings (rounded to two decimals) and counts each rating value
+func SummarizeFeedback(feedback []ChallengeFeedback) FeedbackSummary {
+	var summary FeedbackSummary
+	var difficultyTotal, qualityTotal int
+	for _, f := range feedback {
+		if !IsValidFeedbackRating(f.DifficultyRating) || !IsValidFeedbackRating(f.QualityRating) {
+			continue
+		}
+		summary.ResponseCount++
+		difficultyTotal += f.DifficultyRating
+		qualityTotal += f.QualityRating
+		summary.DifficultyDistribution[f.DifficultyRating-1]++
+		summary.QualityDistribution[f.QualityRating-1]++
+	}
+	if summary.ResponseCount == 0 {
+		return summary
+	}
+
+	summary.AverageDifficulty = roundRating(float64(difficultyTotal) / float64(summary.ResponseCount))
+	summary.AverageQuality = roundRating(float64(qualityTotal) / float64(summary.ResponseCount))
+	summary.PerceivedDifficulty = PerceivedDifficulty(summary.AverageDifficulty)
+	return summary
+}
+
+// PerceivedDifficulty maps an average difficulty rating onto the easy/medium/hard labels
+// used for the official Difficulty field
+func PerceivedDifficulty(average float64) string {
+	switch {
+	case average <= 0:
+		return ""
+	case average < 2.5:
+		return "easy"
+	case average < 3.5:
+		return "medium"
+	default:
+		return "hard"
+	}
+}
+
+func roundRating(v float64) float64 {
+	return math.Round(v*100) / 100
+}
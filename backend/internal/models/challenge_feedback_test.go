@@ -0,0 +1,58 @@
+package models
+
+import "testing"
+
+func TestSummarizeFeedback(t *testing.T) {
+	feedback := []ChallengeFeedback{
+		{DifficultyRating: 4, QualityRating: 5},
+		{DifficultyRating: 5, QualityRating: 4},
+		{DifficultyRating: 4, QualityRating: 3},
+		{DifficultyRating: 0, QualityRating: 5}, // invalid ratings are ignored
+	}
+
+	got := SummarizeFeedback(feedback)
+
+	if got.ResponseCount != 3 {
+		t.Errorf("ResponseCount = %d, want 3", got.ResponseCount)
+	}
+	if got.AverageDifficulty != 4.33 {
+		t.Errorf("AverageDifficulty = %v, want 4.33", got.AverageDifficulty)
+	}
+	if got.AverageQuality != 4 {
+		t.Errorf("AverageQuality = %v, want 4", got.AverageQuality)
+	}
+	if got.DifficultyDistribution != [5]int{0, 0, 0, 2, 1} {
+		t.Errorf("DifficultyDistribution = %v, want [0 0 0 2 1]", got.DifficultyDistribution)
+	}
+	if got.QualityDistribution != [5]int{0, 0, 1, 1, 1} {
+		t.Errorf("QualityDistribution = %v, want [0 0 1 1 1]", got.QualityDistribution)
+	}
+	if got.PerceivedDifficulty != "hard" {
+		t.Errorf("PerceivedDifficulty = %q, want hard", got.PerceivedDifficulty)
+	}
+
+	if empty := SummarizeFeedback(nil); empty.ResponseCount != 0 || empty.PerceivedDifficulty != "" {
+		t.Errorf("SummarizeFeedback(nil) = %+v, want zero summary", empty)
+	}
+}
+
+func TestPerceivedDifficulty(t *testing.T) {
+	tests := []struct {
+		average float64
+		want    string
+	}{
+		{0, ""},
+		{1, "easy"},
+		{2.49, "easy"},
+		{2.5, "medium"},
+		{3.49, "medium"},
+		{3.5, "hard"},
+		{5, "hard"},
+	}
+
+	for _, tt := range tests {
+		if got := PerceivedDifficulty(tt.average); got != tt.want {
+			t.Errorf("PerceivedDifficulty(%v) = %q, want %q", tt.average, got, tt.want)
+		}
+	}
+}
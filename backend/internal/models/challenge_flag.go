@@ -0,0 +1,33 @@
+package models
+
+const (
+	FlagTypeStatic          = "static"
+	FlagTypeCaseInsensitive = "case_insensitive"
+	FlagTypeRegex           = "regex"
+	FlagTypeDynamic         = "dynamic"
+)
+
+// DynamicFlagPrefix is the wrapper used for generated per-team flags, e.g. RA{base_<hmac>}
+const DynamicFlagPrefix = "RA"
+
+// ChallengeFlag is one accepted answer for a challenge.
+// For static and case-insensitive flags Value holds a SHA-256 hash;
+// for regex flags it holds the pattern itself and for dynamic flags
+// the base that is combined with a per-team HMAC.
+type ChallengeFlag struct {
+	ID          string `json:"id"`
+	ChallengeID string `json:"challenge_id"`
+	Type        string `json:"type"`
+	Value       string `json:"-"`
+}
+
+var FlagTypes = []string{FlagTypeStatic, FlagTypeCaseInsensitive, FlagTypeRegex, FlagTypeDynamic}
+
+func IsValidFlagType(t string) bool {
+	for _, validType := range FlagTypes {
+		if t == validType {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,157 @@
+package models
+
+import "sort"
+
+const (
+	PrerequisiteTypeChallenge = "challenge" // a specific challenge must be solved
+	PrerequisiteTypeCategory  = "category"  // N challenges in a category must be solved
+)
+
+// ChallengePrerequisite is one unlock rule for a challenge. A challenge is unlocked
+// for a team once all of its rules are satisfied.
+type ChallengePrerequisite struct {
+	ID                  string `json:"id"`
+	ChallengeID         string `json:"challenge_id"`
+	Type                string `json:"type"`
+	RequiredChallengeID string `json:"required_challenge_id,omitempty"`
+	Category            string `json:"category,omitempty"`
+	Count               int    `json:"count,omitempty"`
+}
+
+// PrerequisitesMet reports whether every rule is satisfied by the solved challenges,
+// given as a map of solved challenge ID to its category.
+func PrerequisitesMet(prereqs []ChallengePrerequisite, solved map[string]string) bool {
+	var categoryCounts map[string]int
+	for _, p := range prereqs {
+		switch p.Type {
+		case PrerequisiteTypeChallenge:
+			if _, ok := solved[p.RequiredChallengeID]; !ok {
+				return false
+			}
+		case PrerequisiteTypeCategory:
+			if categoryCounts == nil {
+				categoryCounts = make(map[string]int)
+				for _, category := range solved {
+					categoryCounts[category]++
+				}
+			}
+			if categoryCounts[p.Category] < p.Count {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// PrerequisiteNode is a challenge in the unlock graph
+type PrerequisiteNode struct {
+	ID            string
+	Category      string
+	Prerequisites []ChallengePrerequisite
+}
+
+// FindPrerequisiteCycle returns the challenge IDs of a cycle formed by challenge
+// prerequisites (A requires B requires ... requires A), or nil if there is none.
+func FindPrerequisiteCycle(nodes []PrerequisiteNode) []string {
+	edges := make(map[string][]string, len(nodes))
+	for _, n := range nodes {
+		for _, p := range n.Prerequisites {
+			if p.Type == PrerequisiteTypeChallenge {
+				edges[n.ID] = append(edges[n.ID], p.RequiredChallengeID)
+			}
+		}
+	}
+
+	const (
+		unvisited = iota
+		inProgress
+		done
+	)
+	state := make(map[string]int, len(nodes))
+	var stack []string
+
+	var visit func(id string) []string
+	visit = func(id string) []string {
+		state[id] = inProgress
+		stack = append(stack, id)
+		for _, next := range edges[id] {
+			switch state[next] {
+			case inProgress:
+				for i := range stack {
+					if stack[i] == next {
+						return append(append([]string{}, stack[i:]...), next)
+					}
+				}
+			case unvisited:
+				if cycle := visit(next); cycle != nil {
+					return cycle
+				}
+			}
+		}
+		stack = stack[:len(stack)-1]
+		state[id] = done
+		return nil
+	}
+
+	for _, n := range nodes {
+		if state[n.ID] == unvisited {
+			if cycle := visit(n.ID); cycle != nil {
+				return cycle
+			}
+		}
+	}
+	return nil
+}
+
+// FindUnreachableChallenges simulates a team solving every challenge as soon as it
+// unlocks and returns the IDs (sorted) of challenges that can never be unlocked,
+// e.g. because of a cycle or a category rule that asks for more solves than exist.
+func FindUnreachableChallenges(nodes []PrerequisiteNode) []string {
+	solved := make(map[string]string, len(nodes))
+	for progress := true; progress; {
+		progress = false
+		for _, n := range nodes {
+			if _, ok := solved[n.ID]; ok {
+				continue
+			}
+			if PrerequisitesMet(n.Prerequisites, solved) {
+				solved[n.ID] = n.Category
+				progress = true
+			}
+		}
+	}
+
+	var unreachable []string
+	for _, n := range nodes {
+		if _, ok := solved[n.ID]; !ok {
+			unreachable = append(unreachable, n.ID)
+		}
+	}
+	sort.Strings(unreachable)
+	return unreachable
+}
+
+// FindUnreachableInContests runs FindUnreachableChallenges over each contest's own
+// challenges, since a team unlocks challenges only with solves made in the same contest.
+// contests maps a contest ID to the challenges attached to its rounds; rules naming a
+// challenge outside the contest can never be met there. Contests where everything can be
+// unlocked are left out.
+func FindUnreachableInContests(nodes []PrerequisiteNode, contests map[string][]string) map[string][]string {
+	byID := make(map[string]PrerequisiteNode, len(nodes))
+	for _, n := range nodes {
+		byID[n.ID] = n
+	}
+	result := make(map[string][]string)
+	for contestID, ids := range contests {
+		scoped := make([]PrerequisiteNode, 0, len(ids))
+		for _, id := range ids {
+			if n, ok := byID[id]; ok {
+				scoped = append(scoped, n)
+			}
+		}
+		if unreachable := FindUnreachableChallenges(scoped); len(unreachable) > 0 {
+			result[contestID] = unreachable
+		}
+	}
+	return result
+}
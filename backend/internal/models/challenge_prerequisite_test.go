@@ -0,0 +1,131 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPrerequisitesMet(t *testing.T) {
+	solved := map[string]string{
+		"a": "web",
+		"b": "web",
+		"c": "crypto",
+	}
+
+	tests := []struct {
+		name    string
+		prereqs []ChallengePrerequisite
+		want    bool
+	}{
+		{"no rules", nil, true},
+		{"required challenge solved", []ChallengePrerequisite{{Type: PrerequisiteTypeChallenge, RequiredChallengeID: "a"}}, true},
+		{"required challenge unsolved", []ChallengePrerequisite{{Type: PrerequisiteTypeChallenge, RequiredChallengeID: "z"}}, false},
+		{"enough category solves", []ChallengePrerequisite{{Type: PrerequisiteTypeCategory, Category: "web", Count: 2}}, true},
+		{"too few category solves", []ChallengePrerequisite{{Type: PrerequisiteTypeCategory, Category: "crypto", Count: 2}}, false},
+		{
+			"all rules must hold",
+			[]ChallengePrerequisite{
+				{Type: PrerequisiteTypeChallenge, RequiredChallengeID: "c"},
+				{Type: PrerequisiteTypeCategory, Category: "pwn", Count: 1},
+			},
+			false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := PrerequisitesMet(tt.prereqs, solved); got != tt.want {
+				t.Errorf("PrerequisitesMet() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func requires(id string) ChallengePrerequisite {
+	return ChallengePrerequisite{Type: PrerequisiteTypeChallenge, RequiredChallengeID: id}
+}
+
+func TestFindPrerequisiteCycle(t *testing.T) {
+	tests := []struct {
+		name  string
+		nodes []PrerequisiteNode
+		want  []string
+	}{
+		{
+			name: "chain without cycle",
+			nodes: []PrerequisiteNode{
+				{ID: "a"},
+				{ID: "b", Prerequisites: []ChallengePrerequisite{requires("a")}},
+				{ID: "c", Prerequisites: []ChallengePrerequisite{requires("b"), requires("a")}},
+			},
+			want: nil,
+		},
+		{
+			name: "self reference",
+			nodes: []PrerequisiteNode{
+				{ID: "a", Prerequisites: []ChallengePrerequisite{requires("a")}},
+			},
+			want: []string{"a", "a"},
+		},
+		{
+			name: "three node cycle",
+			nodes: []PrerequisiteNode{
+				{ID: "a", Prerequisites: []ChallengePrerequisite{requires("b")}},
+				{ID: "b", Prerequisites: []ChallengePrerequisite{requires("c")}},
+				{ID: "c", Prerequisites: []ChallengePrerequisite{requires("a")}},
+			},
+			want: []string{"a", "b", "c", "a"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FindPrerequisiteCycle(tt.nodes); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FindPrerequisiteCycle() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindUnreachableChallenges(t *testing.T) {
+	nodes := []PrerequisiteNode{
+		{ID: "web1", Category: "web"},
+		{ID: "web2", Category: "web", Prerequisites: []ChallengePrerequisite{requires("web1")}},
+		{ID: "crypto1", Category: "crypto", Prerequisites: []ChallengePrerequisite{
+			{Type: PrerequisiteTypeCategory, Category: "web", Count: 2},
+		}},
+		// Mutual category deadlock: each needs a solve in the other's category
+		{ID: "pwn1", Category: "pwn", Prerequisites: []ChallengePrerequisite{
+			{Type: PrerequisiteTypeCategory, Category: "rev", Count: 1},
+		}},
+		{ID: "rev1", Category: "rev", Prerequisites: []ChallengePrerequisite{
+			{Type: PrerequisiteTypeCategory, Category: "pwn", Count: 1},
+		}},
+		{ID: "final", Category: "misc", Prerequisites: []ChallengePrerequisite{requires("crypto1"), requires("rev1")}},
+	}
+
+	want := []string{"final", "pwn1", "rev1"}
+	if got := FindUnreachableChallenges(nodes); !reflect.DeepEqual(got, want) {
+		t.Errorf("FindUnreachableChallenges() = %v, want %v", got, want)
+	}
+}
+
+func TestFindUnreachableInContests(t *testing.T) {
+	nodes := []PrerequisiteNode{
+		{ID: "web1", Category: "web"},
+		{ID: "web2", Category: "web", Prerequisites: []ChallengePrerequisite{requires("web1")}},
+		{ID: "pwn1", Category: "pwn", Prerequisites: []ChallengePrerequisite{
+			{Type: PrerequisiteTypeCategory, Category: "web", Count: 1},
+		}},
+	}
+	contests := map[string][]string{
+		"full":    {"web1", "web2", "pwn1"},
+		"partial": {"web2", "pwn1"}, // web1 is solved in another contest only
+		"empty":   nil,
+	}
+
+	want := map[string][]string{"partial": {"pwn1", "web2"}}
+	if got := FindUnreachableInContests(nodes, contests); !reflect.DeepEqual(got, want) {
+		t.Errorf("FindUnreachableInContests() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,167 @@
+package models
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+)
+
+// Sort keys and limits for the challenge list
+const (
+	ChallengeSortDefault = "" // category, then title
+	ChallengeSortTitle   = "title"
+	ChallengeSortPoints  = "points"
+	ChallengeSortSolves  = "solves"
+
+	ChallengeListMaxLimit = 100
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// ChallengeListQuery filters, sorts and pages the player-facing challenge list.
+// Zero values mean "no filter"; Limit 0 returns everything after the cursor.
+type ChallengeListQuery struct {
+	Category   string
+	Difficulty string
+	Tags       []string // a challenge must carry all of them
+	Solved     *bool    // solved or unsolved by the caller's team
+	Search     string   // every word must appear in the title or description
+	Sort       string
+	Desc       bool
+	Limit      int
+	Cursor     string
+}
+
+// ChallengeListKey is the part of a listed challenge that sorting and cursors look at
+type ChallengeListKey struct {
+	ID       string `json:"id"`
+	Category string `json:"c,omitempty"`
+	Title    string `json:"t,omitempty"`
+	Points   int    `json:"p,omitempty"`
+	Solves   int    `json:"s,omitempty"`
+}
+
+// challengeCursor is the last key of a page, tied to the ordering it was produced with
+type challengeCursor struct {
+	Sort string `json:"sort,omitempty"`
+	Desc bool   `json:"desc,omitempty"`
+	ChallengeListKey
+}
+
+// Validate normalizes the query and rejects unknown sort keys and bad limits
+func (q *ChallengeListQuery) Validate() error {
+	q.Sort = strings.ToLower(strings.TrimSpace(q.Sort))
+	switch q.Sort {
+	case ChallengeSortDefault, ChallengeSortTitle, ChallengeSortPoints, ChallengeSortSolves:
+	default:
+		return errors.New("sort must be one of title, points or solves")
+	}
+	if q.Limit < 0 || q.Limit > ChallengeListMaxLimit {
+		return errors.New("limit must be at most 100")
+	}
+	if q.Cursor != "" {
+		if _, err := q.decodeCursor(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Matches reports whether a challenge passes the filters; solved is whether the caller's team solved it
+func (q ChallengeListQuery) Matches(ch *Challenge, solved bool) bool {
+	if q.Category != "" && !strings.EqualFold(ch.Category, q.Category) {
+		return false
+	}
+	if q.Difficulty != "" && !strings.EqualFold(ch.Difficulty, q.Difficulty) {
+		return false
+	}
+	if q.Solved != nil && *q.Solved != solved {
+		return false
+	}
+	for _, want := range q.Tags {
+		found := false
+		for _, tag := range ch.Tags {
+			if strings.EqualFold(tag, want) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if q.Search != "" {
+		text := strings.ToLower(ch.Title + "\n" + ch.Description)
+		for _, word := range strings.Fields(strings.ToLower(q.Search)) {
+			if !strings.Contains(text, word) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// Less orders two keys by the query's sort, breaking ties by ID so pages are stable
+func (q ChallengeListQuery) Less(a, b ChallengeListKey) bool {
+	if c := q.compare(a, b); c != 0 {
+		return (c < 0) != q.Desc
+	}
+	return a.ID < b.ID
+}
+
+func (q ChallengeListQuery) compare(a, b ChallengeListKey) int {
+	switch q.Sort {
+	case ChallengeSortPoints:
+		return a.Points - b.Points
+	case ChallengeSortSolves:
+		return a.Solves - b.Solves
+	case ChallengeSortTitle:
+		return strings.Compare(strings.ToLower(a.Title), strings.ToLower(b.Title))
+	default:
+		if c := strings.Compare(strings.ToLower(a.Category), strings.ToLower(b.Category)); c != 0 {
+			return c
+		}
+		return strings.Compare(strings.ToLower(a.Title), strings.ToLower(b.Title))
+	}
+}
+
+// Page returns the bounds of the page within keys, which must already be sorted with Less,
+// and the cursor for the next page ("" on the last page)
+func (q ChallengeListQuery) Page(keys []ChallengeListKey) (start, end int, next string, err error) {
+	if q.Cursor != "" {
+		last, err := q.decodeCursor()
+		if err != nil {
+			return 0, 0, "", err
+		}
+		for start < len(keys) && !q.Less(last, keys[start]) {
+			start++
+		}
+	}
+	end = len(keys)
+	if q.Limit > 0 && start+q.Limit < end {
+		end = start + q.Limit
+		next = q.encodeCursor(keys[end-1])
+	}
+	return start, end, next, nil
+}
+
+func (q ChallengeListQuery) encodeCursor(key ChallengeListKey) string {
+	raw, _ := json.Marshal(challengeCursor{Sort: q.Sort, Desc: q.Desc, ChallengeListKey: key})
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func (q ChallengeListQuery) decodeCursor() (ChallengeListKey, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(q.Cursor)
+	if err != nil {
+		return ChallengeListKey{}, ErrInvalidCursor
+	}
+	var cur challengeCursor
+	if err := json.Unmarshal(raw, &cur); err != nil || cur.ID == "" {
+		return ChallengeListKey{}, ErrInvalidCursor
+	}
+	if cur.Sort != q.Sort || cur.Desc != q.Desc {
+		return ChallengeListKey{}, errors.New("cursor was issued for a different sort order")
+	}
+	return cur.ChallengeListKey, nil
+}
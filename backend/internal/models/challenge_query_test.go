@@ -0,0 +1,97 @@
+package models
+
+import (
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestChallengeListQueryMatches(t *testing.T) {
+	ch := &Challenge{
+		Title:       "Baby ROP",
+		Description: "Return oriented programming for beginners",
+		Category:    "Pwn",
+		Difficulty:  "easy",
+		Tags:        []string{"rop", "x86"},
+	}
+	yes, no := true, false
+
+	tests := []struct {
+		name   string
+		query  ChallengeListQuery
+		solved bool
+		want   bool
+	}{
+		{"no filters", ChallengeListQuery{}, false, true},
+		{"category case-insensitive", ChallengeListQuery{Category: "pwn"}, false, true},
+		{"other category", ChallengeListQuery{Category: "web"}, false, false},
+		{"difficulty", ChallengeListQuery{Difficulty: "hard"}, false, false},
+		{"all tags present", ChallengeListQuery{Tags: []string{"ROP", "x86"}}, false, true},
+		{"missing tag", ChallengeListQuery{Tags: []string{"rop", "arm"}}, false, false},
+		{"solved wanted", ChallengeListQuery{Solved: &yes}, true, true},
+		{"unsolved wanted", ChallengeListQuery{Solved: &no}, true, false},
+		{"search title and description", ChallengeListQuery{Search: "rop beginners"}, false, true},
+		{"search word missing", ChallengeListQuery{Search: "rop heap"}, false, false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.query.Matches(ch, tt.solved); got != tt.want {
+			t.Errorf("%s: Matches = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestChallengeListQueryPaging(t *testing.T) {
+	keys := []ChallengeListKey{
+		{ID: "a", Points: 100},
+		{ID: "b", Points: 300},
+		{ID: "c", Points: 200},
+		{ID: "d", Points: 200},
+		{ID: "e", Points: 50},
+	}
+	q := ChallengeListQuery{Sort: ChallengeSortPoints, Desc: true, Limit: 2}
+	if err := q.Validate(); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	sort.Slice(keys, func(i, j int) bool { return q.Less(keys[i], keys[j]) })
+
+	var got []string
+	for page := 0; page < 5; page++ {
+		start, end, next, err := q.Page(keys)
+		if err != nil {
+			t.Fatalf("Page: %v", err)
+		}
+		for _, k := range keys[start:end] {
+			got = append(got, k.ID)
+		}
+		if next == "" {
+			break
+		}
+		q.Cursor = next
+	}
+
+	want := "b c d a e"
+	if joined := strings.Join(got, " "); joined != want {
+		t.Errorf("pages = %q, want %q", joined, want)
+	}
+}
+
+func TestChallengeListQueryValidate(t *testing.T) {
+	if err := (&ChallengeListQuery{Sort: "rating"}).Validate(); err == nil {
+		t.Error("unknown sort key accepted")
+	}
+	if err := (&ChallengeListQuery{Limit: ChallengeListMaxLimit + 1}).Validate(); err == nil {
+		t.Error("oversized limit accepted")
+	}
+	if err := (&ChallengeListQuery{Cursor: "not a cursor"}).Validate(); err == nil {
+		t.Error("garbage cursor accepted")
+	}
+
+	cursor := ChallengeListQuery{Sort: ChallengeSortSolves}.encodeCursor(ChallengeListKey{ID: "a"})
+	if err := (&ChallengeListQuery{Sort: ChallengeSortPoints, Cursor: cursor}).Validate(); err == nil {
+		t.Error("cursor from another sort order accepted")
+	}
+	if err := (&ChallengeListQuery{Sort: "Solves", Cursor: cursor}).Validate(); err != nil {
+		t.Errorf("matching cursor rejected: %v", err)
+	}
+}
@@ -0,0 +1,113 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// Challenge workflow states. Only published challenges are visible to players.
+const (
+	ChallengeStatusDraft            = "draft"
+	ChallengeStatusInReview         = "in_review"
+	ChallengeStatusChangesRequested = "changes_requested"
+	ChallengeStatusApproved         = "approved"
+	ChallengeStatusPublished        = "published"
+)
+
+// Workflow actions that move a challenge between states
+const (
+	ReviewActionSubmit         = "submit"
+	ReviewActionApprove        = "approve"
+	ReviewActionRequestChanges = "request_changes"
+	ReviewActionPublish        = "publish"
+	ReviewActionUnpublish      = "unpublish"
+	ReviewActionReopen         = "reopen"
+)
+
+// ChallengeReview records one workflow transition of a challenge
+type ChallengeReview struct {
+	ID          string    `json:"id"`
+	ChallengeID string    `json:"challenge_id"`
+	Action      string    `json:"action"`
+	FromStatus  string    `json:"from_status"`
+	ToStatus    string    `json:"to_status"`
+	ActorID     string    `json:"actor_id"`
+	ActorName   string    `json:"actor_name,omitempty"`
+	Comment     string    `json:"comment,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+var ChallengeStatuses = []string{
+	ChallengeStatusDraft,
+	ChallengeStatusInReview,
+	ChallengeStatusChangesRequested,
+	ChallengeStatusApproved,
+	ChallengeStatusPublished,
+}
+
+// challengeTransitions maps action -> current status -> next status
+var challengeTransitions = map[string]map[string]string{
+	ReviewActionSubmit: {
+		ChallengeStatusDraft:            ChallengeStatusInReview,
+		ChallengeStatusChangesRequested: ChallengeStatusInReview,
+	},
+	ReviewActionApprove: {
+		ChallengeStatusInReview: ChallengeStatusApproved,
+	},
+	ReviewActionRequestChanges: {
+		ChallengeStatusInReview: ChallengeStatusChangesRequested,
+		ChallengeStatusApproved: ChallengeStatusChangesRequested,
+	},
+	ReviewActionPublish: {
+		ChallengeStatusApproved: ChallengeStatusPublished,
+	},
+	ReviewActionUnpublish: {
+		ChallengeStatusPublished: ChallengeStatusApproved,
+	},
+	ReviewActionReopen: {
+		ChallengeStatusInReview:         ChallengeStatusDraft,
+		ChallengeStatusChangesRequested: ChallengeStatusDraft,
+		ChallengeStatusApproved:         ChallengeStatusDraft,
+	},
+}
+
+func IsValidChallengeStatus(status string) bool {
+	for _, s := range ChallengeStatuses {
+		if status == s {
+			return true
+		}
+	}
+	return false
+}
+
+// NextChallengeStatus returns the state a challenge moves to when action is applied in state from
+func NextChallengeStatus(from, action string) (string, error) {
+	targets, ok := challengeTransitions[action]
+	if !ok {
+		return "", fmt.Errorf("unknown workflow action '%s'", action)
+	}
+	to, ok := targets[from]
+	if !ok {
+		return "", fmt.Errorf("cannot %s a challenge in status '%s'", action, from)
+	}
+	return to, nil
+}
+
+// ActionRequiresSignOff reports whether the action is a review decision that the
+// challenge author may not make on their own work
+func ActionRequiresSignOff(action string) bool {
+	return action == ReviewActionApprove || action == ReviewActionRequestChanges
+}
+
+// AuthorMayApply reports whether a challenge author without admin rights may apply the action.
+// Authors move their own work in and out of review; review decisions and publishing stay with admins.
+func AuthorMayApply(action string) bool {
+	return action == ReviewActionSubmit || action == ReviewActionReopen
+}
+
+// AuthorMayEdit reports whether a challenge author without admin rights may change the content
+// of a challenge in the given status. Live content only changes with a new sign-off: an admin
+// unpublishes the challenge, and the author's edit then sends it back to draft.
+func AuthorMayEdit(status string) bool {
+	return status != ChallengeStatusPublished
+}
@@ -0,0 +1,64 @@
+package models
+
+import "testing"
+
+func TestNextChallengeStatus(t *testing.T) {
+	tests := []struct {
+		name    string
+		from    string
+		action  string
+		want    string
+		wantErr bool
+	}{
+		{"submit draft", ChallengeStatusDraft, ReviewActionSubmit, ChallengeStatusInReview, false},
+		{"resubmit after changes", ChallengeStatusChangesRequested, ReviewActionSubmit, ChallengeStatusInReview, false},
+		{"approve in review", ChallengeStatusInReview, ReviewActionApprove, ChallengeStatusApproved, false},
+		{"request changes in review", ChallengeStatusInReview, ReviewActionRequestChanges, ChallengeStatusChangesRequested, false},
+		{"request changes after approval", ChallengeStatusApproved, ReviewActionRequestChanges, ChallengeStatusChangesRequested, false},
+		{"publish approved", ChallengeStatusApproved, ReviewActionPublish, ChallengeStatusPublished, false},
+		{"unpublish keeps approval", ChallengeStatusPublished, ReviewActionUnpublish, ChallengeStatusApproved, false},
+		{"reopen approved", ChallengeStatusApproved, ReviewActionReopen, ChallengeStatusDraft, false},
+		{"cannot publish draft", ChallengeStatusDraft, ReviewActionPublish, "", true},
+		{"cannot publish in review", ChallengeStatusInReview, ReviewActionPublish, "", true},
+		{"cannot approve draft", ChallengeStatusDraft, ReviewActionApprove, "", true},
+		{"cannot reopen published", ChallengeStatusPublished, ReviewActionReopen, "", true},
+		{"unknown action", ChallengeStatusDraft, "delete", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NextChallengeStatus(tt.from, tt.action)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NextChallengeStatus(%q, %q) error = %v, wantErr %v", tt.from, tt.action, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("NextChallengeStatus(%q, %q) = %q, want %q", tt.from, tt.action, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAuthorMayApply(t *testing.T) {
+	allowed := map[string]bool{
+		ReviewActionSubmit:         true,
+		ReviewActionReopen:         true,
+		ReviewActionApprove:        false,
+		ReviewActionRequestChanges: false,
+		ReviewActionPublish:        false,
+		ReviewActionUnpublish:      false,
+	}
+	for action, want := range allowed {
+		if got := AuthorMayApply(action); got != want {
+			t.Errorf("AuthorMayApply(%q) = %v, want %v", action, got, want)
+		}
+	}
+}
+
+func TestAuthorMayEdit(t *testing.T) {
+	for _, status := range ChallengeStatuses {
+		want := status != ChallengeStatusPublished
+		if got := AuthorMayEdit(status); got != want {
+			t.Errorf("AuthorMayEdit(%q) = %v, want %v", status, got, want)
+		}
+	}
+}
@@ -0,0 +1,218 @@
+package models
+
+import (
+	"reflect"
+	"time"
+)
+
+// ChallengeRevision is an immutable snapshot of a challenge's editable state,
+// stored every time the challenge is created, updated or rolled back.
+type ChallengeRevision struct {
+	ID          string            `json:"id"`
+	ChallengeID string            `json:"challenge_id"`
+	Revision    int               `json:"revision"`
+	AuthorID    string            `json:"author_id,omitempty"`
+	AuthorName  string            `json:"author_name,omitempty"`
+	Note        string            `json:"note,omitempty"`
+	Snapshot    ChallengeSnapshot `json:"snapshot"`
+	CreatedAt   time.Time         `json:"created_at"`
+}
+
+// SnapshotFlag is a flag entry as stored in a revision. Value holds the same
+// hash, pattern or base as ChallengeFlag.Value and is only shown to admins.
+type SnapshotFlag struct {
+	Type  string `json:"type"`
+	Value string `json:"value,omitempty"`
+}
+
+// ChallengeSnapshot holds the fields of a challenge that admins can edit. Publication is
+// left out: it follows the review workflow, not edits or rollbacks.
+type ChallengeSnapshot struct {
+	Title             string                  `json:"title"`
+	Description       string                  `json:"description"`
+	DescriptionFormat string                  `json:"description_format"`
+	Category          string                  `json:"category"`
+	Difficulty        string                  `json:"difficulty"`
+	MaxPoints         int                     `json:"max_points"`
+	MinPoints         int                     `json:"min_points"`
+	Decay             int                     `json:"decay"`
+	ScoringType       string                  `json:"scoring_type"`
+	FlagHash          string                  `json:"flag_hash,omitempty"`
+	Flags             []SnapshotFlag          `json:"flags"`
+	Files             []string                `json:"files"`
+	Tags              []string                `json:"tags"`
+	Hints             []Hint                  `json:"hints"`
+	ScheduledAt       string                  `json:"scheduled_at,omitempty"`
+	Prerequisites     []ChallengePrerequisite `json:"prerequisites"`
+	FirstBloodBonus   []int                   `json:"first_blood_bonus,omitempty"`
+	MaxAttempts       int                     `json:"max_attempts,omitempty"`
+	GradingMode       string                  `json:"grading_mode,omitempty"`
+	FlagFormat        string                  `json:"flag_format,omitempty"`
+}
+
+// NewChallengeSnapshot captures the editable state of a challenge
+func NewChallengeSnapshot(c *Challenge) ChallengeSnapshot {
+	flags := make([]SnapshotFlag, 0, len(c.Flags))
+	for _, f := range c.Flags {
+		flags = append(flags, SnapshotFlag{Type: f.Type, Value: f.Value})
+	}
+	return ChallengeSnapshot{
+		Title:             c.Title,
+		Description:       c.Description,
+		DescriptionFormat: c.DescriptionFormat,
+		Category:          c.Category,
+		Difficulty:        c.Difficulty,
+		MaxPoints:         c.MaxPoints,
+		MinPoints:         c.MinPoints,
+		Decay:             c.Decay,
+		ScoringType:       c.ScoringType,
+		FlagHash:          c.FlagHash,
+		Flags:             flags,
+		Files:             c.Files,
+		Tags:              c.Tags,
+		Hints:             c.Hints,
+		ScheduledAt:       c.ScheduledAt,
+		Prerequisites:     c.Prerequisites,
+		FirstBloodBonus:   c.FirstBloodBonus,
+		MaxAttempts:       c.MaxAttempts,
+		GradingMode:       c.GradingMode,
+		FlagFormat:        c.FlagFormat,
+	}
+}
+
+// ApplyTo overwrites the editable fields of c with the snapshot. Solve counts,
+// contest assignment, publication and writeups are left untouched.
+func (s ChallengeSnapshot) ApplyTo(c *Challenge) {
+	flags := make([]ChallengeFlag, 0, len(s.Flags))
+	for _, f := range s.Flags {
+		flags = append(flags, ChallengeFlag{Type: f.Type, Value: f.Value})
+	}
+	prereqs := make([]ChallengePrerequisite, 0, len(s.Prerequisites))
+	for _, p := range s.Prerequisites {
+		p.ID = ""
+		prereqs = append(prereqs, p)
+	}
+
+	c.Title = s.Title
+	c.Description = s.Description
+	c.DescriptionFormat = s.DescriptionFormat
+	c.Category = s.Category
+	c.Difficulty = s.Difficulty
+	c.MaxPoints = s.MaxPoints
+	c.MinPoints = s.MinPoints
+	c.Decay = s.Decay
+	c.ScoringType = s.ScoringType
+	c.FlagHash = s.FlagHash
+	c.Flags = flags
+	c.Files = s.Files
+	c.Tags = s.Tags
+	c.Hints = s.Hints
+	c.ScheduledAt = s.ScheduledAt
+	c.Prerequisites = prereqs
+	c.FirstBloodBonus = s.FirstBloodBonus
+	c.MaxAttempts = s.MaxAttempts
+	c.GradingMode = s.GradingMode
+	if c.GradingMode == "" {
+		c.GradingMode = GradingAuto // snapshots taken before grading modes existed
+	}
+	c.FlagFormat = s.FlagFormat
+}
+
+// Redacted returns a copy without flag hashes, patterns or bases
+func (s ChallengeSnapshot) Redacted() ChallengeSnapshot {
+	s.FlagHash = ""
+	flags := make([]SnapshotFlag, 0, len(s.Flags))
+	for _, f := range s.Flags {
+		flags = append(flags, SnapshotFlag{Type: f.Type})
+	}
+	s.Flags = flags
+	return s
+}
+
+// ChallengeFieldChange is one field that differs between two snapshots
+type ChallengeFieldChange struct {
+	Field string      `json:"field"`
+	From  interface{} `json:"from"`
+	To    interface{} `json:"to"`
+}
+
+// DiffChallengeSnapshots lists the fields that changed between two snapshots
+func DiffChallengeSnapshots(from, to ChallengeSnapshot) []ChallengeFieldChange {
+	fields := []struct {
+		name     string
+		from, to interface{}
+	}{
+		{"title", from.Title, to.Title},
+		{"description", from.Description, to.Description},
+		{"description_format", from.DescriptionFormat, to.DescriptionFormat},
+		{"category", from.Category, to.Category},
+		{"difficulty", from.Difficulty, to.Difficulty},
+		{"max_points", from.MaxPoints, to.MaxPoints},
+		{"min_points", from.MinPoints, to.MinPoints},
+		{"decay", from.Decay, to.Decay},
+		{"scoring_type", from.ScoringType, to.ScoringType},
+		{"flag_hash", from.FlagHash, to.FlagHash},
+		{"flags", from.Flags, to.Flags},
+		{"files", from.Files, to.Files},
+		{"tags", from.Tags, to.Tags},
+		{"hints", hintContents(from.Hints), hintContents(to.Hints)},
+		{"scheduled_at", from.ScheduledAt, to.ScheduledAt},
+		{"prerequisites", prerequisiteRules(from.Prerequisites), prerequisiteRules(to.Prerequisites)},
+		{"first_blood_bonus", from.FirstBloodBonus, to.FirstBloodBonus},
+		{"max_attempts", from.MaxAttempts, to.MaxAttempts},
+		{"grading_mode", from.GradingMode, to.GradingMode},
+		{"flag_format", from.FlagFormat, to.FlagFormat},
+	}
+
+	changes := []ChallengeFieldChange{}
+	for _, f := range fields {
+		if !reflect.DeepEqual(emptyAsNil(f.from), emptyAsNil(f.to)) {
+			changes = append(changes, ChallengeFieldChange{Field: f.name, From: f.from, To: f.to})
+		}
+	}
+	return changes
+}
+
+// RedactFlagChanges hides flag values in a diff, keeping only the fact that flags changed
+// and the flag types involved
+func RedactFlagChanges(changes []ChallengeFieldChange) []ChallengeFieldChange {
+	redacted := make([]ChallengeFieldChange, 0, len(changes))
+	for _, c := range changes {
+		switch c.Field {
+		case "flag_hash":
+			c.From, c.To = "[hidden]", "[hidden]"
+		case "flags":
+			c.From = ChallengeSnapshot{Flags: c.From.([]SnapshotFlag)}.Redacted().Flags
+			c.To = ChallengeSnapshot{Flags: c.To.([]SnapshotFlag)}.Redacted().Flags
+		}
+		redacted = append(redacted, c)
+	}
+	return redacted
+}
+
+// hintContents strips hint IDs so re-created hints with the same content compare equal
+func hintContents(hints []Hint) []Hint {
+	result := make([]Hint, 0, len(hints))
+	for _, h := range hints {
+		result = append(result, Hint{Content: h.Content, Cost: h.Cost, Order: h.Order})
+	}
+	return result
+}
+
+// prerequisiteRules strips row IDs so re-created rules compare equal
+func prerequisiteRules(prereqs []ChallengePrerequisite) []ChallengePrerequisite {
+	result := make([]ChallengePrerequisite, 0, len(prereqs))
+	for _, p := range prereqs {
+		result = append(result, ChallengePrerequisite{Type: p.Type, RequiredChallengeID: p.RequiredChallengeID, Category: p.Category, Count: p.Count})
+	}
+	return result
+}
+
+// emptyAsNil treats nil and empty slices as equal when diffing
+func emptyAsNil(v interface{}) interface{} {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Slice && rv.Len() == 0 {
+		return nil
+	}
+	return v
+}
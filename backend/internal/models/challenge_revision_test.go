@@ -0,0 +1,86 @@
+package models
+
+import "testing"
+
+func TestDiffChallengeSnapshots(t *testing.T) {
+	base := Challenge{
+		Title:       "Baby RSA",
+		Description: "Factor me",
+		MaxPoints:   500,
+		FlagHash:    "hash-1",
+		Flags:       []ChallengeFlag{{ID: "f1", Type: FlagTypeStatic, Value: "hash-1"}},
+		Hints:       []Hint{{ID: "h1", Content: "small e", Cost: 50, Order: 1}},
+	}
+	from := NewChallengeSnapshot(&base)
+
+	t.Run("identical snapshots", func(t *testing.T) {
+		// Re-created hints get new IDs and nil/empty slices are equivalent
+		same := base
+		same.Hints = []Hint{{ID: "h2", Content: "small e", Cost: 50, Order: 1}}
+		same.Tags = []string{}
+		if changes := DiffChallengeSnapshots(from, NewChallengeSnapshot(&same)); len(changes) != 0 {
+			t.Errorf("DiffChallengeSnapshots() = %+v, want no changes", changes)
+		}
+	})
+
+	t.Run("changed fields are listed", func(t *testing.T) {
+		edited := base
+		edited.Description = "Factor me, please"
+		edited.MaxPoints = 400
+		edited.FlagHash = "hash-2"
+		edited.Flags = []ChallengeFlag{{Type: FlagTypeStatic, Value: "hash-2"}}
+
+		changes := DiffChallengeSnapshots(from, NewChallengeSnapshot(&edited))
+		got := make(map[string]bool)
+		for _, c := range changes {
+			got[c.Field] = true
+		}
+		for _, field := range []string{"description", "max_points", "flag_hash", "flags"} {
+			if !got[field] {
+				t.Errorf("expected %q in changes %+v", field, changes)
+			}
+		}
+		if len(changes) != 4 {
+			t.Errorf("got %d changes, want 4: %+v", len(changes), changes)
+		}
+
+		for _, c := range RedactFlagChanges(changes) {
+			switch c.Field {
+			case "flag_hash":
+				if c.From != "[hidden]" || c.To != "[hidden]" {
+					t.Errorf("flag_hash change not redacted: %+v", c)
+				}
+			case "flags":
+				for _, f := range c.To.([]SnapshotFlag) {
+					if f.Value != "" {
+						t.Errorf("flag value not redacted: %+v", c)
+					}
+				}
+			}
+		}
+	})
+}
+
+func TestChallengeSnapshotApplyTo(t *testing.T) {
+	original := Challenge{
+		Title:      "Old",
+		MaxPoints:  100,
+		SolveCount: 7,
+		ContestID:  "contest-1",
+		Flags:      []ChallengeFlag{{ID: "f1", Type: FlagTypeRegex, Value: "flag\\{.*\\}"}},
+	}
+	snapshot := NewChallengeSnapshot(&original)
+
+	current := Challenge{Title: "New", MaxPoints: 300, SolveCount: 12, ContestID: "contest-1"}
+	snapshot.ApplyTo(&current)
+
+	if current.Title != "Old" || current.MaxPoints != 100 {
+		t.Errorf("ApplyTo() did not restore editable fields: %+v", current)
+	}
+	if current.SolveCount != 12 {
+		t.Errorf("ApplyTo() changed SolveCount to %d, want 12", current.SolveCount)
+	}
+	if len(current.Flags) != 1 || current.Flags[0].ID != "" || current.Flags[0].Value != "flag\\{.*\\}" {
+		t.Errorf("ApplyTo() flags = %+v, want restored regex flag without ID", current.Flags)
+	}
+}
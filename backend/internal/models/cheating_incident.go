@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+const (
+	CheatingIncidentFlagSharing = "flag_sharing"
+)
+
+const (
+	CheatingIncidentStatusOpen      = "open"
+	CheatingIncidentStatusConfirmed = "confirmed"
+	CheatingIncidentStatusDismissed = "dismissed"
+)
+
+// CheatingIncident records suspicious activity for admins to review,
+// e.g. a team submitting the dynamic flag generated for another team.
+// A flag issued to a user without a team names that user as SourceUserID.
+type CheatingIncident struct {
+	ID           string    `json:"id"`
+	Type         string    `json:"type"`
+	ChallengeID  string    `json:"challenge_id"`
+	ContestID    string    `json:"contest_id,omitempty"`
+	UserID       string    `json:"user_id"`
+	TeamID       string    `json:"team_id,omitempty"`
+	SourceTeamID string    `json:"source_team_id,omitempty"`
+	SourceUserID string    `json:"source_user_id,omitempty"`
+	SubmissionID string    `json:"submission_id,omitempty"`
+	IPAddress    string    `json:"ip_address,omitempty"`
+	Details      string    `json:"details,omitempty"`
+	Status       string    `json:"status"`
+	ReviewedBy   string    `json:"reviewed_by,omitempty"`
+	ReviewNote   string    `json:"review_note,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+	ReviewedAt   string    `json:"reviewed_at,omitempty"`
+}
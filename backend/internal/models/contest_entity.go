@@ -10,6 +10,11 @@ type Contest struct {
 	EndTime              time.Time `json:"end_time"`
 	FreezeTime           string    `json:"freeze_time,omitempty"`
 	ScoreboardVisibility string    `json:"scoreboard_visibility,omitempty"`
+	FlagFormat           string    `json:"flag_format,omitempty"`     // regex every flag submitted in the contest must match
+	ScoringType          string    `json:"scoring_type,omitempty"`    // overrides the scoring strategy of every challenge in the contest
+	RankingMode          string    `json:"ranking_mode,omitempty"`    // how the team scoreboard ranks teams, RankingPoints by default
+	PenaltyMinutes       int       `json:"penalty_minutes,omitempty"` // ICPC ranking: penalty per wrong submission before a solve
+	TieBreaker           string    `json:"tie_breaker,omitempty"`     // how scoreboards order equal scores, TieBreakAlphabetical by default
 	IsActive             bool      `json:"is_active"`
 	CreatedAt            time.Time `json:"created_at"`
 	UpdatedAt            time.Time `json:"updated_at"`
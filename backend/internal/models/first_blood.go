@@ -0,0 +1,69 @@
+package models
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// MaxFirstBloodPlaces limits how many solver places a bonus schedule can reward
+const MaxFirstBloodPlaces = 10
+
+// FirstBloodSolve is a team's rank among the solvers of a challenge
+type FirstBloodSolve struct {
+	Place        int       `json:"place"`
+	SubmissionID string    `json:"submission_id"`
+	UserID       string    `json:"user_id"`
+	SolvedAt     time.Time `json:"solved_at"`
+}
+
+// ValidateFirstBloodBonus checks a bonus schedule: one percentage per solver place,
+// e.g. [15, 10, 5] for +15%/+10%/+5% to the first three solving teams
+func ValidateFirstBloodBonus(schedule []int) error {
+	if len(schedule) > MaxFirstBloodPlaces {
+		return fmt.Errorf("first_blood_bonus may reward at most %d places", MaxFirstBloodPlaces)
+	}
+	for i, pct := range schedule {
+		if pct < 0 || pct > 100 {
+			return fmt.Errorf("first_blood_bonus place %d: percentage must be between 0 and 100", i+1)
+		}
+	}
+	return nil
+}
+
+// FirstBloodBonus returns the bonus points for a solver place (1-based) on a challenge worth points
+func FirstBloodBonus(points int, schedule []int, place int) int {
+	if place < 1 || place > len(schedule) {
+		return 0
+	}
+	return int(math.Round(float64(points) * float64(schedule[place-1]) / 100))
+}
+
+// FirstBloodPlaces ranks teams by their first correct submission to each challenge.
+// Returns challengeID -> teamID -> solve. Submissions without a team are ignored.
+func FirstBloodPlaces(submissions []Submission) map[string]map[string]FirstBloodSolve {
+	sorted := make([]Submission, 0, len(submissions))
+	for _, sub := range submissions {
+		if sub.IsCorrect && sub.TeamID != "" {
+			sorted = append(sorted, sub)
+		}
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Timestamp.Before(sorted[j].Timestamp)
+	})
+
+	places := make(map[string]map[string]FirstBloodSolve)
+	for _, sub := range sorted {
+		teams := places[sub.ChallengeID]
+		if teams == nil {
+			teams = make(map[string]FirstBloodSolve)
+			places[sub.ChallengeID] = teams
+		}
+		if _, solved := teams[sub.TeamID]; solved {
+			continue
+		}
+		teams[sub.TeamID] = FirstBloodSolve{Place: len(teams) + 1, SubmissionID: sub.ID, UserID: sub.UserID, SolvedAt: sub.Timestamp}
+	}
+	return places
+}
@@ -0,0 +1,84 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFirstBloodBonus(t *testing.T) {
+	schedule := []int{15, 10, 5}
+
+	tests := []struct {
+		name   string
+		points int
+		place  int
+		want   int
+	}{
+		{"first place", 500, 1, 75},
+		{"second place", 500, 2, 50},
+		{"third place", 500, 3, 25},
+		{"outside schedule", 500, 4, 0},
+		{"no place", 500, 0, 0},
+		{"rounds to nearest point", 333, 1, 50},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FirstBloodBonus(tt.points, schedule, tt.place); got != tt.want {
+				t.Errorf("FirstBloodBonus(%d, %v, %d) = %d, want %d", tt.points, schedule, tt.place, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateFirstBloodBonus(t *testing.T) {
+	if err := ValidateFirstBloodBonus([]int{15, 10, 5}); err != nil {
+		t.Errorf("valid schedule rejected: %v", err)
+	}
+	if err := ValidateFirstBloodBonus([]int{15, -1}); err == nil {
+		t.Error("negative percentage accepted")
+	}
+	if err := ValidateFirstBloodBonus([]int{150}); err == nil {
+		t.Error("percentage above 100 accepted")
+	}
+	if err := ValidateFirstBloodBonus(make([]int, MaxFirstBloodPlaces+1)); err == nil {
+		t.Error("too many places accepted")
+	}
+}
+
+func TestFirstBloodPlaces(t *testing.T) {
+	base := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	submissions := []Submission{
+		{ID: "s3", TeamID: "team-c", UserID: "u3", ChallengeID: "c1", IsCorrect: true, Timestamp: base.Add(3 * time.Minute)},
+		{ID: "s1", TeamID: "team-a", UserID: "u1", ChallengeID: "c1", IsCorrect: true, Timestamp: base.Add(1 * time.Minute)},
+		{ID: "s2", TeamID: "team-b", UserID: "u2", ChallengeID: "c1", IsCorrect: true, Timestamp: base.Add(2 * time.Minute)},
+		{ID: "s4", TeamID: "team-a", UserID: "u4", ChallengeID: "c1", IsCorrect: true, Timestamp: base.Add(4 * time.Minute)},
+		{ID: "s5", TeamID: "team-b", UserID: "u2", ChallengeID: "c2", IsCorrect: true, Timestamp: base},
+		{ID: "s6", TeamID: "team-c", UserID: "u3", ChallengeID: "c2", IsCorrect: false, Timestamp: base.Add(-time.Minute)},
+		{ID: "s7", UserID: "solo", ChallengeID: "c2", IsCorrect: true, Timestamp: base.Add(-2 * time.Minute)},
+	}
+
+	places := FirstBloodPlaces(submissions)
+
+	want := map[string]map[string]FirstBloodSolve{
+		"c1": {
+			"team-a": {Place: 1, SubmissionID: "s1", UserID: "u1", SolvedAt: base.Add(1 * time.Minute)},
+			"team-b": {Place: 2, SubmissionID: "s2", UserID: "u2", SolvedAt: base.Add(2 * time.Minute)},
+			"team-c": {Place: 3, SubmissionID: "s3", UserID: "u3", SolvedAt: base.Add(3 * time.Minute)},
+		},
+		"c2": {
+			"team-b": {Place: 1, SubmissionID: "s5", UserID: "u2", SolvedAt: base},
+		},
+	}
+
+	for challengeID, teams := range want {
+		if len(places[challengeID]) != len(teams) {
+			t.Errorf("challenge %s: got %d ranked teams, want %d", challengeID, len(places[challengeID]), len(teams))
+		}
+		for teamID, solve := range teams {
+			if got := places[challengeID][teamID]; got != solve {
+				t.Errorf("challenge %s team %s = %+v, want %+v", challengeID, teamID, got, solve)
+			}
+		}
+	}
+}
@@ -0,0 +1,27 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// ValidateFlagFormat checks a flag format regex. Formats are matched against the whole
+// submission, e.g. `RootAccess\{[^}]+\}`. An empty format accepts anything.
+func ValidateFlagFormat(pattern string) error {
+	if pattern == "" {
+		return nil
+	}
+	if _, err := regexp.Compile("^(?:" + pattern + ")$"); err != nil {
+		return fmt.Errorf("invalid flag_format: %v", err)
+	}
+	return nil
+}
+
+// EffectiveFlagFormat returns the format a submission must match: the challenge's
+// override if it has one, otherwise the contest's
+func EffectiveFlagFormat(contestFormat, challengeFormat string) string {
+	if challengeFormat != "" {
+		return challengeFormat
+	}
+	return contestFormat
+}
@@ -0,0 +1,39 @@
+package models
+
+import "testing"
+
+func TestValidateFlagFormat(t *testing.T) {
+	tests := []struct {
+		pattern string
+		wantErr bool
+	}{
+		{"", false},
+		{`RootAccess\{[^}]+\}`, false},
+		{`(flag|RootAccess)\{.*\}`, false},
+		{`RootAccess\{[^}+\}`, true},
+		{`(unclosed`, true},
+	}
+
+	for _, tt := range tests {
+		if err := ValidateFlagFormat(tt.pattern); (err != nil) != tt.wantErr {
+			t.Errorf("ValidateFlagFormat(%q) error = %v, wantErr %v", tt.pattern, err, tt.wantErr)
+		}
+	}
+}
+
+func TestEffectiveFlagFormat(t *testing.T) {
+	tests := []struct {
+		contest, challenge, want string
+	}{
+		{`RootAccess\{.+\}`, "", `RootAccess\{.+\}`},
+		{`RootAccess\{.+\}`, `[0-9a-f]{32}`, `[0-9a-f]{32}`},
+		{"", `[0-9a-f]{32}`, `[0-9a-f]{32}`},
+		{"", "", ""},
+	}
+
+	for _, tt := range tests {
+		if got := EffectiveFlagFormat(tt.contest, tt.challenge); got != tt.want {
+			t.Errorf("EffectiveFlagFormat(%q, %q) = %q, want %q", tt.contest, tt.challenge, got, tt.want)
+		}
+	}
+}
@@ -1,5 +1,7 @@
 package models
 
+import "time"
+
 type Hint struct {
 	ID          string `json:"id"`
 	ChallengeID string `json:"challenge_id"`
@@ -8,11 +10,16 @@ type Hint struct {
 	Order       int    `json:"order"`
 }
 
+// HintReveal charges a hint's cost to the user (and their team) who revealed it.
+// Reveals count against the contest that was active at the time; reveals made
+// before contests were tracked have no ContestID or CreatedAt.
 type HintReveal struct {
-	ID          string `json:"id"`
-	HintID      string `json:"hint_id"`
-	ChallengeID string `json:"challenge_id"`
-	UserID      string `json:"user_id"`
-	TeamID      string `json:"team_id,omitempty"`
-	Cost        int    `json:"cost"`
+	ID          string    `json:"id"`
+	HintID      string    `json:"hint_id"`
+	ChallengeID string    `json:"challenge_id"`
+	UserID      string    `json:"user_id"`
+	TeamID      string    `json:"team_id,omitempty"`
+	ContestID   string    `json:"contest_id,omitempty"`
+	Cost        int       `json:"cost"`
+	CreatedAt   time.Time `json:"created_at"`
 }
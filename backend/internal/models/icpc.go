@@ -0,0 +1,87 @@
+package models
+
+import (
+	"errors"
+	"sort"
+	"time"
+)
+
+// Contest ranking modes
+const (
+	RankingPoints = "points" // by points, the default
+	RankingICPC   = "icpc"   // by number of solves, then by time penalty
+)
+
+// DefaultPenaltyMinutes is the ICPC penalty for a wrong submission when a contest sets none
+const DefaultPenaltyMinutes = 20
+
+func IsValidRankingMode(mode string) bool {
+	return mode == "" || mode == RankingPoints || mode == RankingICPC
+}
+
+// ValidatePenaltyMinutes checks the per-wrong-submission penalty of an ICPC contest
+func ValidatePenaltyMinutes(minutes int) error {
+	if minutes < 0 || minutes > 24*60 {
+		return errors.New("penalty_minutes must be between 0 and 1440")
+	}
+	return nil
+}
+
+// ICPCScore is a team's standing under ICPC rules
+type ICPCScore struct {
+	Solved      int       `json:"solved"`
+	Penalty     int       `json:"penalty"` // minutes
+	LastSolveAt time.Time `json:"last_solve_at"`
+}
+
+// ICPCScores ranks teams ICPC-style from their contest submissions. Each solved challenge
+// adds the whole minutes from start to its first correct submission plus penaltyMinutes
+// for every wrong submission before it; wrong submissions on unsolved challenges cost
+// nothing. Submissions without a team are ignored. Returns teamID -> score.
+func ICPCScores(submissions []Submission, start time.Time, penaltyMinutes int) map[string]ICPCScore {
+	sorted := make([]Submission, 0, len(submissions))
+	for _, sub := range submissions {
+		if sub.TeamID != "" {
+			sorted = append(sorted, sub)
+		}
+	}
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Timestamp.Before(sorted[j].Timestamp) })
+
+	scores := make(map[string]ICPCScore)
+	wrong := make(map[string]int)
+	solved := make(map[string]bool)
+	for _, sub := range sorted {
+		key := sub.TeamID + "|" + sub.ChallengeID
+		if solved[key] {
+			continue
+		}
+		if !sub.IsCorrect {
+			wrong[key]++
+			continue
+		}
+		solved[key] = true
+
+		elapsed := int(sub.Timestamp.Sub(start) / time.Minute)
+		if elapsed < 0 {
+			elapsed = 0
+		}
+		score := scores[sub.TeamID]
+		score.Solved++
+		score.Penalty += elapsed + wrong[key]*penaltyMinutes
+		score.LastSolveAt = sub.Timestamp
+		scores[sub.TeamID] = score
+	}
+	return scores
+}
+
+// ICPCLess reports whether a ranks above b: more solves, then less penalty, then the
+// earlier last solve
+func ICPCLess(a, b ICPCScore) bool {
+	if a.Solved != b.Solved {
+		return a.Solved > b.Solved
+	}
+	if a.Penalty != b.Penalty {
+		return a.Penalty < b.Penalty
+	}
+	return a.LastSolveAt.Before(b.LastSolveAt)
+}
@@ -0,0 +1,69 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestICPCScores(t *testing.T) {
+	start := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	at := func(minutes, seconds int) time.Time {
+		return start.Add(time.Duration(minutes)*time.Minute + time.Duration(seconds)*time.Second)
+	}
+
+	scores := ICPCScores([]Submission{
+		// t1: A wrong twice then solved at 30:59, B solved at 45, C only wrong
+		{TeamID: "t1", ChallengeID: "A", Timestamp: at(5, 0)},
+		{TeamID: "t1", ChallengeID: "A", Timestamp: at(10, 0)},
+		{TeamID: "t1", ChallengeID: "A", IsCorrect: true, Timestamp: at(30, 59)},
+		{TeamID: "t1", ChallengeID: "B", IsCorrect: true, Timestamp: at(45, 0)},
+		{TeamID: "t1", ChallengeID: "C", Timestamp: at(50, 0)},
+		// t2: a wrong submission after the solve costs nothing
+		{TeamID: "t2", ChallengeID: "A", IsCorrect: true, Timestamp: at(20, 0)},
+		{TeamID: "t2", ChallengeID: "A", Timestamp: at(25, 0)},
+		// no team: ignored
+		{UserID: "u1", ChallengeID: "A", IsCorrect: true, Timestamp: at(1, 0)},
+	}, start, 20)
+
+	if got := scores["t1"]; got.Solved != 2 || got.Penalty != 30+2*20+45 || !got.LastSolveAt.Equal(at(45, 0)) {
+		t.Errorf("t1 = %+v, want 2 solves, 115 minutes", got)
+	}
+	if got := scores["t2"]; got.Solved != 1 || got.Penalty != 20 {
+		t.Errorf("t2 = %+v, want 1 solve, 20 minutes", got)
+	}
+	if len(scores) != 2 {
+		t.Errorf("got %d teams, want 2", len(scores))
+	}
+}
+
+func TestICPCScoresOrderIndependent(t *testing.T) {
+	start := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	scores := ICPCScores([]Submission{
+		{TeamID: "t1", ChallengeID: "A", IsCorrect: true, Timestamp: start.Add(40 * time.Minute)},
+		{TeamID: "t1", ChallengeID: "A", Timestamp: start.Add(10 * time.Minute)},
+	}, start, 20)
+	if got := scores["t1"].Penalty; got != 60 {
+		t.Errorf("Penalty = %d, want 60", got)
+	}
+}
+
+func TestICPCLess(t *testing.T) {
+	early := time.Date(2026, 3, 1, 11, 0, 0, 0, time.UTC)
+	late := early.Add(time.Hour)
+
+	tests := []struct {
+		a, b ICPCScore
+		want bool
+	}{
+		{ICPCScore{Solved: 3, Penalty: 500}, ICPCScore{Solved: 2, Penalty: 10}, true},
+		{ICPCScore{Solved: 2, Penalty: 100}, ICPCScore{Solved: 2, Penalty: 90}, false},
+		{ICPCScore{Solved: 2, Penalty: 90, LastSolveAt: early}, ICPCScore{Solved: 2, Penalty: 90, LastSolveAt: late}, true},
+		{ICPCScore{Solved: 2, Penalty: 90, LastSolveAt: late}, ICPCScore{Solved: 2, Penalty: 90, LastSolveAt: early}, false},
+	}
+
+	for i, tt := range tests {
+		if got := ICPCLess(tt.a, tt.b); got != tt.want {
+			t.Errorf("case %d: ICPCLess = %v, want %v", i, got, tt.want)
+		}
+	}
+}
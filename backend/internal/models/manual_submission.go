@@ -0,0 +1,116 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Grading modes. Auto challenges check flags on submission; answers to manual
+// challenges wait in the judging queue until an admin grades them.
+const (
+	GradingAuto   = "auto"
+	GradingManual = "manual"
+)
+
+// Manual submission states
+const (
+	JudgementPending  = "pending"
+	JudgementAccepted = "accepted"
+	JudgementRejected = "rejected"
+	JudgementPartial  = "partial"
+)
+
+// Verdicts an admin can give a pending answer
+const (
+	VerdictAccept  = "accept"
+	VerdictReject  = "reject"
+	VerdictPartial = "partial"
+)
+
+// MaxManualAnswerLength limits the size of an answer to a manually graded challenge
+const MaxManualAnswerLength = 20000
+
+// ManualSubmission is an answer to a manually graded challenge. Accepted answers are
+// recorded as correct submissions and scored like any other solve; partial credit is
+// added to the scoreboards separately.
+type ManualSubmission struct {
+	ID             string     `json:"id"`
+	ChallengeID    string     `json:"challenge_id"`
+	ChallengeTitle string     `json:"challenge_title,omitempty"`
+	UserID         string     `json:"user_id"`
+	Username       string     `json:"username,omitempty"`
+	TeamID         string     `json:"team_id,omitempty"`
+	TeamName       string     `json:"team_name,omitempty"`
+	ContestID      string     `json:"contest_id,omitempty"`
+	Answer         string     `json:"answer"`
+	Status         string     `json:"status"`
+	PointsAwarded  int        `json:"points_awarded"` // partial credit; accepted answers score as solves
+	Feedback       string     `json:"feedback,omitempty"`
+	JudgedBy       string     `json:"judged_by,omitempty"`
+	JudgedAt       *time.Time `json:"judged_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+func IsValidGradingMode(mode string) bool {
+	return mode == GradingAuto || mode == GradingManual
+}
+
+func IsValidJudgementStatus(status string) bool {
+	switch status {
+	case JudgementPending, JudgementAccepted, JudgementRejected, JudgementPartial:
+		return true
+	}
+	return false
+}
+
+// JudgementStatusForVerdict validates a verdict and returns the state it moves an answer to.
+// Partial credit must be more than 0 and less than the challenge's current points.
+func JudgementStatusForVerdict(verdict string, points, challengePoints int) (string, error) {
+	switch verdict {
+	case VerdictAccept:
+		return JudgementAccepted, nil
+	case VerdictReject:
+		return JudgementRejected, nil
+	case VerdictPartial:
+		if points <= 0 || points >= challengePoints {
+			return "", fmt.Errorf("partial points must be between 1 and %d", challengePoints-1)
+		}
+		return JudgementPartial, nil
+	}
+	return "", errors.New("verdict must be 'accept', 'reject' or 'partial'")
+}
+
+// BestPartialAwards keeps the highest partial award per team (or user, without a team) and
+// challenge, so resubmitting after partial credit never stacks awards. Ties keep the earliest.
+func BestPartialAwards(entries []ManualSubmission) []ManualSubmission {
+	sorted := make([]ManualSubmission, 0, len(entries))
+	for _, e := range entries {
+		if e.Status == JudgementPartial && e.PointsAwarded > 0 {
+			sorted = append(sorted, e)
+		}
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].CreatedAt.Before(sorted[j].CreatedAt)
+	})
+
+	best := make(map[string]int)
+	var result []ManualSubmission
+	for _, e := range sorted {
+		owner := e.TeamID
+		if owner == "" {
+			owner = e.UserID
+		}
+		key := e.ChallengeID + "|" + owner
+		if i, ok := best[key]; ok {
+			if e.PointsAwarded > result[i].PointsAwarded {
+				result[i] = e
+			}
+			continue
+		}
+		best[key] = len(result)
+		result = append(result, e)
+	}
+	return result
+}
@@ -0,0 +1,55 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestJudgementStatusForVerdict(t *testing.T) {
+	tests := []struct {
+		verdict string
+		points  int
+		want    string
+		wantErr bool
+	}{
+		{VerdictAccept, 0, JudgementAccepted, false},
+		{VerdictReject, 0, JudgementRejected, false},
+		{VerdictPartial, 50, JudgementPartial, false},
+		{VerdictPartial, 0, "", true},
+		{VerdictPartial, 100, "", true}, // full points is an accept
+		{"maybe", 0, "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := JudgementStatusForVerdict(tt.verdict, tt.points, 100)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("JudgementStatusForVerdict(%q, %d) error = %v, wantErr %v", tt.verdict, tt.points, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("JudgementStatusForVerdict(%q, %d) = %q, want %q", tt.verdict, tt.points, got, tt.want)
+		}
+	}
+}
+
+func TestBestPartialAwards(t *testing.T) {
+	base := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	entries := []ManualSubmission{
+		{ID: "a", ChallengeID: "c1", TeamID: "t1", Status: JudgementPartial, PointsAwarded: 30, CreatedAt: base},
+		{ID: "b", ChallengeID: "c1", TeamID: "t1", Status: JudgementPartial, PointsAwarded: 60, CreatedAt: base.Add(time.Hour)},
+		{ID: "c", ChallengeID: "c1", TeamID: "t1", Status: JudgementPartial, PointsAwarded: 40, CreatedAt: base.Add(2 * time.Hour)},
+		{ID: "d", ChallengeID: "c1", TeamID: "t2", Status: JudgementRejected, CreatedAt: base},
+		{ID: "e", ChallengeID: "c2", TeamID: "t2", Status: JudgementPartial, PointsAwarded: 10, CreatedAt: base},
+		{ID: "f", ChallengeID: "c2", UserID: "u1", Status: JudgementPartial, PointsAwarded: 20, CreatedAt: base},
+	}
+
+	got := BestPartialAwards(entries)
+	ids := make(map[string]bool)
+	for _, e := range got {
+		ids[e.ID] = true
+	}
+
+	if len(got) != 3 || !ids["b"] || !ids["e"] || !ids["f"] {
+		t.Errorf("BestPartialAwards kept %v, want b, e and f", ids)
+	}
+}
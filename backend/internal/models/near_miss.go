@@ -0,0 +1,296 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Near-miss kinds: how a wrong flag differs from one the challenge accepts
+const (
+	NearMissWhitespace = "whitespace" // surrounding, inner or space-for-underscore differences
+	NearMissCase       = "case"       // differs only in letter case
+	NearMissWrapper    = "wrapper"    // missing, extra or different prefix{...} wrapper
+	NearMissTypo       = "typo"       // a small edit distance away
+)
+
+const (
+	// MaxNearMissDistance is the largest edit distance reported as a typo against flags known
+	// in plain text (dynamic flags). Hashed flags can only be checked one edit away.
+	MaxNearMissDistance = 2
+	// MaxTypoScanLength bounds the flags whose one-edit variants are enumerated and hashed
+	MaxTypoScanLength = 100
+)
+
+// CommonFlagPrefixes are wrappers players often use out of habit
+var CommonFlagPrefixes = []string{DynamicFlagPrefix, "flag", "FLAG", "CTF"}
+
+// NearMiss records a wrong submission that was very close to an accepted flag.
+// The submitted text itself is not stored; Detail only says what differed.
+type NearMiss struct {
+	ID           string    `json:"id"`
+	ChallengeID  string    `json:"challenge_id"`
+	ContestID    string    `json:"contest_id,omitempty"`
+	SubmissionID string    `json:"submission_id"`
+	UserID       string    `json:"user_id"`
+	Username     string    `json:"username,omitempty"`
+	TeamID       string    `json:"team_id,omitempty"`
+	TeamName     string    `json:"team_name,omitempty"`
+	FlagID       string    `json:"flag_id,omitempty"` // accepted flag it was close to, "" for the legacy flag hash
+	Kind         string    `json:"kind"`
+	Distance     int       `json:"distance"`
+	Detail       string    `json:"detail"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// NearMissReport aggregates the near misses of one challenge
+type NearMissReport struct {
+	ChallengeID string         `json:"challenge_id"`
+	Title       string         `json:"title"`
+	Total       int            `json:"total"`
+	Owners      int            `json:"owners"` // distinct teams, plus users without a team
+	ByKind      map[string]int `json:"by_kind"`
+	ByFlag      map[string]int `json:"by_flag"`
+	ByDetail    map[string]int `json:"by_detail"`
+	NearMisses  []NearMiss     `json:"near_misses"`
+}
+
+// NearMissSummary is one row of the overview listing challenges by near misses
+type NearMissSummary struct {
+	ChallengeID string    `json:"challenge_id"`
+	Title       string    `json:"title"`
+	Category    string    `json:"category"`
+	Total       int       `json:"total"`
+	Owners      int       `json:"owners"`
+	LatestAt    time.Time `json:"latest_at"`
+}
+
+// SummarizeNearMisses builds the report for near misses of a single challenge
+func SummarizeNearMisses(challengeID, title string, nearMisses []NearMiss) NearMissReport {
+	report := NearMissReport{
+		ChallengeID: challengeID,
+		Title:       title,
+		Total:       len(nearMisses),
+		ByKind:      map[string]int{},
+		ByFlag:      map[string]int{},
+		ByDetail:    map[string]int{},
+		NearMisses:  nearMisses,
+	}
+	owners := map[string]bool{}
+	for _, nm := range nearMisses {
+		report.ByKind[nm.Kind]++
+		report.ByFlag[nm.FlagID]++
+		report.ByDetail[nm.Detail]++
+		owners[nearMissOwner(nm)] = true
+	}
+	report.Owners = len(owners)
+	return report
+}
+
+func nearMissOwner(nm NearMiss) string {
+	if nm.TeamID != "" {
+		return "team:" + nm.TeamID
+	}
+	return nm.UserID
+}
+
+// FlagVariant is a rewrite of a wrong flag that may be what the player meant to submit
+type FlagVariant struct {
+	Value  string
+	Kind   string
+	Detail string
+}
+
+// FlagFormatPrefix extracts the literal wrapper from a flag format such as `^RA\{.+\}$`,
+// or "" when the format does not start with one
+func FlagFormatPrefix(format string) string {
+	format = strings.TrimPrefix(format, "^")
+	end := 0
+	for end < len(format) && isPrefixChar(rune(format[end])) {
+		end++
+	}
+	rest := format[end:]
+	if end == 0 || !(strings.HasPrefix(rest, `\{`) || strings.HasPrefix(rest, "{")) {
+		return ""
+	}
+	return format[:end]
+}
+
+func isPrefixChar(r rune) bool {
+	return r == '_' || r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r))
+}
+
+// splitWrapper splits prefix{inner} into its parts
+func splitWrapper(flag string) (prefix, inner string, ok bool) {
+	open := strings.IndexByte(flag, '{')
+	if open < 0 || !strings.HasSuffix(flag, "}") || open == len(flag)-1 {
+		return "", "", false
+	}
+	for _, r := range flag[:open] {
+		if !isPrefixChar(r) {
+			return "", "", false
+		}
+	}
+	return flag[:open], flag[open+1 : len(flag)-1], true
+}
+
+// FlagVariants returns rewrites of a submitted flag that undo common near misses:
+// stray whitespace, wrong letter case and missing, extra or different wrappers.
+// prefixes are the wrappers worth trying, e.g. the contest's and CommonFlagPrefixes.
+func FlagVariants(flag string, prefixes []string) []FlagVariant {
+	var variants []FlagVariant
+	seen := map[string]bool{flag: true}
+	add := func(value, kind, detail string) {
+		if value == "" || seen[value] {
+			return
+		}
+		seen[value] = true
+		variants = append(variants, FlagVariant{Value: value, Kind: kind, Detail: detail})
+	}
+
+	trimmed := strings.TrimSpace(flag)
+	add(trimmed, NearMissWhitespace, "surrounding whitespace")
+	if strings.ContainsFunc(trimmed, unicode.IsSpace) {
+		add(strings.Join(strings.Fields(trimmed), ""), NearMissWhitespace, "whitespace inside the flag")
+		add(strings.Join(strings.Fields(trimmed), "_"), NearMissWhitespace, "spaces instead of underscores")
+	}
+
+	add(strings.ToLower(trimmed), NearMissCase, "letter case")
+	add(strings.ToUpper(trimmed), NearMissCase, "letter case")
+	add(swapCase(trimmed), NearMissCase, "letter case")
+
+	if prefix, inner, ok := splitWrapper(trimmed); ok {
+		// Re-case the contents inside the player's wrapper and inside known wrappers
+		// that only differ from it in case
+		wrappers := []string{prefix}
+		for _, p := range prefixes {
+			if p != prefix && strings.EqualFold(p, prefix) {
+				wrappers = append(wrappers, p)
+			}
+		}
+		for _, w := range wrappers {
+			for _, in := range []string{inner, strings.ToLower(inner), strings.ToUpper(inner), swapCase(inner)} {
+				add(w+"{"+in+"}", NearMissCase, "letter case")
+			}
+		}
+		add(inner, NearMissWrapper, "unexpected "+wrapperName(prefix)+" wrapper")
+		for _, p := range prefixes {
+			if !strings.EqualFold(p, prefix) {
+				add(p+"{"+inner+"}", NearMissWrapper, wrapperName(prefix)+" wrapper instead of "+wrapperName(p))
+			}
+		}
+	} else {
+		for _, p := range prefixes {
+			add(p+"{"+trimmed+"}", NearMissWrapper, "missing "+wrapperName(p)+" wrapper")
+		}
+	}
+	return variants
+}
+
+func wrapperName(prefix string) string {
+	return prefix + "{...}"
+}
+
+func swapCase(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsUpper(r) {
+			return unicode.ToLower(r)
+		}
+		return unicode.ToUpper(r)
+	}, s)
+}
+
+// EachTypo calls try with every string one edit away from flag: a deleted character, two
+// swapped neighbours, or a printable ASCII character changed or inserted. It stops at the
+// first candidate try accepts and describes that edit. Flags longer than MaxTypoScanLength
+// are not scanned.
+func EachTypo(flag string, try func(candidate string) bool) (detail string, found bool) {
+	n := len(flag)
+	if n == 0 || n > MaxTypoScanLength {
+		return "", false
+	}
+	buf := make([]byte, 0, n+1)
+
+	for i := 0; i < n; i++ {
+		buf = append(append(buf[:0], flag[:i]...), flag[i+1:]...)
+		if try(string(buf)) {
+			return fmt.Sprintf("extra character at position %d", i+1), true
+		}
+	}
+	for i := 0; i+1 < n; i++ {
+		if flag[i] == flag[i+1] {
+			continue
+		}
+		buf = append(buf[:0], flag...)
+		buf[i], buf[i+1] = buf[i+1], buf[i]
+		if try(string(buf)) {
+			return fmt.Sprintf("characters %d and %d swapped", i+1, i+2), true
+		}
+	}
+	for i := 0; i < n; i++ {
+		buf = append(buf[:0], flag...)
+		for c := byte('!'); c <= '~'; c++ {
+			if c == flag[i] {
+				continue
+			}
+			buf[i] = c
+			if try(string(buf)) {
+				return fmt.Sprintf("character %d differs", i+1), true
+			}
+		}
+	}
+	for i := 0; i <= n; i++ {
+		for c := byte('!'); c <= '~'; c++ {
+			buf = append(append(append(buf[:0], flag[:i]...), c), flag[i:]...)
+			if try(string(buf)) {
+				return fmt.Sprintf("missing character at position %d", i+1), true
+			}
+		}
+	}
+	return "", false
+}
+
+// EditDistance is the optimal string alignment distance between a and b: the number of
+// single-byte insertions, deletions, substitutions and adjacent swaps between them
+func EditDistance(a, b string) int {
+	prev2 := make([]int, len(b)+1)
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				cur[j] = min(cur[j], prev2[j-2]+1)
+			}
+		}
+		prev2, prev, cur = prev, cur, prev2
+	}
+	return prev[len(b)]
+}
+
+// ClassifyNearMiss compares a wrong flag with an accepted flag known in plain text and
+// describes the difference when it is a near miss
+func ClassifyNearMiss(submitted, expected string, prefixes []string) (FlagVariant, bool) {
+	if submitted == expected {
+		return FlagVariant{}, false
+	}
+	for _, v := range FlagVariants(submitted, prefixes) {
+		if v.Value == expected {
+			return v, true
+		}
+	}
+	trimmed := strings.TrimSpace(submitted)
+	if d := EditDistance(trimmed, expected); d <= MaxNearMissDistance {
+		return FlagVariant{Value: expected, Kind: NearMissTypo, Detail: fmt.Sprintf("%d character edit(s) away", d)}, true
+	}
+	return FlagVariant{}, false
+}
@@ -0,0 +1,112 @@
+package models
+
+import "testing"
+
+func TestFlagFormatPrefix(t *testing.T) {
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{`^RA\{[^}]+\}$`, "RA"},
+		{`flag\{.*\}`, "flag"},
+		{`^CTF{.+}$`, "CTF"},
+		{`^[A-Z]+\{.+\}$`, ""},
+		{`^\d+$`, ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := FlagFormatPrefix(tt.format); got != tt.want {
+			t.Errorf("FlagFormatPrefix(%q) = %q, want %q", tt.format, got, tt.want)
+		}
+	}
+}
+
+func TestClassifyNearMiss(t *testing.T) {
+	prefixes := []string{"RA", "flag"}
+	expected := "RA{sql_1nject10n}"
+
+	tests := []struct {
+		name      string
+		submitted string
+		wantKind  string
+		wantMatch bool
+	}{
+		{"trailing newline", "RA{sql_1nject10n}\n", NearMissWhitespace, true},
+		{"space instead of underscore", "RA{sql 1nject10n}", NearMissWhitespace, true},
+		{"upper-cased", "RA{SQL_1NJECT10N}", NearMissCase, true},
+		{"lower-case wrapper", "ra{sql_1nject10n}", NearMissCase, true},
+		{"missing wrapper", "sql_1nject10n", NearMissWrapper, true},
+		{"other wrapper", "flag{sql_1nject10n}", NearMissWrapper, true},
+		{"one substitution", "RA{sql_1nject1On}", NearMissTypo, true},
+		{"swapped letters", "RA{sql_1njcet10n}", NearMissTypo, true},
+		{"two edits", "RA{sql_injecti0n}", NearMissTypo, true},
+		{"unrelated", "RA{totally_wrong}", "", false},
+		{"exact flag is not a miss", expected, "", false},
+	}
+
+	for _, tt := range tests {
+		v, ok := ClassifyNearMiss(tt.submitted, expected, prefixes)
+		if ok != tt.wantMatch || v.Kind != tt.wantKind {
+			t.Errorf("%s: ClassifyNearMiss(%q) = (%q, %v), want (%q, %v)", tt.name, tt.submitted, v.Kind, ok, tt.wantKind, tt.wantMatch)
+		}
+	}
+}
+
+func TestEachTypo(t *testing.T) {
+	target := "RA{ab}"
+	tests := []struct {
+		submitted  string
+		wantDetail string
+	}{
+		{"RA{abc}", "extra character at position 6"},
+		{"RA{ba}", "characters 4 and 5 swapped"},
+		{"RA{ax}", "character 5 differs"},
+		{"RA{a}", "missing character at position 5"},
+	}
+
+	for _, tt := range tests {
+		detail, found := EachTypo(tt.submitted, func(c string) bool { return c == target })
+		if !found || detail != tt.wantDetail {
+			t.Errorf("EachTypo(%q) = (%q, %v), want (%q, true)", tt.submitted, detail, found, tt.wantDetail)
+		}
+	}
+
+	if _, found := EachTypo("RA{zzzz}", func(c string) bool { return c == target }); found {
+		t.Error("EachTypo found a flag two edits away")
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"abc", "", 3},
+		{"kitten", "sitting", 3},
+		{"flag", "falg", 1},
+		{"flag", "flag", 0},
+	}
+
+	for _, tt := range tests {
+		if got := EditDistance(tt.a, tt.b); got != tt.want {
+			t.Errorf("EditDistance(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestSummarizeNearMisses(t *testing.T) {
+	report := SummarizeNearMisses("c1", "Baby SQLi", []NearMiss{
+		{TeamID: "t1", UserID: "u1", FlagID: "f1", Kind: NearMissCase, Detail: "letter case"},
+		{TeamID: "t1", UserID: "u2", FlagID: "f1", Kind: NearMissCase, Detail: "letter case"},
+		{UserID: "u3", FlagID: "f2", Kind: NearMissTypo, Detail: "character 5 differs"},
+	})
+
+	if report.Total != 3 || report.Owners != 2 {
+		t.Errorf("Total, Owners = %d, %d, want 3, 2", report.Total, report.Owners)
+	}
+	if report.ByKind[NearMissCase] != 2 || report.ByFlag["f2"] != 1 || report.ByDetail["letter case"] != 2 {
+		t.Errorf("unexpected breakdown: %+v", report)
+	}
+}
@@ -0,0 +1,64 @@
+package models
+
+import "sort"
+
+// Stored counters a reconciliation checks against the raw submissions
+const (
+	CounterChallengeSolves = "challenge_solve_count" // challenges.solve_count
+	CounterContestSolves   = "contest_solve_count"   // contest_challenge_solves.solve_count
+	CounterTeamScore       = "team_score"            // teams.score
+)
+
+// CounterDrift is a stored counter that disagrees with the data it is derived from
+type CounterDrift struct {
+	Counter   string `json:"counter"`
+	ContestID string `json:"contest_id,omitempty"`
+	TargetID  string `json:"target_id"` // challenge or team
+	Name      string `json:"name,omitempty"`
+	Stored    int    `json:"stored"`
+	Expected  int    `json:"expected"`
+}
+
+// ReconciliationReport is what a reconciliation found, and whether it repaired it
+type ReconciliationReport struct {
+	Drifts []CounterDrift `json:"drifts"`
+	// Entries settling the score ledger with the submissions, which team scores and every
+	// scoreboard are derived from
+	LedgerEntries []ScoreLedgerEntry `json:"ledger_entries"`
+	Applied       bool               `json:"applied"`
+}
+
+// InSync reports whether there was nothing to repair
+func (r *ReconciliationReport) InSync() bool {
+	return len(r.Drifts) == 0 && len(r.LedgerEntries) == 0
+}
+
+// DiffCounters lists the counters whose stored value differs from the expected one, by
+// target ID. A target missing from either map counts as 0; names label the targets.
+func DiffCounters(counter, contestID string, stored, expected map[string]int, names map[string]string) []CounterDrift {
+	var drifts []CounterDrift
+	add := func(id string) {
+		if stored[id] != expected[id] {
+			drifts = append(drifts, CounterDrift{
+				Counter:   counter,
+				ContestID: contestID,
+				TargetID:  id,
+				Name:      names[id],
+				Stored:    stored[id],
+				Expected:  expected[id],
+			})
+		}
+	}
+	for id := range stored {
+		add(id)
+	}
+	for id := range expected {
+		if _, seen := stored[id]; !seen {
+			add(id)
+		}
+	}
+	sort.Slice(drifts, func(i, j int) bool {
+		return drifts[i].TargetID < drifts[j].TargetID
+	})
+	return drifts
+}
@@ -0,0 +1,34 @@
+package models
+
+import "testing"
+
+func TestDiffCounters(t *testing.T) {
+	drifts := DiffCounters(CounterContestSolves, "ctf",
+		map[string]int{"a": 3, "b": 2, "c": 1, "z": 0},
+		map[string]int{"a": 2, "b": 2, "d": 4},
+		map[string]string{"a": "Alpha", "d": "Delta"},
+	)
+
+	want := []CounterDrift{
+		{Counter: CounterContestSolves, ContestID: "ctf", TargetID: "a", Name: "Alpha", Stored: 3, Expected: 2},
+		{Counter: CounterContestSolves, ContestID: "ctf", TargetID: "c", Stored: 1, Expected: 0},
+		{Counter: CounterContestSolves, ContestID: "ctf", TargetID: "d", Name: "Delta", Stored: 0, Expected: 4},
+	}
+	if len(drifts) != len(want) {
+		t.Fatalf("got %d drifts %+v, want %d", len(drifts), drifts, len(want))
+	}
+	for i := range want {
+		if drifts[i] != want[i] {
+			t.Errorf("drift %d = %+v, want %+v", i, drifts[i], want[i])
+		}
+	}
+}
+
+func TestReconciliationReportInSync(t *testing.T) {
+	if r := (&ReconciliationReport{}); !r.InSync() {
+		t.Error("empty report should be in sync")
+	}
+	if r := (&ReconciliationReport{LedgerEntries: []ScoreLedgerEntry{{Points: 10}}}); r.InSync() {
+		t.Error("report with ledger corrections should not be in sync")
+	}
+}
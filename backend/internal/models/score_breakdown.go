@@ -0,0 +1,128 @@
+package models
+
+import "time"
+
+// ScoreBreakdown itemizes a user's or team's score in a contest: what solves earned,
+// what revealed hints cost and what admins adjusted
+type ScoreBreakdown struct {
+	ContestID     string         `json:"contest_id"`
+	TargetType    string         `json:"target_type"` // ScoreAdjustmentTargetUser or ScoreAdjustmentTargetTeam
+	TargetID      string         `json:"target_id"`
+	Solves        []SolveScore   `json:"solves"`
+	PartialCredit []PartialScore `json:"partial_credit"`
+	Hints         []HintCost     `json:"hints"`
+	SolvePoints   int            `json:"solve_points"`
+	BonusPoints   int            `json:"bonus_points"` // first blood bonuses
+	PartialPoints int            `json:"partial_points"`
+	HintPenalty   int            `json:"hint_penalty"` // total cost of revealed hints, subtracted from the score
+	Adjustments   int            `json:"adjustments"`
+	Total         int            `json:"total"`
+	FrozenAt      *time.Time     `json:"frozen_at,omitempty"` // set when later activity is not counted yet
+}
+
+// SolveScore is what one solved challenge contributes
+type SolveScore struct {
+	ChallengeID string    `json:"challenge_id"`
+	Title       string    `json:"title"`
+	Category    string    `json:"category"`
+	Points      int       `json:"points"`
+	Bonus       int       `json:"bonus"`
+	SolvedAt    time.Time `json:"solved_at"`
+}
+
+// PartialScore is partial credit awarded for an answer to a manually graded challenge
+type PartialScore struct {
+	ChallengeID string    `json:"challenge_id"`
+	Title       string    `json:"title"`
+	Points      int       `json:"points"`
+	AnsweredAt  time.Time `json:"answered_at"`
+}
+
+// HintCost is one revealed hint charged to the score
+type HintCost struct {
+	HintID      string    `json:"hint_id"`
+	ChallengeID string    `json:"challenge_id"`
+	Title       string    `json:"title"`
+	Cost        int       `json:"cost"`
+	RevealedAt  time.Time `json:"revealed_at"`
+}
+
+// Tally sums the itemized entries into the breakdown's totals
+func (b *ScoreBreakdown) Tally() {
+	b.SolvePoints, b.BonusPoints, b.PartialPoints, b.HintPenalty = 0, 0, 0, 0
+	for _, s := range b.Solves {
+		b.SolvePoints += s.Points
+		b.BonusPoints += s.Bonus
+	}
+	for _, p := range b.PartialCredit {
+		b.PartialPoints += p.Points
+	}
+	for _, h := range b.Hints {
+		b.HintPenalty += h.Cost
+	}
+	b.Total = b.SolvePoints + b.BonusPoints + b.PartialPoints - b.HintPenalty + b.Adjustments
+}
+
+// AddLedgerEntries itemizes ledger entries into the breakdown and tallies it. Solves and
+// partial credit are summed per challenge, so revaluations and invalidations are folded
+// into the solve they concern; titles are left for the caller to fill in.
+func (b *ScoreBreakdown) AddLedgerEntries(entries []ScoreLedgerEntry) {
+	solves := make(map[string]int)
+	partial := make(map[string]int)
+	for _, e := range entries {
+		switch e.Kind {
+		case LedgerSolve, LedgerFirstBlood, LedgerRevaluation, LedgerInvalidation:
+			i, seen := solves[e.ChallengeID]
+			if !seen {
+				i = len(b.Solves)
+				solves[e.ChallengeID] = i
+				b.Solves = append(b.Solves, SolveScore{ChallengeID: e.ChallengeID})
+			}
+			if e.Kind == LedgerFirstBlood {
+				b.Solves[i].Bonus += e.Points
+			} else {
+				b.Solves[i].Points += e.Points
+			}
+			if e.Kind == LedgerSolve {
+				b.Solves[i].SolvedAt = e.CreatedAt
+			}
+		case LedgerPartial:
+			i, seen := partial[e.ChallengeID]
+			if !seen {
+				i = len(b.PartialCredit)
+				partial[e.ChallengeID] = i
+				b.PartialCredit = append(b.PartialCredit, PartialScore{ChallengeID: e.ChallengeID})
+			}
+			b.PartialCredit[i].Points += e.Points
+			if e.Points > 0 {
+				b.PartialCredit[i].AnsweredAt = e.CreatedAt
+			}
+		case LedgerHint:
+			b.Hints = append(b.Hints, HintCost{
+				HintID:      e.ReferenceID,
+				ChallengeID: e.ChallengeID,
+				Cost:        -e.Points,
+				RevealedAt:  e.CreatedAt,
+			})
+		case LedgerAdjustment:
+			b.Adjustments += e.Points
+		}
+	}
+
+	// Drop what nets to nothing, e.g. invalidated solves and partial credit replaced by a solve
+	kept := b.Solves[:0]
+	for _, s := range b.Solves {
+		if s.Points != 0 || s.Bonus != 0 {
+			kept = append(kept, s)
+		}
+	}
+	b.Solves = kept
+	keptPartial := b.PartialCredit[:0]
+	for _, p := range b.PartialCredit {
+		if p.Points != 0 {
+			keptPartial = append(keptPartial, p)
+		}
+	}
+	b.PartialCredit = keptPartial
+	b.Tally()
+}
@@ -0,0 +1,27 @@
+package models
+
+import "testing"
+
+func TestScoreBreakdownTally(t *testing.T) {
+	b := ScoreBreakdown{
+		Solves:        []SolveScore{{Points: 500, Bonus: 50}, {Points: 200}},
+		PartialCredit: []PartialScore{{Points: 30}},
+		Hints:         []HintCost{{Cost: 25}, {Cost: 10}},
+		Adjustments:   -100,
+	}
+	b.Tally()
+
+	if b.SolvePoints != 700 || b.BonusPoints != 50 || b.PartialPoints != 30 || b.HintPenalty != 35 {
+		t.Errorf("unexpected totals: %+v", b)
+	}
+	if b.Total != 645 {
+		t.Errorf("Total = %d, want 645", b.Total)
+	}
+
+	// Tally can be called again after entries change
+	b.Hints = nil
+	b.Tally()
+	if b.HintPenalty != 0 || b.Total != 680 {
+		t.Errorf("after removing hints: HintPenalty, Total = %d, %d, want 0, 680", b.HintPenalty, b.Total)
+	}
+}
@@ -0,0 +1,282 @@
+package models
+
+import (
+	"sort"
+	"time"
+)
+
+// Score ledger entry kinds
+const (
+	LedgerSolve        = "solve"        // a challenge's value, credited for the first solve
+	LedgerFirstBlood   = "first_blood"  // bonus for being among the first solvers
+	LedgerPartial      = "partial"      // partial credit for an answer to a manually graded challenge
+	LedgerHint         = "hint"         // cost of a revealed hint
+	LedgerAdjustment   = "adjustment"   // manual adjustment by an admin
+	LedgerInvalidation = "invalidation" // credit taken back with an invalidated solve
+	LedgerRevaluation  = "revaluation"  // change in what an earlier solve is worth, e.g. dynamic decay
+)
+
+// ScoreLedgerEntry is one point-affecting event. The ledger is append-only: every score is
+// the sum of its entries, and corrections are recorded as new entries, never as edits.
+type ScoreLedgerEntry struct {
+	ID          string    `json:"id"`
+	ContestID   string    `json:"contest_id,omitempty"`
+	TeamID      string    `json:"team_id,omitempty"`
+	UserID      string    `json:"user_id,omitempty"`
+	ChallengeID string    `json:"challenge_id,omitempty"`
+	Kind        string    `json:"kind"`
+	Points      int       `json:"points"`
+	ActorID     string    `json:"actor_id,omitempty"`     // admin behind the event, if any
+	ReferenceID string    `json:"reference_id,omitempty"` // submission, hint, answer or adjustment it stems from
+	Reason      string    `json:"reason,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// LedgerTotal sums the points of entries
+func LedgerTotal(entries []ScoreLedgerEntry) int {
+	total := 0
+	for _, e := range entries {
+		total += e.Points
+	}
+	return total
+}
+
+// LedgerTotals sums the points of entries per key, e.g. per team. Entries with an empty
+// key are skipped.
+func LedgerTotals(entries []ScoreLedgerEntry, key func(ScoreLedgerEntry) string) map[string]int {
+	totals := make(map[string]int)
+	for _, e := range entries {
+		if k := key(e); k != "" {
+			totals[k] += e.Points
+		}
+	}
+	return totals
+}
+
+// LedgerChallengePoints sums what entries credit per challenge, leaving out hint costs and
+// adjustments
+func LedgerChallengePoints(entries []ScoreLedgerEntry) map[string]int {
+	points := make(map[string]int)
+	for _, e := range entries {
+		if e.ChallengeID != "" && e.Kind != LedgerHint && e.Kind != LedgerAdjustment {
+			points[e.ChallengeID] += e.Points
+		}
+	}
+	return points
+}
+
+// LedgerLine is a ledger entry with the score it left its team or user at
+type LedgerLine struct {
+	ScoreLedgerEntry
+	Balance int `json:"balance"`
+}
+
+// LedgerStatement explains a score entry by entry, oldest first
+type LedgerStatement struct {
+	TargetType string       `json:"target_type"` // ScoreAdjustmentTargetUser or ScoreAdjustmentTargetTeam
+	TargetID   string       `json:"target_id"`
+	ContestID  string       `json:"contest_id,omitempty"`
+	Entries    []LedgerLine `json:"entries"`
+	Total      int          `json:"total"`
+}
+
+// NewLedgerStatement builds a statement from entries sorted oldest first
+func NewLedgerStatement(targetType, targetID, contestID string, entries []ScoreLedgerEntry) *LedgerStatement {
+	st := &LedgerStatement{
+		TargetType: targetType,
+		TargetID:   targetID,
+		ContestID:  contestID,
+		Entries:    make([]LedgerLine, 0, len(entries)),
+	}
+	for _, e := range entries {
+		st.Total += e.Points
+		st.Entries = append(st.Entries, LedgerLine{ScoreLedgerEntry: e, Balance: st.Total})
+	}
+	return st
+}
+
+// LedgerScope is a challenge in a contest ("" outside contests), the unit credit is
+// settled in
+type LedgerScope struct {
+	ContestID   string
+	ChallengeID string
+}
+
+// ChallengeCredit is what one solver should hold on a challenge right now
+type ChallengeCredit struct {
+	TeamID     string
+	UserID     string
+	Solve      int // the challenge's current value, when solved
+	FirstBlood int
+	Partial    int // best partial credit, while unsolved
+}
+
+func (c ChallengeCredit) total() int {
+	return c.Solve + c.FirstBlood + c.Partial
+}
+
+// LedgerCause is the event a settlement of a challenge's credit is recorded for
+type LedgerCause struct {
+	Kind string // LedgerSolve, LedgerPartial, LedgerInvalidation or LedgerRevaluation
+	// The team (or user, without a team) the event happened to. Every other solver's
+	// change is a side effect and is recorded as a revaluation.
+	TeamID      string
+	UserID      string
+	ReferenceID string
+	ActorID     string
+	Reason      string
+	At          time.Time
+}
+
+func (c LedgerCause) concerns(teamID, userID string) bool {
+	if c.TeamID != "" {
+		return teamID == c.TeamID
+	}
+	return teamID == "" && userID == c.UserID
+}
+
+type ledgerHolder struct {
+	teamID, userID string
+}
+
+// SettleChallengeCredit returns the entries that bring what the ledger credits on a
+// challenge (current, excluding hints and adjustments) in line with what each solver
+// should hold (expected). Settling an already settled challenge returns nothing.
+func SettleChallengeCredit(contestID, challengeID string, current []ScoreLedgerEntry, expected []ChallengeCredit, cause LedgerCause) []ScoreLedgerEntry {
+	var holders []ledgerHolder
+	owed := make(map[ledgerHolder]ChallengeCredit)
+	for _, c := range expected {
+		h := ledgerHolder{c.TeamID, c.UserID}
+		if _, seen := owed[h]; !seen {
+			holders = append(holders, h)
+		}
+		owed[h] = c
+	}
+	held := make(map[ledgerHolder]int)
+	heldPartial := make(map[ledgerHolder]int)
+	for _, e := range current {
+		if e.ChallengeID != challengeID || e.Kind == LedgerHint || e.Kind == LedgerAdjustment {
+			continue
+		}
+		h := ledgerHolder{e.TeamID, e.UserID}
+		if _, seen := owed[h]; !seen {
+			if _, seen := held[h]; !seen {
+				holders = append(holders, h)
+			}
+		}
+		held[h] += e.Points
+		if e.Kind == LedgerPartial {
+			heldPartial[h] += e.Points
+		}
+	}
+
+	var entries []ScoreLedgerEntry
+	for _, h := range holders {
+		credit := owed[h]
+		diff := credit.total() - held[h]
+		if diff == 0 {
+			continue
+		}
+		entry := func(kind string, points int) ScoreLedgerEntry {
+			return ScoreLedgerEntry{
+				ContestID:   contestID,
+				TeamID:      h.teamID,
+				UserID:      h.userID,
+				ChallengeID: challengeID,
+				Kind:        kind,
+				Points:      points,
+				ActorID:     cause.ActorID,
+				ReferenceID: cause.ReferenceID,
+				Reason:      cause.Reason,
+				CreatedAt:   cause.At,
+			}
+		}
+
+		subject := cause.concerns(h.teamID, h.userID)
+		switch {
+		case subject && cause.Kind == LedgerSolve && held[h] == heldPartial[h]:
+			// A new solve: its value and bonus, replacing any partial credit held so far
+			if credit.Solve != 0 {
+				entries = append(entries, entry(LedgerSolve, credit.Solve))
+			}
+			if credit.FirstBlood != 0 {
+				entries = append(entries, entry(LedgerFirstBlood, credit.FirstBlood))
+			}
+			if rest := diff - credit.Solve - credit.FirstBlood; rest != 0 {
+				entries = append(entries, entry(LedgerPartial, rest))
+			}
+		case subject && cause.Kind == LedgerPartial, subject && cause.Kind == LedgerInvalidation && diff < 0:
+			entries = append(entries, entry(cause.Kind, diff))
+		default:
+			entries = append(entries, entry(LedgerRevaluation, diff))
+		}
+	}
+	return entries
+}
+
+// CreditEvent is a past event that changed the credit on a challenge
+type CreditEvent struct {
+	Cause      LedgerCause
+	SolveCount int // the solve count the challenge is valued at right after the event
+}
+
+// ReplayCredit lists, oldest first, the events that changed the credit on a challenge in a
+// contest ("" outside contests), so that a ledger can be filled as if it had recorded them
+// as they happened: the first solve of each team (or user without a team), partial credit,
+// and outside contests the solves made in contests too, since they move the global solve
+// count the challenge is valued at there. solves are the valid correct submissions to the
+// challenge (in the contest, or in every contest for ""), answers its judged answers in the
+// contest.
+func ReplayCredit(contestID string, solves []Submission, answers []ManualSubmission, reason string) []CreditEvent {
+	sorted := append([]Submission(nil), solves...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Timestamp.Before(sorted[j].Timestamp)
+	})
+
+	var events []CreditEvent
+	claimed := make(map[string]bool)
+	for _, sub := range sorted {
+		owner := sub.UserID
+		if sub.TeamID != "" {
+			owner = "team:" + sub.TeamID
+		}
+		key := sub.ContestID + "|" + owner
+		if claimed[key] {
+			continue
+		}
+		claimed[key] = true
+		cause := LedgerCause{Kind: LedgerSolve, TeamID: sub.TeamID, UserID: sub.UserID, ReferenceID: sub.ID, Reason: reason, At: sub.Timestamp}
+		if sub.ContestID != contestID {
+			cause = LedgerCause{Kind: LedgerRevaluation, ReferenceID: sub.ID, Reason: reason + " in a contest", At: sub.Timestamp}
+		}
+		events = append(events, CreditEvent{Cause: cause, SolveCount: len(claimed)})
+	}
+	for _, a := range answers {
+		if a.Status != JudgementPartial || a.PointsAwarded <= 0 {
+			continue
+		}
+		events = append(events, CreditEvent{Cause: LedgerCause{
+			Kind:        LedgerPartial,
+			TeamID:      a.TeamID,
+			UserID:      a.UserID,
+			ReferenceID: a.ID,
+			ActorID:     a.JudgedBy,
+			Reason:      reason,
+			At:          a.CreatedAt,
+		}})
+	}
+
+	// Partial credit is valued at the solve count reached by the time it was given
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Cause.At.Before(events[j].Cause.At)
+	})
+	count := 0
+	for i := range events {
+		if events[i].Cause.Kind == LedgerPartial {
+			events[i].SolveCount = count
+		} else {
+			count = events[i].SolveCount
+		}
+	}
+	return events
+}
@@ -0,0 +1,205 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSettleChallengeCredit(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	held := func(team, kind string, points int) ScoreLedgerEntry {
+		return ScoreLedgerEntry{TeamID: team, UserID: team + "-u", ChallengeID: "c1", Kind: kind, Points: points}
+	}
+	owed := func(team string, solve, bonus, partial int) ChallengeCredit {
+		return ChallengeCredit{TeamID: team, UserID: team + "-u", Solve: solve, FirstBlood: bonus, Partial: partial}
+	}
+	solveBy := func(team string) LedgerCause {
+		return LedgerCause{Kind: LedgerSolve, TeamID: team, ReferenceID: "sub-" + team, At: now}
+	}
+
+	tests := []struct {
+		name     string
+		current  []ScoreLedgerEntry
+		expected []ChallengeCredit
+		cause    LedgerCause
+		want     []ScoreLedgerEntry // kind, team and points are compared
+	}{
+		{
+			name:     "first solve earns value and first blood",
+			expected: []ChallengeCredit{owed("t1", 500, 50, 0)},
+			cause:    solveBy("t1"),
+			want:     []ScoreLedgerEntry{held("t1", LedgerSolve, 500), held("t1", LedgerFirstBlood, 50)},
+		},
+		{
+			name:     "a later solve revalues earlier solvers",
+			current:  []ScoreLedgerEntry{held("t1", LedgerSolve, 500)},
+			expected: []ChallengeCredit{owed("t1", 480, 0, 0), owed("t2", 480, 0, 0)},
+			cause:    solveBy("t2"),
+			want:     []ScoreLedgerEntry{held("t1", LedgerRevaluation, -20), held("t2", LedgerSolve, 480)},
+		},
+		{
+			name:     "a solve replaces partial credit",
+			current:  []ScoreLedgerEntry{held("t1", LedgerPartial, 100)},
+			expected: []ChallengeCredit{owed("t1", 500, 0, 0)},
+			cause:    solveBy("t1"),
+			want:     []ScoreLedgerEntry{held("t1", LedgerSolve, 500), held("t1", LedgerPartial, -100)},
+		},
+		{
+			name: "invalidation takes the credit back and revalues the rest",
+			current: []ScoreLedgerEntry{
+				held("t1", LedgerSolve, 500), held("t1", LedgerFirstBlood, 50),
+				held("t2", LedgerSolve, 480), held("t1", LedgerRevaluation, -20),
+			},
+			expected: []ChallengeCredit{owed("t2", 500, 50, 0)},
+			cause:    LedgerCause{Kind: LedgerInvalidation, TeamID: "t1", At: now},
+			want:     []ScoreLedgerEntry{held("t2", LedgerRevaluation, 70), held("t1", LedgerInvalidation, -530)},
+		},
+		{
+			name:     "hints and adjustments are not challenge credit",
+			current:  []ScoreLedgerEntry{held("t1", LedgerSolve, 500), held("t1", LedgerHint, -50), held("t1", LedgerAdjustment, 10)},
+			expected: []ChallengeCredit{owed("t1", 500, 0, 0)},
+			cause:    solveBy("t1"),
+		},
+		{
+			name:     "settling twice records nothing new",
+			current:  []ScoreLedgerEntry{held("t1", LedgerSolve, 500), held("t1", LedgerFirstBlood, 50)},
+			expected: []ChallengeCredit{owed("t1", 500, 50, 0)},
+			cause:    solveBy("t1"),
+		},
+		{
+			name:     "partial credit for the answering team",
+			current:  []ScoreLedgerEntry{held("t1", LedgerPartial, 30)},
+			expected: []ChallengeCredit{owed("t1", 0, 0, 80)},
+			cause:    LedgerCause{Kind: LedgerPartial, TeamID: "t1", At: now},
+			want:     []ScoreLedgerEntry{held("t1", LedgerPartial, 50)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SettleChallengeCredit("ctf", "c1", tt.current, tt.expected, tt.cause)
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d entries %+v, want %d", len(got), got, len(tt.want))
+			}
+			for i, e := range got {
+				w := tt.want[i]
+				if e.Kind != w.Kind || e.TeamID != w.TeamID || e.Points != w.Points {
+					t.Errorf("entry %d = %s %s %+d, want %s %s %+d", i, e.TeamID, e.Kind, e.Points, w.TeamID, w.Kind, w.Points)
+				}
+				if e.ContestID != "ctf" || e.ChallengeID != "c1" || !e.CreatedAt.Equal(now) {
+					t.Errorf("entry %d not stamped with the contest, challenge and cause time: %+v", i, e)
+				}
+			}
+			if net := LedgerTotal(append(tt.current, got...)) - LedgerTotal(tt.current); net != creditTotal(tt.expected)-challengeCreditHeld(tt.current) {
+				t.Errorf("entries net %d, want the difference between owed and held credit", net)
+			}
+		})
+	}
+}
+
+func creditTotal(credits []ChallengeCredit) int {
+	total := 0
+	for _, c := range credits {
+		total += c.total()
+	}
+	return total
+}
+
+func challengeCreditHeld(entries []ScoreLedgerEntry) int {
+	total := 0
+	for _, e := range entries {
+		if e.Kind != LedgerHint && e.Kind != LedgerAdjustment {
+			total += e.Points
+		}
+	}
+	return total
+}
+
+func TestReplayCredit(t *testing.T) {
+	at := func(minute int) time.Time { return time.Date(2026, 3, 1, 10, minute, 0, 0, time.UTC) }
+	solves := []Submission{
+		{ID: "s4", UserID: "u3", ChallengeID: "c1", IsCorrect: true, Timestamp: at(20)},
+		{ID: "s1", UserID: "u1", TeamID: "t1", ChallengeID: "c1", IsCorrect: true, Timestamp: at(0)},
+		{ID: "s2", UserID: "u2", TeamID: "t1", ChallengeID: "c1", IsCorrect: true, Timestamp: at(5)},
+		{ID: "s3", UserID: "u5", TeamID: "t2", ChallengeID: "c1", ContestID: "ctf", IsCorrect: true, Timestamp: at(10)},
+	}
+	answers := []ManualSubmission{
+		{ID: "a1", UserID: "u4", TeamID: "t4", ChallengeID: "c1", Status: JudgementPartial, PointsAwarded: 40, JudgedBy: "admin", CreatedAt: at(15)},
+		{ID: "a2", UserID: "u4", TeamID: "t4", ChallengeID: "c1", Status: JudgementRejected, CreatedAt: at(16)},
+	}
+
+	got := ReplayCredit("", solves, answers, "seeded")
+	want := []struct {
+		kind, team, user, ref string
+		at                    time.Time
+		count                 int
+	}{
+		{LedgerSolve, "t1", "u1", "s1", at(0), 1},
+		{LedgerRevaluation, "", "", "s3", at(10), 2}, // a contest solve moves the global count
+		{LedgerPartial, "t4", "u4", "a1", at(15), 2},
+		{LedgerSolve, "", "u3", "s4", at(20), 3},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d events %+v, want %d", len(got), got, len(want))
+	}
+	for i, w := range want {
+		c := got[i].Cause
+		if c.Kind != w.kind || c.TeamID != w.team || c.UserID != w.user || c.ReferenceID != w.ref || !c.At.Equal(w.at) || got[i].SolveCount != w.count {
+			t.Errorf("event %d = %+v at count %d, want %s by %s/%s from %s at %v, count %d",
+				i, c, got[i].SolveCount, w.kind, w.team, w.user, w.ref, w.at, w.count)
+		}
+	}
+}
+
+func TestLedgerTotalsAndStatement(t *testing.T) {
+	entries := []ScoreLedgerEntry{
+		{TeamID: "t1", UserID: "u1", Kind: LedgerSolve, Points: 500},
+		{TeamID: "t1", UserID: "u2", Kind: LedgerHint, Points: -50},
+		{UserID: "u1", Kind: LedgerAdjustment, Points: 20},
+		{TeamID: "t2", UserID: "u3", Kind: LedgerSolve, Points: 300},
+	}
+
+	teams := LedgerTotals(entries, func(e ScoreLedgerEntry) string { return e.TeamID })
+	if len(teams) != 2 || teams["t1"] != 450 || teams["t2"] != 300 {
+		t.Errorf("team totals = %v", teams)
+	}
+	users := LedgerTotals(entries, func(e ScoreLedgerEntry) string { return e.UserID })
+	if users["u1"] != 520 || users["u2"] != -50 || users["u3"] != 300 {
+		t.Errorf("user totals = %v", users)
+	}
+
+	st := NewLedgerStatement(ScoreAdjustmentTargetTeam, "t1", "", entries[:2])
+	if st.Total != 450 || len(st.Entries) != 2 || st.Entries[0].Balance != 500 || st.Entries[1].Balance != 450 {
+		t.Errorf("statement = %+v", st)
+	}
+}
+
+func TestScoreBreakdownAddLedgerEntries(t *testing.T) {
+	solvedAt := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	b := ScoreBreakdown{}
+	b.AddLedgerEntries([]ScoreLedgerEntry{
+		{ChallengeID: "c1", Kind: LedgerPartial, Points: 40},
+		{ChallengeID: "c1", Kind: LedgerSolve, Points: 500, CreatedAt: solvedAt},
+		{ChallengeID: "c1", Kind: LedgerFirstBlood, Points: 50},
+		{ChallengeID: "c1", Kind: LedgerPartial, Points: -40},
+		{ChallengeID: "c1", Kind: LedgerRevaluation, Points: -20},
+		{ChallengeID: "c2", Kind: LedgerSolve, Points: 300},
+		{ChallengeID: "c2", Kind: LedgerInvalidation, Points: -300},
+		{ChallengeID: "c3", Kind: LedgerPartial, Points: 25},
+		{ChallengeID: "c3", Kind: LedgerHint, Points: -10, ReferenceID: "h1"},
+		{Kind: LedgerAdjustment, Points: -5},
+	})
+
+	if len(b.Solves) != 1 || b.Solves[0].Points != 480 || b.Solves[0].Bonus != 50 || !b.Solves[0].SolvedAt.Equal(solvedAt) {
+		t.Errorf("solves = %+v, want c1 only at 480 + 50", b.Solves)
+	}
+	if len(b.PartialCredit) != 1 || b.PartialCredit[0].ChallengeID != "c3" {
+		t.Errorf("partial credit = %+v, want c3 only", b.PartialCredit)
+	}
+	if len(b.Hints) != 1 || b.Hints[0].HintID != "h1" || b.Hints[0].Cost != 10 {
+		t.Errorf("hints = %+v", b.Hints)
+	}
+	if b.Total != 480+50+25-10-5 {
+		t.Errorf("Total = %d", b.Total)
+	}
+}
@@ -0,0 +1,187 @@
+package models
+
+import (
+	"errors"
+	"math"
+	"sort"
+)
+
+const (
+	ScoringLogarithmic = "logarithmic"
+	ScoringCTFd        = "ctfd"
+
+	// defaultDecay is used when a challenge has no decay configured
+	defaultDecay = 10
+	// MaxCurvePreviewSolves bounds the point curve preview
+	MaxCurvePreviewSolves = 1000
+)
+
+// ScoringParams are the challenge settings a scoring strategy works from
+type ScoringParams struct {
+	MaxPoints int `json:"max_points"`
+	MinPoints int `json:"min_points"`
+	Decay     int `json:"decay"` // meaning depends on the strategy, usually the solves until MinPoints
+}
+
+// ScoringStrategy computes what a challenge is worth after a number of solves
+type ScoringStrategy interface {
+	Name() string
+	Description() string
+	Points(p ScoringParams, solves int) int
+}
+
+var scoringStrategies = map[string]ScoringStrategy{}
+
+// RegisterScoringStrategy makes a strategy available under its name, replacing any
+// strategy registered under the same name
+func RegisterScoringStrategy(s ScoringStrategy) {
+	scoringStrategies[s.Name()] = s
+}
+
+// ScoringStrategyFor returns the strategy registered under name. Unknown and empty
+// names fall back to the quadratic dynamic strategy, the historical default.
+func ScoringStrategyFor(name string) ScoringStrategy {
+	if s, ok := scoringStrategies[name]; ok {
+		return s
+	}
+	return scoringStrategies[ScoringDynamic]
+}
+
+func IsValidScoringType(name string) bool {
+	_, ok := scoringStrategies[name]
+	return ok
+}
+
+// ScoringStrategies returns every registered strategy sorted by name
+func ScoringStrategies() []ScoringStrategy {
+	strategies := make([]ScoringStrategy, 0, len(scoringStrategies))
+	for _, s := range scoringStrategies {
+		strategies = append(strategies, s)
+	}
+	sort.Slice(strategies, func(i, j int) bool { return strategies[i].Name() < strategies[j].Name() })
+	return strategies
+}
+
+// ValidateScoringParams rejects point ranges no strategy can work with
+func ValidateScoringParams(p ScoringParams) error {
+	if p.MaxPoints < 0 || p.MinPoints < 0 {
+		return errors.New("points cannot be negative")
+	}
+	if p.MinPoints > p.MaxPoints {
+		return errors.New("min_points cannot exceed max_points")
+	}
+	if p.Decay < 0 {
+		return errors.New("decay cannot be negative")
+	}
+	return nil
+}
+
+// CurvePoint is the value of a challenge after a number of solves
+type CurvePoint struct {
+	Solves int `json:"solves"`
+	Points int `json:"points"`
+}
+
+// PointsCurve evaluates a strategy for 0..maxSolves solves
+func PointsCurve(strategy ScoringStrategy, p ScoringParams, maxSolves int) []CurvePoint {
+	curve := make([]CurvePoint, 0, maxSolves+1)
+	for n := 0; n <= maxSolves; n++ {
+		curve = append(curve, CurvePoint{Solves: n, Points: strategy.Points(p, n)})
+	}
+	return curve
+}
+
+func (p ScoringParams) decay() int {
+	if p.Decay <= 0 {
+		return defaultDecay
+	}
+	return p.Decay
+}
+
+func (p ScoringParams) clamp(points int) int {
+	if points < p.MinPoints {
+		return p.MinPoints
+	}
+	return points
+}
+
+type staticScoring struct{}
+
+func (staticScoring) Name() string        { return ScoringStatic }
+func (staticScoring) Description() string { return "Always worth max_points" }
+func (staticScoring) Points(p ScoringParams, _ int) int {
+	return p.MaxPoints
+}
+
+type linearScoring struct{}
+
+func (linearScoring) Name() string { return ScoringLinear }
+func (linearScoring) Description() string {
+	return "Drops by the same amount per solve, reaching min_points after decay solves"
+}
+func (linearScoring) Points(p ScoringParams, solves int) int {
+	if solves <= 0 {
+		return p.MaxPoints
+	}
+	decrease := float64(p.MaxPoints-p.MinPoints) * float64(solves) / float64(p.decay())
+	return p.clamp(int(math.Round(float64(p.MaxPoints) - decrease)))
+}
+
+type dynamicScoring struct{}
+
+func (dynamicScoring) Name() string { return ScoringDynamic }
+func (dynamicScoring) Description() string {
+	return "Quadratic decay, slow at first and reaching min_points after decay solves"
+}
+func (dynamicScoring) Points(p ScoringParams, solves int) int {
+	if solves <= 0 {
+		return p.MaxPoints
+	}
+	d := float64(p.decay())
+	value := ((float64(p.MinPoints)-float64(p.MaxPoints))/(d*d))*float64(solves*solves) + float64(p.MaxPoints)
+	return p.clamp(int(math.Round(value)))
+}
+
+type logarithmicScoring struct{}
+
+func (logarithmicScoring) Name() string { return ScoringLogarithmic }
+func (logarithmicScoring) Description() string {
+	return "Logarithmic decay, steep for the first solves and reaching min_points after decay solves"
+}
+func (logarithmicScoring) Points(p ScoringParams, solves int) int {
+	if solves <= 0 {
+		return p.MaxPoints
+	}
+	fraction := math.Log1p(float64(solves)) / math.Log1p(float64(p.decay()))
+	value := float64(p.MaxPoints) - float64(p.MaxPoints-p.MinPoints)*fraction
+	return p.clamp(int(math.Round(value)))
+}
+
+// ctfdScoring is CTFd's dynamic value formula: the first solver keeps the initial value,
+// the value is rounded up and a decay of 0 counts as 1
+type ctfdScoring struct{}
+
+func (ctfdScoring) Name() string { return ScoringCTFd }
+func (ctfdScoring) Description() string {
+	return "CTFd's dynamic formula: quadratic decay counted from the second solve, rounded up"
+}
+func (ctfdScoring) Points(p ScoringParams, solves int) int {
+	if solves > 0 {
+		solves--
+	}
+	decay := p.Decay
+	if decay <= 0 {
+		decay = 1
+	}
+	d := float64(decay)
+	value := ((float64(p.MinPoints)-float64(p.MaxPoints))/(d*d))*float64(solves*solves) + float64(p.MaxPoints)
+	return p.clamp(int(math.Ceil(value)))
+}
+
+func init() {
+	RegisterScoringStrategy(staticScoring{})
+	RegisterScoringStrategy(linearScoring{})
+	RegisterScoringStrategy(dynamicScoring{})
+	RegisterScoringStrategy(logarithmicScoring{})
+	RegisterScoringStrategy(ctfdScoring{})
+}
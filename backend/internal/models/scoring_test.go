@@ -0,0 +1,95 @@
+package models
+
+import "testing"
+
+func TestScoringStrategies(t *testing.T) {
+	p := ScoringParams{MaxPoints: 500, MinPoints: 100, Decay: 10}
+
+	tests := []struct {
+		strategy string
+		solves   int
+		want     int
+	}{
+		{ScoringStatic, 50, 500},
+		{ScoringLinear, 5, 300},
+		{ScoringDynamic, 5, 400},
+		{ScoringLogarithmic, 0, 500},
+		{ScoringLogarithmic, 1, 384},
+		{ScoringLogarithmic, 5, 201},
+		{ScoringLogarithmic, 10, 100},
+		{ScoringLogarithmic, 100, 100},
+		// CTFd: the first solver keeps the initial value and values are rounded up
+		{ScoringCTFd, 0, 500},
+		{ScoringCTFd, 1, 500},
+		{ScoringCTFd, 2, 496},
+		{ScoringCTFd, 4, 464},
+		{ScoringCTFd, 11, 100},
+		{ScoringCTFd, 50, 100},
+	}
+
+	for _, tt := range tests {
+		if got := ScoringStrategyFor(tt.strategy).Points(p, tt.solves); got != tt.want {
+			t.Errorf("%s with %d solves = %d, want %d", tt.strategy, tt.solves, got, tt.want)
+		}
+	}
+}
+
+func TestCTFdScoringRoundsUp(t *testing.T) {
+	// Two solves: (100-487)/9^2 * 1^2 + 487 = 482.22, which CTFd rounds up to 483
+	p := ScoringParams{MaxPoints: 487, MinPoints: 100, Decay: 9}
+	if got := ScoringStrategyFor(ScoringCTFd).Points(p, 2); got != 483 {
+		t.Errorf("Points = %d, want 483", got)
+	}
+	// CTFd treats a decay of 0 as 1, so the value drops to the minimum at the second solve
+	p.Decay = 0
+	if got := ScoringStrategyFor(ScoringCTFd).Points(p, 2); got != 100 {
+		t.Errorf("Points with zero decay = %d, want 100", got)
+	}
+}
+
+func TestScoringStrategyFallback(t *testing.T) {
+	if got := ScoringStrategyFor("unknown").Name(); got != ScoringDynamic {
+		t.Errorf("ScoringStrategyFor(unknown) = %q, want %q", got, ScoringDynamic)
+	}
+	if IsValidScoringType("unknown") || !IsValidScoringType(ScoringCTFd) {
+		t.Error("IsValidScoringType does not follow the registry")
+	}
+	if n := len(ScoringStrategies()); n != 5 {
+		t.Errorf("ScoringStrategies() has %d entries, want 5", n)
+	}
+}
+
+func TestPointsInContest(t *testing.T) {
+	ch := Challenge{MaxPoints: 500, MinPoints: 100, Decay: 10, ScoringType: ScoringStatic}
+	if got := ch.PointsInContest("", 5); got != 500 {
+		t.Errorf("PointsInContest without override = %d, want 500", got)
+	}
+	if got := ch.PointsInContest(ScoringLinear, 5); got != 300 {
+		t.Errorf("PointsInContest with linear override = %d, want 300", got)
+	}
+}
+
+func TestPointsCurve(t *testing.T) {
+	curve := PointsCurve(ScoringStrategyFor(ScoringLinear), ScoringParams{MaxPoints: 100, MinPoints: 0, Decay: 4}, 5)
+	want := []int{100, 75, 50, 25, 0, 0}
+	if len(curve) != len(want) {
+		t.Fatalf("curve has %d points, want %d", len(curve), len(want))
+	}
+	for i, pt := range curve {
+		if pt.Solves != i || pt.Points != want[i] {
+			t.Errorf("curve[%d] = %+v, want {%d %d}", i, pt, i, want[i])
+		}
+	}
+}
+
+func TestValidateScoringParams(t *testing.T) {
+	if err := ValidateScoringParams(ScoringParams{MaxPoints: 100, MinPoints: 200}); err == nil {
+		t.Error("min above max accepted")
+	}
+	if err := ValidateScoringParams(ScoringParams{MaxPoints: 100, Decay: -1}); err == nil {
+		t.Error("negative decay accepted")
+	}
+	if err := ValidateScoringParams(ScoringParams{MaxPoints: 100, MinPoints: 10, Decay: 5}); err != nil {
+		t.Errorf("valid params rejected: %v", err)
+	}
+}
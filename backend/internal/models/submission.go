@@ -3,13 +3,22 @@ package models
 import "time"
 
 type Submission struct {
-	ID          string    `json:"id"`
-	UserID      string    `json:"user_id"`
-	TeamID      string    `json:"team_id,omitempty"`
-	ChallengeID string    `json:"challenge_id"`
-	ContestID   string    `json:"contest_id,omitempty"`
-	Flag        string    `json:"flag"`
-	IsCorrect   bool      `json:"is_correct"`
-	IPAddress   string    `json:"ip_address,omitempty"`
-	Timestamp   time.Time `json:"timestamp"`
+	ID            string    `json:"id"`
+	UserID        string    `json:"user_id"`
+	TeamID        string    `json:"team_id,omitempty"`
+	ChallengeID   string    `json:"challenge_id"`
+	ContestID     string    `json:"contest_id,omitempty"`
+	Flag          string    `json:"flag"`
+	IsCorrect     bool      `json:"is_correct"`
+	InvalidFormat bool      `json:"invalid_format,omitempty"` // rejected for its format; logged but not an attempt
+	IPAddress     string    `json:"ip_address,omitempty"`
+	Timestamp     time.Time `json:"timestamp"`
+	// Set when an admin invalidated the solve, e.g. for cheating. Invalidated submissions
+	// no longer count as solves anywhere until they are restored.
+	InvalidatedAt      *time.Time `json:"invalidated_at,omitempty"`
+	InvalidatedBy      string     `json:"invalidated_by,omitempty"`
+	InvalidationReason string     `json:"invalidation_reason,omitempty"`
 }
+
+// MaxInvalidationReasonLength limits the reason an admin gives for invalidating a submission
+const MaxInvalidationReasonLength = 1000
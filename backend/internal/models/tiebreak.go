@@ -0,0 +1,122 @@
+package models
+
+import "time"
+
+// Scoreboard tie-breaking rules, ordering entries with equal scores. Entries the rule
+// cannot tell apart are ordered by name.
+const (
+	TieBreakEarliest     = "earliest"     // the first to reach the score ranks higher
+	TieBreakFewerHints   = "fewer_hints"  // fewer revealed hints ranks higher
+	TieBreakFewerWrong   = "fewer_wrong"  // fewer wrong submissions ranks higher
+	TieBreakAlphabetical = "alphabetical" // by name, the default
+)
+
+func IsValidTieBreaker(rule string) bool {
+	switch rule {
+	case "", TieBreakEarliest, TieBreakFewerHints, TieBreakFewerWrong, TieBreakAlphabetical:
+		return true
+	}
+	return false
+}
+
+// TieBreakerOrDefault returns the rule a contest's scoreboards use
+func TieBreakerOrDefault(rule string) string {
+	if rule == "" {
+		return TieBreakAlphabetical
+	}
+	return rule
+}
+
+// TieBreakStats is what the tie-breaking rules compare for one scoreboard entry
+type TieBreakStats struct {
+	ReachedAt        time.Time // last change of score it earned; revaluations of its solves do not count
+	HintsUsed        int
+	WrongSubmissions int
+}
+
+// TieBreak is the tie-breaker metadata shown with a scoreboard entry: the rule and the
+// value it compared
+type TieBreak struct {
+	Rule             string     `json:"rule"`
+	ReachedAt        *time.Time `json:"reached_at,omitempty"`
+	HintsUsed        *int       `json:"hints_used,omitempty"`
+	WrongSubmissions *int       `json:"wrong_submissions,omitempty"`
+}
+
+// TieBreak returns the metadata of the stats under rule
+func (s TieBreakStats) TieBreak(rule string) *TieBreak {
+	rule = TieBreakerOrDefault(rule)
+	tb := &TieBreak{Rule: rule}
+	switch rule {
+	case TieBreakEarliest:
+		if !s.ReachedAt.IsZero() {
+			reachedAt := s.ReachedAt
+			tb.ReachedAt = &reachedAt
+		}
+	case TieBreakFewerHints:
+		hints := s.HintsUsed
+		tb.HintsUsed = &hints
+	case TieBreakFewerWrong:
+		wrong := s.WrongSubmissions
+		tb.WrongSubmissions = &wrong
+	}
+	return tb
+}
+
+// CompareTieBreak orders two entries with equal scores under rule: negative when a ranks
+// first, positive when b does, and zero when the rule cannot tell them apart. Under
+// TieBreakEarliest an entry that never scored ranks last.
+func CompareTieBreak(rule string, a, b TieBreakStats) int {
+	switch TieBreakerOrDefault(rule) {
+	case TieBreakEarliest:
+		switch {
+		case a.ReachedAt.Equal(b.ReachedAt):
+			return 0
+		case b.ReachedAt.IsZero(), !a.ReachedAt.IsZero() && a.ReachedAt.Before(b.ReachedAt):
+			return -1
+		default:
+			return 1
+		}
+	case TieBreakFewerHints:
+		return a.HintsUsed - b.HintsUsed
+	case TieBreakFewerWrong:
+		return a.WrongSubmissions - b.WrongSubmissions
+	}
+	return 0
+}
+
+// CollectTieBreakStats gathers tie-breaking stats per scoreboard entry from a contest's
+// ledger entries, hint reveals and attempts. key maps an event's team and user to the
+// entry it counts for, "" to skip it.
+func CollectTieBreakStats(entries []ScoreLedgerEntry, reveals []HintReveal, attempts []Submission, key func(teamID, userID string) string) map[string]TieBreakStats {
+	stats := make(map[string]TieBreakStats)
+	for _, e := range entries {
+		k := key(e.TeamID, e.UserID)
+		if k == "" || e.Kind == LedgerRevaluation {
+			continue
+		}
+		s := stats[k]
+		if e.CreatedAt.After(s.ReachedAt) {
+			s.ReachedAt = e.CreatedAt
+		}
+		stats[k] = s
+	}
+	for _, r := range reveals {
+		if k := key(r.TeamID, r.UserID); k != "" {
+			s := stats[k]
+			s.HintsUsed++
+			stats[k] = s
+		}
+	}
+	for _, sub := range attempts {
+		if sub.IsCorrect || sub.InvalidFormat {
+			continue
+		}
+		if k := key(sub.TeamID, sub.UserID); k != "" {
+			s := stats[k]
+			s.WrongSubmissions++
+			stats[k] = s
+		}
+	}
+	return stats
+}
@@ -0,0 +1,82 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCompareTieBreak(t *testing.T) {
+	early := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	late := early.Add(time.Hour)
+
+	tests := []struct {
+		name string
+		rule string
+		a, b TieBreakStats
+		want int // sign only
+	}{
+		{"earliest ranks the first to reach the score", TieBreakEarliest, TieBreakStats{ReachedAt: early}, TieBreakStats{ReachedAt: late}, -1},
+		{"earliest ranks never scoring last", TieBreakEarliest, TieBreakStats{}, TieBreakStats{ReachedAt: late}, 1},
+		{"earliest ties on the same time", TieBreakEarliest, TieBreakStats{ReachedAt: early}, TieBreakStats{ReachedAt: early}, 0},
+		{"fewer hints", TieBreakFewerHints, TieBreakStats{HintsUsed: 3}, TieBreakStats{HintsUsed: 1}, 1},
+		{"fewer wrong submissions", TieBreakFewerWrong, TieBreakStats{WrongSubmissions: 2, HintsUsed: 9}, TieBreakStats{WrongSubmissions: 5}, -1},
+		{"alphabetical leaves it to names", TieBreakAlphabetical, TieBreakStats{ReachedAt: early}, TieBreakStats{ReachedAt: late}, 0},
+		{"no rule is alphabetical", "", TieBreakStats{HintsUsed: 1}, TieBreakStats{}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := CompareTieBreak(tt.rule, tt.a, tt.b)
+			if (got < 0) != (tt.want < 0) || (got > 0) != (tt.want > 0) {
+				t.Errorf("CompareTieBreak() = %d, want sign of %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCollectTieBreakStats(t *testing.T) {
+	at := func(minutes int) time.Time {
+		return time.Date(2026, 3, 1, 10, minutes, 0, 0, time.UTC)
+	}
+	byTeam := func(teamID, userID string) string { return teamID }
+
+	stats := CollectTieBreakStats(
+		[]ScoreLedgerEntry{
+			{TeamID: "t1", Kind: LedgerSolve, Points: 100, CreatedAt: at(5)},
+			{TeamID: "t1", Kind: LedgerHint, Points: -10, CreatedAt: at(20)},
+			{TeamID: "t1", Kind: LedgerRevaluation, Points: -5, CreatedAt: at(40)},
+			{UserID: "u9", Kind: LedgerAdjustment, Points: 5, CreatedAt: at(50)},
+		},
+		[]HintReveal{{TeamID: "t1"}, {TeamID: "t2"}, {TeamID: "t1"}},
+		[]Submission{
+			{TeamID: "t1", IsCorrect: true},
+			{TeamID: "t1"},
+			{TeamID: "t2"},
+			{TeamID: "t2"},
+			{TeamID: "t2", InvalidFormat: true},
+		},
+		byTeam,
+	)
+
+	if got := stats["t1"]; !got.ReachedAt.Equal(at(20)) || got.HintsUsed != 2 || got.WrongSubmissions != 1 {
+		t.Errorf("t1 = %+v, want reached at 10:20 (revaluations ignored), 2 hints, 1 wrong", got)
+	}
+	if got := stats["t2"]; !got.ReachedAt.IsZero() || got.HintsUsed != 1 || got.WrongSubmissions != 2 {
+		t.Errorf("t2 = %+v, want never scored, 1 hint, 2 wrong", got)
+	}
+	if len(stats) != 2 {
+		t.Errorf("got %d entries, want 2 (events without a team skipped)", len(stats))
+	}
+}
+
+func TestTieBreakStatsTieBreak(t *testing.T) {
+	s := TieBreakStats{HintsUsed: 0, WrongSubmissions: 4}
+	if tb := s.TieBreak(TieBreakFewerHints); tb.HintsUsed == nil || *tb.HintsUsed != 0 || tb.WrongSubmissions != nil {
+		t.Errorf("fewer_hints metadata = %+v", tb)
+	}
+	if tb := s.TieBreak(""); tb.Rule != TieBreakAlphabetical || tb.ReachedAt != nil || tb.HintsUsed != nil {
+		t.Errorf("default metadata = %+v", tb)
+	}
+	if tb := s.TieBreak(TieBreakEarliest); tb.ReachedAt != nil {
+		t.Errorf("earliest metadata without a score = %+v", tb)
+	}
+}
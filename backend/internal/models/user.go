@@ -2,6 +2,13 @@ package models
 
 import "time"
 
+// User roles. Authors can create challenges and manage the ones they own, nothing else.
+const (
+	RoleUser   = "user"
+	RoleAuthor = "author"
+	RoleAdmin  = "admin"
+)
+
 type User struct {
 	ID                  string    `json:"id"`
 	Username            string    `json:"username"`
@@ -32,3 +39,7 @@ type IPRecord struct {
 	Timestamp time.Time `json:"timestamp"`
 	Action    string    `json:"action,omitempty"`
 }
+
+func IsValidRole(role string) bool {
+	return role == RoleUser || role == RoleAuthor || role == RoleAdmin
+}
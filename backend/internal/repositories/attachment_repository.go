@@ -0,0 +1,80 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/Uttam-Mahata/RootAccess/backend/internal/models"
+	"github.com/google/uuid"
+)
+
+type AttachmentRepository struct {
+	db *sql.DB
+}
+
+func NewAttachmentRepository(db *sql.DB) *AttachmentRepository {
+	return &AttachmentRepository{db: db}
+}
+
+func (r *AttachmentRepository) Create(attachment *models.ChallengeAttachment) error {
+	if attachment.ID == "" {
+		attachment.ID = uuid.New().String()
+	}
+	attachment.CreatedAt = time.Now()
+
+	query := `INSERT INTO challenge_attachments (id, challenge_id, filename, storage_key, content_type, size, sha256, uploaded_by, created_at)
+			  VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	_, err := r.db.Exec(query, attachment.ID, attachment.ChallengeID, attachment.Filename, attachment.StorageKey,
+		attachment.ContentType, attachment.Size, attachment.SHA256, attachment.UploadedBy, attachment.CreatedAt.Format(time.RFC3339))
+	return err
+}
+
+func (r *AttachmentRepository) scanAttachments(rows *sql.Rows) ([]models.ChallengeAttachment, error) {
+	attachments := []models.ChallengeAttachment{}
+	for rows.Next() {
+		var a models.ChallengeAttachment
+		var contentType, uploadedBy sql.NullString
+		var created string
+		if err := rows.Scan(&a.ID, &a.ChallengeID, &a.Filename, &a.StorageKey, &contentType, &a.Size, &a.SHA256, &uploadedBy, &created); err != nil {
+			return nil, err
+		}
+		a.ContentType = contentType.String
+		a.UploadedBy = uploadedBy.String
+		a.CreatedAt, _ = time.Parse(time.RFC3339, created)
+		attachments = append(attachments, a)
+	}
+	return attachments, nil
+}
+
+func (r *AttachmentRepository) GetByChallenge(challengeID string) ([]models.ChallengeAttachment, error) {
+	rows, err := r.db.Query(`SELECT id, challenge_id, filename, storage_key, content_type, size, sha256, uploaded_by, created_at
+							 FROM challenge_attachments WHERE challenge_id=? ORDER BY created_at`, challengeID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return r.scanAttachments(rows)
+}
+
+func (r *AttachmentRepository) FindByID(id string) (*models.ChallengeAttachment, error) {
+	rows, err := r.db.Query(`SELECT id, challenge_id, filename, storage_key, content_type, size, sha256, uploaded_by, created_at
+							 FROM challenge_attachments WHERE id=?`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	attachments, err := r.scanAttachments(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(attachments) == 0 {
+		return nil, errors.New("attachment not found")
+	}
+	return &attachments[0], nil
+}
+
+func (r *AttachmentRepository) Delete(id string) error {
+	_, err := r.db.Exec("DELETE FROM challenge_attachments WHERE id=?", id)
+	return err
+}
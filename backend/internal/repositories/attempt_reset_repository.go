@@ -0,0 +1,31 @@
+package repositories
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/Uttam-Mahata/RootAccess/backend/internal/models"
+	"github.com/google/uuid"
+)
+
+// AttemptResetRepository records resets of per-challenge wrong-attempt counters
+type AttemptResetRepository struct {
+	db *sql.DB
+}
+
+func NewAttemptResetRepository(db *sql.DB) *AttemptResetRepository {
+	return &AttemptResetRepository{db: db}
+}
+
+func (r *AttemptResetRepository) Create(reset *models.AttemptReset) error {
+	if reset.ID == "" {
+		reset.ID = uuid.New().String()
+	}
+	reset.CreatedAt = time.Now()
+
+	_, err := r.db.Exec(`INSERT INTO attempt_resets (id, challenge_id, owner_id, contest_id, reset_by, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)`,
+		reset.ID, reset.ChallengeID, reset.OwnerID, reset.ContestID, reset.ResetBy,
+		reset.CreatedAt.Format(time.RFC3339))
+	return err
+}
@@ -0,0 +1,115 @@
+package repositories
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/Uttam-Mahata/RootAccess/backend/internal/models"
+	"github.com/google/uuid"
+)
+
+type ChallengeFeedbackRepository struct {
+	db *sql.DB
+}
+
+func NewChallengeFeedbackRepository(db *sql.DB) *ChallengeFeedbackRepository {
+	return &ChallengeFeedbackRepository{db: db}
+}
+
+// Upsert stores a user's feedback for a challenge, replacing any earlier entry
+func (r *ChallengeFeedbackRepository) Upsert(f *models.ChallengeFeedback) error {
+	if f.ID == "" {
+		f.ID = uuid.New().String()
+	}
+	now := time.Now()
+	f.CreatedAt = now
+	f.UpdatedAt = now
+
+	query := `INSERT INTO challenge_feedback (id, challenge_id, user_id, team_id, contest_id, difficulty_rating, quality_rating, comment, created_at, updated_at)
+			  VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			  ON CONFLICT(challenge_id, user_id) DO UPDATE SET
+				team_id=excluded.team_id, contest_id=excluded.contest_id,
+				difficulty_rating=excluded.difficulty_rating, quality_rating=excluded.quality_rating,
+				comment=excluded.comment, updated_at=excluded.updated_at`
+	_, err := r.db.Exec(query, f.ID, f.ChallengeID, f.UserID, f.TeamID, f.ContestID,
+		f.DifficultyRating, f.QualityRating, f.Comment, now.Format(time.RFC3339), now.Format(time.RFC3339))
+	if err != nil {
+		return err
+	}
+
+	// Read back the stored ID and creation time when an existing entry was updated
+	stored, err := r.FindByUserAndChallenge(f.UserID, f.ChallengeID)
+	if err != nil {
+		return err
+	}
+	f.ID = stored.ID
+	f.CreatedAt = stored.CreatedAt
+	return nil
+}
+
+const challengeFeedbackSelect = `SELECT f.id, f.challenge_id, f.user_id, u.username, f.team_id, f.contest_id,
+	f.difficulty_rating, f.quality_rating, f.comment, f.created_at, f.updated_at
+	FROM challenge_feedback f LEFT JOIN users u ON u.id = f.user_id`
+
+func (r *ChallengeFeedbackRepository) scanFeedback(rows *sql.Rows) ([]models.ChallengeFeedback, error) {
+	feedback := []models.ChallengeFeedback{}
+	for rows.Next() {
+		var f models.ChallengeFeedback
+		var username, teamID, contestID, comment sql.NullString
+		var created, updated string
+		if err := rows.Scan(&f.ID, &f.ChallengeID, &f.UserID, &username, &teamID, &contestID,
+			&f.DifficultyRating, &f.QualityRating, &comment, &created, &updated); err != nil {
+			return nil, err
+		}
+		f.Username = username.String
+		f.TeamID = teamID.String
+		f.ContestID = contestID.String
+		f.Comment = comment.String
+		f.CreatedAt, _ = time.Parse(time.RFC3339, created)
+		f.UpdatedAt, _ = time.Parse(time.RFC3339, updated)
+		feedback = append(feedback, f)
+	}
+	return feedback, nil
+}
+
+func (r *ChallengeFeedbackRepository) FindByUserAndChallenge(userID, challengeID string) (*models.ChallengeFeedback, error) {
+	rows, err := r.db.Query(challengeFeedbackSelect+" WHERE f.user_id=? AND f.challenge_id=?", userID, challengeID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	feedback, err := r.scanFeedback(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(feedback) == 0 {
+		return nil, sql.ErrNoRows
+	}
+	return &feedback[0], nil
+}
+
+// GetByChallenge returns all feedback for a challenge, newest first
+func (r *ChallengeFeedbackRepository) GetByChallenge(challengeID string) ([]models.ChallengeFeedback, error) {
+	rows, err := r.db.Query(challengeFeedbackSelect+" WHERE f.challenge_id=? ORDER BY f.updated_at DESC", challengeID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return r.scanFeedback(rows)
+}
+
+// GetAll returns all feedback. When contestID is set, only feedback from solves in that contest.
+func (r *ChallengeFeedbackRepository) GetAll(contestID string) ([]models.ChallengeFeedback, error) {
+	query := challengeFeedbackSelect
+	var args []interface{}
+	if contestID != "" {
+		query += " WHERE f.contest_id=?"
+		args = append(args, contestID)
+	}
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return r.scanFeedback(rows)
+}
@@ -24,9 +24,17 @@ func (r *ChallengeRepository) CreateChallenge(challenge *models.Challenge) error
 		challenge.ID = uuid.New().String()
 	}
 	challenge.SolveCount = 0
+	if challenge.Status == "" {
+		challenge.Status = models.ChallengeStatusDraft
+	}
+	if challenge.GradingMode == "" {
+		challenge.GradingMode = models.GradingAuto
+	}
+	challenge.IsPublished = challenge.Status == models.ChallengeStatusPublished
 
 	filesJSON, _ := json.Marshal(challenge.Files)
 	tagsJSON, _ := json.Marshal(challenge.Tags)
+	bonusJSON, _ := json.Marshal(challenge.FirstBloodBonus)
 
 	tx, err := r.db.Begin()
 	if err != nil {
@@ -39,8 +47,9 @@ func (r *ChallengeRepository) CreateChallenge(challenge *models.Challenge) error
 			id, title, description, description_format, category, difficulty,
 			max_points, min_points, decay, scoring_type, solve_count, flag_hash,
 			files, tags, scheduled_at, is_published, contest_id, official_writeup,
-			official_writeup_format, official_writeup_published
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			official_writeup_format, official_writeup_published, status, author_id, first_blood_bonus,
+			max_attempts, grading_mode, flag_format
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
 	isPublished := 0
@@ -59,7 +68,8 @@ func (r *ChallengeRepository) CreateChallenge(challenge *models.Challenge) error
 		challenge.Decay, challenge.ScoringType, challenge.SolveCount, challenge.FlagHash,
 		string(filesJSON), string(tagsJSON), challenge.ScheduledAt, isPublished,
 		challenge.ContestID, challenge.OfficialWriteup, challenge.OfficialWriteupFormat,
-		owPublished,
+		owPublished, challenge.Status, challenge.AuthorID, string(bonusJSON),
+		challenge.MaxAttempts, challenge.GradingMode, challenge.FlagFormat,
 	)
 	if err != nil {
 		return err
@@ -77,13 +87,58 @@ func (r *ChallengeRepository) CreateChallenge(challenge *models.Challenge) error
 		}
 	}
 
+	if err := r.insertFlags(tx, challenge.ID, challenge.Flags); err != nil {
+		return err
+	}
+
+	if err := r.insertPrerequisites(tx, challenge.ID, challenge.Prerequisites); err != nil {
+		return err
+	}
+
 	return tx.Commit()
 }
 
+// insertFlags stores the accepted flag entries for a challenge inside an open transaction
+func (r *ChallengeRepository) insertFlags(tx *sql.Tx, challengeID string, flags []models.ChallengeFlag) error {
+	for i := range flags {
+		if flags[i].ID == "" {
+			flags[i].ID = uuid.New().String()
+		}
+		flags[i].ChallengeID = challengeID
+		_, err := tx.Exec("INSERT INTO challenge_flags (id, challenge_id, flag_type, value, display_order) VALUES (?, ?, ?, ?, ?)",
+			flags[i].ID, challengeID, flags[i].Type, flags[i].Value, i)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// insertPrerequisites stores the unlock rules for a challenge inside an open transaction
+func (r *ChallengeRepository) insertPrerequisites(tx *sql.Tx, challengeID string, prereqs []models.ChallengePrerequisite) error {
+	for i := range prereqs {
+		if prereqs[i].ID == "" {
+			prereqs[i].ID = uuid.New().String()
+		}
+		prereqs[i].ChallengeID = challengeID
+		var requiredID interface{}
+		if prereqs[i].RequiredChallengeID != "" {
+			requiredID = prereqs[i].RequiredChallengeID
+		}
+		_, err := tx.Exec("INSERT INTO challenge_prerequisites (id, challenge_id, prerequisite_type, required_challenge_id, category, required_count) VALUES (?, ?, ?, ?, ?, ?)",
+			prereqs[i].ID, challengeID, prereqs[i].Type, requiredID, prereqs[i].Category, prereqs[i].Count)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (r *ChallengeRepository) scanChallenge(row *sql.Row) (*models.Challenge, error) {
 	var c models.Challenge
 	var filesJSON, tagsJSON string
 	var isPub, owPub int
+	var authorID, bonusJSON, flagFormat sql.NullString
 
 	err := row.Scan(
 		&c.ID, &c.Title, &c.Description, &c.DescriptionFormat,
@@ -91,7 +146,7 @@ func (r *ChallengeRepository) scanChallenge(row *sql.Row) (*models.Challenge, er
 		&c.Decay, &c.ScoringType, &c.SolveCount, &c.FlagHash,
 		&filesJSON, &tagsJSON, &c.ScheduledAt, &isPub,
 		&c.ContestID, &c.OfficialWriteup, &c.OfficialWriteupFormat,
-		&owPub,
+		&owPub, &c.Status, &authorID, &bonusJSON, &c.MaxAttempts, &c.GradingMode, &flagFormat,
 	)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -102,14 +157,21 @@ func (r *ChallengeRepository) scanChallenge(row *sql.Row) (*models.Challenge, er
 
 	c.IsPublished = isPub == 1
 	c.OfficialWriteupPublished = owPub == 1
+	c.AuthorID = authorID.String
+	c.FlagFormat = flagFormat.String
 	if filesJSON != "" {
 		json.Unmarshal([]byte(filesJSON), &c.Files)
 	}
 	if tagsJSON != "" {
 		json.Unmarshal([]byte(tagsJSON), &c.Tags)
 	}
+	if bonusJSON.String != "" {
+		json.Unmarshal([]byte(bonusJSON.String), &c.FirstBloodBonus)
+	}
 
 	c.Hints, _ = r.getHints(c.ID)
+	c.Flags, _ = r.getFlags(c.ID)
+	c.Prerequisites, _ = r.getPrerequisites(c.ID)
 	return &c, nil
 }
 
@@ -119,6 +181,7 @@ func (r *ChallengeRepository) scanChallenges(rows *sql.Rows) ([]models.Challenge
 		var c models.Challenge
 		var filesJSON, tagsJSON string
 		var isPub, owPub int
+		var authorID, bonusJSON, flagFormat sql.NullString
 
 		if err := rows.Scan(
 			&c.ID, &c.Title, &c.Description, &c.DescriptionFormat,
@@ -126,21 +189,28 @@ func (r *ChallengeRepository) scanChallenges(rows *sql.Rows) ([]models.Challenge
 			&c.Decay, &c.ScoringType, &c.SolveCount, &c.FlagHash,
 			&filesJSON, &tagsJSON, &c.ScheduledAt, &isPub,
 			&c.ContestID, &c.OfficialWriteup, &c.OfficialWriteupFormat,
-			&owPub,
+			&owPub, &c.Status, &authorID, &bonusJSON, &c.MaxAttempts, &c.GradingMode, &flagFormat,
 		); err != nil {
 			return nil, err
 		}
 
 		c.IsPublished = isPub == 1
 		c.OfficialWriteupPublished = owPub == 1
+		c.AuthorID = authorID.String
+		c.FlagFormat = flagFormat.String
 		if filesJSON != "" {
 			json.Unmarshal([]byte(filesJSON), &c.Files)
 		}
 		if tagsJSON != "" {
 			json.Unmarshal([]byte(tagsJSON), &c.Tags)
 		}
+		if bonusJSON.String != "" {
+			json.Unmarshal([]byte(bonusJSON.String), &c.FirstBloodBonus)
+		}
 
 		c.Hints, _ = r.getHints(c.ID)
+		c.Flags, _ = r.getFlags(c.ID)
+		c.Prerequisites, _ = r.getPrerequisites(c.ID)
 		challenges = append(challenges, c)
 	}
 	return challenges, nil
@@ -150,7 +220,8 @@ func (r *ChallengeRepository) selectChallengeFields() string {
 	return `id, title, description, description_format, category, difficulty,
 			max_points, min_points, decay, scoring_type, solve_count, flag_hash,
 			files, tags, scheduled_at, is_published, contest_id, official_writeup,
-			official_writeup_format, official_writeup_published`
+			official_writeup_format, official_writeup_published, status, author_id, first_blood_bonus,
+			max_attempts, grading_mode, flag_format`
 }
 
 func (r *ChallengeRepository) getHints(challengeID string) ([]models.Hint, error) {
@@ -172,6 +243,69 @@ func (r *ChallengeRepository) getHints(challengeID string) ([]models.Hint, error
 	return hints, nil
 }
 
+func (r *ChallengeRepository) getFlags(challengeID string) ([]models.ChallengeFlag, error) {
+	rows, err := r.db.Query("SELECT id, flag_type, value FROM challenge_flags WHERE challenge_id=? ORDER BY display_order", challengeID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var flags []models.ChallengeFlag
+	for rows.Next() {
+		var f models.ChallengeFlag
+		if err := rows.Scan(&f.ID, &f.Type, &f.Value); err != nil {
+			return nil, err
+		}
+		f.ChallengeID = challengeID
+		flags = append(flags, f)
+	}
+	return flags, nil
+}
+
+func (r *ChallengeRepository) getPrerequisites(challengeID string) ([]models.ChallengePrerequisite, error) {
+	prereqs, err := r.GetPrerequisitesForChallenges([]string{challengeID})
+	if err != nil {
+		return nil, err
+	}
+	return prereqs[challengeID], nil
+}
+
+// GetPrerequisitesForChallenges returns the unlock rules of the given challenges keyed by challenge ID.
+// Challenges without rules are absent from the map.
+func (r *ChallengeRepository) GetPrerequisitesForChallenges(ids []string) (map[string][]models.ChallengePrerequisite, error) {
+	result := make(map[string][]models.ChallengePrerequisite)
+	if len(ids) == 0 {
+		return result, nil
+	}
+
+	placeholders := make([]string, len(ids))
+	args := make([]interface{}, len(ids))
+	for i, id := range ids {
+		placeholders[i] = "?"
+		args[i] = id
+	}
+
+	query := fmt.Sprintf(`SELECT id, challenge_id, prerequisite_type, required_challenge_id, category, required_count
+						  FROM challenge_prerequisites WHERE challenge_id IN (%s) ORDER BY rowid`, strings.Join(placeholders, ","))
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var p models.ChallengePrerequisite
+		var requiredID, category sql.NullString
+		if err := rows.Scan(&p.ID, &p.ChallengeID, &p.Type, &requiredID, &category, &p.Count); err != nil {
+			return nil, err
+		}
+		p.RequiredChallengeID = requiredID.String
+		p.Category = category.String
+		result[p.ChallengeID] = append(result[p.ChallengeID], p)
+	}
+	return result, nil
+}
+
 func (r *ChallengeRepository) GetAllChallenges() ([]models.Challenge, error) {
 	query := fmt.Sprintf("SELECT %s FROM challenges", r.selectChallengeFields())
 	rows, err := r.db.Query(query)
@@ -186,7 +320,8 @@ func (r *ChallengeRepository) GetAllChallengesForList() ([]models.Challenge, err
 	query := `SELECT id, title, '', description_format, category, difficulty,
 			max_points, min_points, decay, scoring_type, solve_count, flag_hash,
 			files, tags, scheduled_at, is_published, contest_id, official_writeup,
-			official_writeup_format, official_writeup_published FROM challenges`
+			official_writeup_format, official_writeup_published, status, author_id, first_blood_bonus,
+			max_attempts, grading_mode, flag_format FROM challenges`
 	rows, err := r.db.Query(query)
 	if err != nil {
 		return nil, err
@@ -203,6 +338,7 @@ func (r *ChallengeRepository) GetChallengeByID(id string) (*models.Challenge, er
 func (r *ChallengeRepository) UpdateChallenge(id string, challenge *models.Challenge) error {
 	filesJSON, _ := json.Marshal(challenge.Files)
 	tagsJSON, _ := json.Marshal(challenge.Tags)
+	bonusJSON, _ := json.Marshal(challenge.FirstBloodBonus)
 
 	tx, err := r.db.Begin()
 	if err != nil {
@@ -214,13 +350,14 @@ func (r *ChallengeRepository) UpdateChallenge(id string, challenge *models.Chall
 		UPDATE challenges SET
 			title=?, description=?, description_format=?, category=?, difficulty=?,
 			max_points=?, min_points=?, decay=?, scoring_type=?, flag_hash=?,
-			files=?, tags=?
+			files=?, tags=?, scheduled_at=?, first_blood_bonus=?, max_attempts=?, grading_mode=?, flag_format=?
 		WHERE id=?
 	`
 	_, err = tx.Exec(query,
 		challenge.Title, challenge.Description, challenge.DescriptionFormat, challenge.Category,
 		challenge.Difficulty, challenge.MaxPoints, challenge.MinPoints, challenge.Decay,
-		challenge.ScoringType, challenge.FlagHash, string(filesJSON), string(tagsJSON), id,
+		challenge.ScoringType, challenge.FlagHash, string(filesJSON), string(tagsJSON),
+		challenge.ScheduledAt, string(bonusJSON), challenge.MaxAttempts, challenge.GradingMode, challenge.FlagFormat, id,
 	)
 	if err != nil {
 		return err
@@ -243,6 +380,24 @@ func (r *ChallengeRepository) UpdateChallenge(id string, challenge *models.Chall
 		}
 	}
 
+	_, err = tx.Exec("DELETE FROM challenge_flags WHERE challenge_id=?", id)
+	if err != nil {
+		return err
+	}
+
+	if err := r.insertFlags(tx, id, challenge.Flags); err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("DELETE FROM challenge_prerequisites WHERE challenge_id=?", id)
+	if err != nil {
+		return err
+	}
+
+	if err := r.insertPrerequisites(tx, id, challenge.Prerequisites); err != nil {
+		return err
+	}
+
 	return tx.Commit()
 }
 
@@ -258,6 +413,16 @@ func (r *ChallengeRepository) DeleteChallenge(id string) error {
 	queries := []string{
 		"DELETE FROM hint_reveals WHERE challenge_id=?",
 		"DELETE FROM hints WHERE challenge_id=?",
+		"DELETE FROM challenge_flags WHERE challenge_id=?",
+		"DELETE FROM cheating_incidents WHERE challenge_id=?",
+		"DELETE FROM challenge_attachments WHERE challenge_id=?",
+		"DELETE FROM challenge_reviews WHERE challenge_id=?",
+		"DELETE FROM challenge_feedback WHERE challenge_id=?",
+		"DELETE FROM challenge_revisions WHERE challenge_id=?",
+		"DELETE FROM attempt_resets WHERE challenge_id=?",
+		"DELETE FROM manual_submissions WHERE challenge_id=?",
+		"DELETE FROM near_misses WHERE challenge_id=?",
+		"DELETE FROM challenge_prerequisites WHERE ? IN (challenge_id, required_challenge_id)",
 		"DELETE FROM writeup_upvotes WHERE writeup_id IN (SELECT id FROM writeups WHERE challenge_id=?)",
 		"DELETE FROM writeups WHERE challenge_id=?",
 		"UPDATE achievements SET challenge_id=NULL WHERE challenge_id=?",
@@ -274,11 +439,41 @@ func (r *ChallengeRepository) DeleteChallenge(id string) error {
 	return tx.Commit()
 }
 
-func (r *ChallengeRepository) IncrementSolveCount(id string) error {
-	_, err := r.db.Exec("UPDATE challenges SET solve_count = solve_count + 1 WHERE id=?", id)
+// recountSolvesQuery rebuilds a challenge's solve count, taking the challenge ID twice
+const recountSolvesQuery = `UPDATE challenges SET solve_count = (
+		SELECT COUNT(DISTINCT COALESCE(contest_id, '') || '|' || ` + solveOwnerExpr + `)
+		FROM submissions WHERE challenge_id=? AND is_correct=1 AND invalidated_at IS NULL
+	) WHERE id=?`
+
+// RecountSolves rebuilds a challenge's solve count from its valid correct submissions.
+// A solve is a team (or a user without a team) solving the challenge, once per contest.
+func (r *ChallengeRepository) RecountSolves(id string) error {
+	_, err := r.db.Exec(recountSolvesQuery, id, id)
 	return err
 }
 
+// CountValidSolves counts every challenge's solves from its valid correct submissions, the
+// way RecountSolves does. Challenges without solves are left out.
+func (r *ChallengeRepository) CountValidSolves() (map[string]int, error) {
+	rows, err := r.db.Query(`SELECT challenge_id, COUNT(DISTINCT COALESCE(contest_id, '') || '|' || ` + solveOwnerExpr + `)
+		FROM submissions WHERE is_correct=1 AND invalidated_at IS NULL GROUP BY challenge_id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		var challengeID string
+		var count int
+		if err := rows.Scan(&challengeID, &count); err != nil {
+			return nil, err
+		}
+		counts[challengeID] = count
+	}
+	return counts, rows.Err()
+}
+
 func (r *ChallengeRepository) GetFlagHash(id string) (string, error) {
 	var hash string
 	err := r.db.QueryRow("SELECT flag_hash FROM challenges WHERE id=?", id).Scan(&hash)
@@ -316,6 +511,89 @@ func (r *ChallengeRepository) GetChallengesByIDs(ids []string, publishedOnly boo
 	return r.scanChallenges(rows)
 }
 
+// FilterPublishedIDs returns the subset of ids that belong to published challenges
+func (r *ChallengeRepository) FilterPublishedIDs(ids []string) ([]string, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	placeholders := make([]string, len(ids))
+	args := make([]interface{}, len(ids))
+	for i, id := range ids {
+		placeholders[i] = "?"
+		args[i] = id
+	}
+
+	query := fmt.Sprintf("SELECT id FROM challenges WHERE id IN (%s) AND is_published=1", strings.Join(placeholders, ","))
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var published []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		published = append(published, id)
+	}
+	return published, nil
+}
+
+// GetScheduledUnpublishedChallenges returns approved, unpublished challenges that have a scheduled_at set
+func (r *ChallengeRepository) GetScheduledUnpublishedChallenges() ([]models.Challenge, error) {
+	query := fmt.Sprintf("SELECT %s FROM challenges WHERE status=? AND scheduled_at IS NOT NULL AND scheduled_at != ''", r.selectChallengeFields())
+	rows, err := r.db.Query(query, models.ChallengeStatusApproved)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return r.scanChallenges(rows)
+}
+
+// PublishScheduledChallenge publishes a challenge if it is still approved and unpublished.
+// Returns false when another scheduler run already published it or it left the approved state.
+func (r *ChallengeRepository) PublishScheduledChallenge(id string) (bool, error) {
+	return r.TransitionStatus(id, models.ChallengeStatusApproved, models.ChallengeStatusPublished)
+}
+
+// TransitionStatus moves a challenge from one workflow state to another and keeps
+// is_published in step. Returns false when the challenge is no longer in the from state.
+func (r *ChallengeRepository) TransitionStatus(id, from, to string) (bool, error) {
+	isPublished := 0
+	if to == models.ChallengeStatusPublished {
+		isPublished = 1
+	}
+	result, err := r.db.Exec("UPDATE challenges SET status=?, is_published=? WHERE id=? AND status=?", to, isPublished, id, from)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+// ClearScheduledAt removes the release time of a challenge
+func (r *ChallengeRepository) ClearScheduledAt(id string) error {
+	_, err := r.db.Exec("UPDATE challenges SET scheduled_at='' WHERE id=?", id)
+	return err
+}
+
+// GetChallengesByStatus returns the challenges in a workflow state
+func (r *ChallengeRepository) GetChallengesByStatus(status string) ([]models.Challenge, error) {
+	query := fmt.Sprintf("SELECT %s FROM challenges WHERE status=?", r.selectChallengeFields())
+	rows, err := r.db.Query(query, status)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return r.scanChallenges(rows)
+}
+
 func (r *ChallengeRepository) UpdateOfficialWriteup(id string, content, format string) error {
 	_, err := r.db.Exec("UPDATE challenges SET official_writeup=?, official_writeup_format=? WHERE id=?", content, format, id)
 	return err
@@ -0,0 +1,73 @@
+package repositories
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/Uttam-Mahata/RootAccess/backend/internal/models"
+	"github.com/google/uuid"
+)
+
+// ChallengeReviewRepository stores the workflow transitions of challenges
+type ChallengeReviewRepository struct {
+	db *sql.DB
+}
+
+func NewChallengeReviewRepository(db *sql.DB) *ChallengeReviewRepository {
+	return &ChallengeReviewRepository{db: db}
+}
+
+func (r *ChallengeReviewRepository) Create(review *models.ChallengeReview) error {
+	if review.ID == "" {
+		review.ID = uuid.New().String()
+	}
+	review.CreatedAt = time.Now()
+
+	_, err := r.db.Exec(`INSERT INTO challenge_reviews (id, challenge_id, action, from_status, to_status, actor_id, comment, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		review.ID, review.ChallengeID, review.Action, review.FromStatus, review.ToStatus,
+		review.ActorID, review.Comment, review.CreatedAt.Format(time.RFC3339))
+	return err
+}
+
+func (r *ChallengeReviewRepository) scanReviews(rows *sql.Rows) ([]models.ChallengeReview, error) {
+	reviews := []models.ChallengeReview{}
+	for rows.Next() {
+		var rev models.ChallengeReview
+		var actorID, actorName, comment sql.NullString
+		var created string
+		if err := rows.Scan(&rev.ID, &rev.ChallengeID, &rev.Action, &rev.FromStatus, &rev.ToStatus,
+			&actorID, &actorName, &comment, &created); err != nil {
+			return nil, err
+		}
+		rev.ActorID = actorID.String
+		rev.ActorName = actorName.String
+		rev.Comment = comment.String
+		rev.CreatedAt, _ = time.Parse(time.RFC3339, created)
+		reviews = append(reviews, rev)
+	}
+	return reviews, nil
+}
+
+// GetByChallenge returns the workflow history of a challenge, oldest first
+func (r *ChallengeReviewRepository) GetByChallenge(challengeID string) ([]models.ChallengeReview, error) {
+	rows, err := r.db.Query(`SELECT cr.id, cr.challenge_id, cr.action, cr.from_status, cr.to_status, cr.actor_id, u.username, cr.comment, cr.created_at
+		FROM challenge_reviews cr LEFT JOIN users u ON u.id = cr.actor_id
+		WHERE cr.challenge_id=? ORDER BY cr.created_at ASC, cr.rowid ASC`, challengeID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return r.scanReviews(rows)
+}
+
+// FindLatestActor returns who last performed action on the challenge, or "" if nobody has
+func (r *ChallengeReviewRepository) FindLatestActor(challengeID, action string) (string, error) {
+	var actorID sql.NullString
+	err := r.db.QueryRow(`SELECT actor_id FROM challenge_reviews WHERE challenge_id=? AND action=?
+		ORDER BY created_at DESC, rowid DESC LIMIT 1`, challengeID, action).Scan(&actorID)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return actorID.String, err
+}
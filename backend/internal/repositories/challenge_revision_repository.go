@@ -0,0 +1,99 @@
+package repositories
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/Uttam-Mahata/RootAccess/backend/internal/models"
+	"github.com/google/uuid"
+)
+
+// ChallengeRevisionRepository stores challenge revisions. Revisions are append-only:
+// there are no update or delete methods.
+type ChallengeRevisionRepository struct {
+	db *sql.DB
+}
+
+func NewChallengeRevisionRepository(db *sql.DB) *ChallengeRevisionRepository {
+	return &ChallengeRevisionRepository{db: db}
+}
+
+// Create appends a revision, assigning the next revision number for the challenge
+func (r *ChallengeRevisionRepository) Create(rev *models.ChallengeRevision) error {
+	if rev.ID == "" {
+		rev.ID = uuid.New().String()
+	}
+	rev.CreatedAt = time.Now()
+
+	snapshotJSON, err := json.Marshal(rev.Snapshot)
+	if err != nil {
+		return err
+	}
+
+	query := `INSERT INTO challenge_revisions (id, challenge_id, revision_number, snapshot, author_id, note, created_at)
+			  SELECT ?, ?, COALESCE(MAX(revision_number), 0) + 1, ?, ?, ?, ? FROM challenge_revisions WHERE challenge_id=?`
+	if _, err := r.db.Exec(query, rev.ID, rev.ChallengeID, string(snapshotJSON), rev.AuthorID, rev.Note,
+		rev.CreatedAt.Format(time.RFC3339), rev.ChallengeID); err != nil {
+		return err
+	}
+
+	return r.db.QueryRow("SELECT revision_number FROM challenge_revisions WHERE id=?", rev.ID).Scan(&rev.Revision)
+}
+
+func (r *ChallengeRevisionRepository) scanRevisions(rows *sql.Rows) ([]models.ChallengeRevision, error) {
+	revisions := []models.ChallengeRevision{}
+	for rows.Next() {
+		var rev models.ChallengeRevision
+		var snapshotJSON, created string
+		var authorID, authorName, note sql.NullString
+		if err := rows.Scan(&rev.ID, &rev.ChallengeID, &rev.Revision, &snapshotJSON, &authorID, &authorName, &note, &created); err != nil {
+			return nil, err
+		}
+		rev.AuthorID = authorID.String
+		rev.AuthorName = authorName.String
+		rev.Note = note.String
+		rev.CreatedAt, _ = time.Parse(time.RFC3339, created)
+		if err := json.Unmarshal([]byte(snapshotJSON), &rev.Snapshot); err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, rev)
+	}
+	return revisions, nil
+}
+
+const challengeRevisionSelect = `SELECT cr.id, cr.challenge_id, cr.revision_number, cr.snapshot, cr.author_id, u.username, cr.note, cr.created_at
+	FROM challenge_revisions cr LEFT JOIN users u ON u.id = cr.author_id`
+
+// GetByChallenge returns all revisions of a challenge, newest first
+func (r *ChallengeRevisionRepository) GetByChallenge(challengeID string) ([]models.ChallengeRevision, error) {
+	rows, err := r.db.Query(challengeRevisionSelect+" WHERE cr.challenge_id=? ORDER BY cr.revision_number DESC", challengeID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return r.scanRevisions(rows)
+}
+
+func (r *ChallengeRevisionRepository) FindByNumber(challengeID string, revision int) (*models.ChallengeRevision, error) {
+	rows, err := r.db.Query(challengeRevisionSelect+" WHERE cr.challenge_id=? AND cr.revision_number=?", challengeID, revision)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	revisions, err := r.scanRevisions(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(revisions) == 0 {
+		return nil, errors.New("revision not found")
+	}
+	return &revisions[0], nil
+}
+
+func (r *ChallengeRevisionRepository) CountByChallenge(challengeID string) (int, error) {
+	var count int
+	err := r.db.QueryRow("SELECT COUNT(*) FROM challenge_revisions WHERE challenge_id=?", challengeID).Scan(&count)
+	return count, err
+}
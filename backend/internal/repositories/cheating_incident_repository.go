@@ -0,0 +1,129 @@
+package repositories
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/Uttam-Mahata/RootAccess/backend/internal/models"
+	"github.com/google/uuid"
+)
+
+type CheatingIncidentRepository struct {
+	db *sql.DB
+}
+
+func NewCheatingIncidentRepository(db *sql.DB) *CheatingIncidentRepository {
+	return &CheatingIncidentRepository{db: db}
+}
+
+const cheatingIncidentFields = `id, incident_type, challenge_id, contest_id, user_id, team_id, source_team_id, source_user_id,
+	submission_id, ip_address, details, status, reviewed_by, review_note, created_at, reviewed_at`
+
+func (r *CheatingIncidentRepository) Create(incident *models.CheatingIncident) error {
+	if incident.ID == "" {
+		incident.ID = uuid.New().String()
+	}
+	if incident.Status == "" {
+		incident.Status = models.CheatingIncidentStatusOpen
+	}
+	incident.CreatedAt = time.Now()
+
+	query := `INSERT INTO cheating_incidents (` + cheatingIncidentFields + `)
+			  VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	_, err := r.db.Exec(query, incident.ID, incident.Type, incident.ChallengeID, incident.ContestID, incident.UserID,
+		incident.TeamID, incident.SourceTeamID, incident.SourceUserID, incident.SubmissionID, incident.IPAddress, incident.Details, incident.Status,
+		incident.ReviewedBy, incident.ReviewNote, incident.CreatedAt.Format(time.RFC3339), incident.ReviewedAt)
+	return err
+}
+
+// List returns incidents newest first, optionally filtered by status
+func (r *CheatingIncidentRepository) List(status string, limit int, page int) ([]models.CheatingIncident, error) {
+	if limit <= 0 {
+		limit = 50
+	}
+	if page <= 0 {
+		page = 1
+	}
+	skip := (page - 1) * limit
+
+	query := `SELECT ` + cheatingIncidentFields + ` FROM cheating_incidents`
+	var args []interface{}
+	if status != "" {
+		query += ` WHERE status = ?`
+		args = append(args, status)
+	}
+	query += ` ORDER BY created_at DESC LIMIT ? OFFSET ?`
+	args = append(args, limit, skip)
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	incidents := []models.CheatingIncident{}
+	for rows.Next() {
+		incident, err := scanCheatingIncident(rows)
+		if err != nil {
+			return nil, err
+		}
+		incidents = append(incidents, *incident)
+	}
+	return incidents, nil
+}
+
+func (r *CheatingIncidentRepository) Count(status string) (int64, error) {
+	var count int64
+	var err error
+	if status != "" {
+		err = r.db.QueryRow("SELECT COUNT(*) FROM cheating_incidents WHERE status = ?", status).Scan(&count)
+	} else {
+		err = r.db.QueryRow("SELECT COUNT(*) FROM cheating_incidents").Scan(&count)
+	}
+	return count, err
+}
+
+func (r *CheatingIncidentRepository) FindByID(id string) (*models.CheatingIncident, error) {
+	row := r.db.QueryRow(`SELECT `+cheatingIncidentFields+` FROM cheating_incidents WHERE id = ?`, id)
+	return scanCheatingIncident(row)
+}
+
+// UpdateReview records an admin's decision on an incident
+func (r *CheatingIncidentRepository) UpdateReview(id, status, reviewedBy, note string) error {
+	result, err := r.db.Exec(`UPDATE cheating_incidents SET status = ?, reviewed_by = ?, review_note = ?, reviewed_at = ? WHERE id = ?`,
+		status, reviewedBy, note, time.Now().Format(time.RFC3339), id)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func scanCheatingIncident(scanner interface{ Scan(...any) error }) (*models.CheatingIncident, error) {
+	var incident models.CheatingIncident
+	var contestID, teamID, sourceTeamID, sourceUserID, submissionID, ipAddress, details, reviewedBy, reviewNote, reviewedAt sql.NullString
+	var createdAt string
+	err := scanner.Scan(&incident.ID, &incident.Type, &incident.ChallengeID, &contestID, &incident.UserID, &teamID,
+		&sourceTeamID, &sourceUserID, &submissionID, &ipAddress, &details, &incident.Status, &reviewedBy, &reviewNote, &createdAt, &reviewedAt)
+	if err != nil {
+		return nil, err
+	}
+	incident.ContestID = contestID.String
+	incident.TeamID = teamID.String
+	incident.SourceTeamID = sourceTeamID.String
+	incident.SourceUserID = sourceUserID.String
+	incident.SubmissionID = submissionID.String
+	incident.IPAddress = ipAddress.String
+	incident.Details = details.String
+	incident.ReviewedBy = reviewedBy.String
+	incident.ReviewNote = reviewNote.String
+	incident.ReviewedAt = reviewedAt.String
+	incident.CreatedAt, _ = time.Parse(time.RFC3339, createdAt)
+	return &incident, nil
+}
@@ -28,9 +28,9 @@ func (r *ContestEntityRepository) Create(c *models.Contest) error {
 		isActive = 1
 	}
 
-	_, err := r.db.Exec(`INSERT INTO contests (id, name, description, start_time, end_time, freeze_time, scoreboard_visibility, is_active, created_at, updated_at) 
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
-		c.ID, c.Name, c.Description, c.StartTime.Format(time.RFC3339), c.EndTime.Format(time.RFC3339), c.FreezeTime, c.ScoreboardVisibility, isActive, c.CreatedAt.Format(time.RFC3339), c.UpdatedAt.Format(time.RFC3339))
+	_, err := r.db.Exec(`INSERT INTO contests (id, name, description, start_time, end_time, freeze_time, scoreboard_visibility, is_active, created_at, updated_at, flag_format, scoring_type, ranking_mode, penalty_minutes, tie_breaker) 
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		c.ID, c.Name, c.Description, c.StartTime.Format(time.RFC3339), c.EndTime.Format(time.RFC3339), c.FreezeTime, c.ScoreboardVisibility, isActive, c.CreatedAt.Format(time.RFC3339), c.UpdatedAt.Format(time.RFC3339), c.FlagFormat, c.ScoringType, c.RankingMode, c.PenaltyMinutes, c.TieBreaker)
 	return err
 }
 
@@ -41,8 +41,8 @@ func (r *ContestEntityRepository) Update(c *models.Contest) error {
 		isActive = 1
 	}
 
-	_, err := r.db.Exec(`UPDATE contests SET name=?, description=?, start_time=?, end_time=?, freeze_time=?, scoreboard_visibility=?, is_active=?, updated_at=? WHERE id=?`,
-		c.Name, c.Description, c.StartTime.Format(time.RFC3339), c.EndTime.Format(time.RFC3339), c.FreezeTime, c.ScoreboardVisibility, isActive, c.UpdatedAt.Format(time.RFC3339), c.ID)
+	_, err := r.db.Exec(`UPDATE contests SET name=?, description=?, start_time=?, end_time=?, freeze_time=?, scoreboard_visibility=?, is_active=?, updated_at=?, flag_format=?, scoring_type=?, ranking_mode=?, penalty_minutes=?, tie_breaker=? WHERE id=?`,
+		c.Name, c.Description, c.StartTime.Format(time.RFC3339), c.EndTime.Format(time.RFC3339), c.FreezeTime, c.ScoreboardVisibility, isActive, c.UpdatedAt.Format(time.RFC3339), c.FlagFormat, c.ScoringType, c.RankingMode, c.PenaltyMinutes, c.TieBreaker, c.ID)
 	return err
 }
 
@@ -52,7 +52,8 @@ func (r *ContestEntityRepository) scanContests(rows *sql.Rows) ([]models.Contest
 		var c models.Contest
 		var start, end, created, updated string
 		var isActive int
-		if err := rows.Scan(&c.ID, &c.Name, &c.Description, &start, &end, &c.FreezeTime, &c.ScoreboardVisibility, &isActive, &created, &updated); err != nil {
+		var flagFormat, scoringType, rankingMode, tieBreaker sql.NullString
+		if err := rows.Scan(&c.ID, &c.Name, &c.Description, &start, &end, &c.FreezeTime, &c.ScoreboardVisibility, &isActive, &created, &updated, &flagFormat, &scoringType, &rankingMode, &c.PenaltyMinutes, &tieBreaker); err != nil {
 			return nil, err
 		}
 		c.StartTime, _ = time.Parse(time.RFC3339, start)
@@ -60,13 +61,17 @@ func (r *ContestEntityRepository) scanContests(rows *sql.Rows) ([]models.Contest
 		c.CreatedAt, _ = time.Parse(time.RFC3339, created)
 		c.UpdatedAt, _ = time.Parse(time.RFC3339, updated)
 		c.IsActive = isActive == 1
+		c.FlagFormat = flagFormat.String
+		c.ScoringType = scoringType.String
+		c.RankingMode = rankingMode.String
+		c.TieBreaker = tieBreaker.String
 		cs = append(cs, c)
 	}
 	return cs, nil
 }
 
 func (r *ContestEntityRepository) FindByID(id string) (*models.Contest, error) {
-	rows, err := r.db.Query("SELECT id, name, description, start_time, end_time, freeze_time, scoreboard_visibility, is_active, created_at, updated_at FROM contests WHERE id=?", id)
+	rows, err := r.db.Query("SELECT id, name, description, start_time, end_time, freeze_time, scoreboard_visibility, is_active, created_at, updated_at, flag_format, scoring_type, ranking_mode, penalty_minutes, tie_breaker FROM contests WHERE id=?", id)
 	if err != nil {
 		return nil, err
 	}
@@ -79,7 +84,7 @@ func (r *ContestEntityRepository) FindByID(id string) (*models.Contest, error) {
 }
 
 func (r *ContestEntityRepository) ListAll() ([]models.Contest, error) {
-	rows, err := r.db.Query("SELECT id, name, description, start_time, end_time, freeze_time, scoreboard_visibility, is_active, created_at, updated_at FROM contests ORDER BY start_time DESC")
+	rows, err := r.db.Query("SELECT id, name, description, start_time, end_time, freeze_time, scoreboard_visibility, is_active, created_at, updated_at, flag_format, scoring_type, ranking_mode, penalty_minutes, tie_breaker FROM contests ORDER BY start_time DESC")
 	if err != nil {
 		return nil, err
 	}
@@ -88,7 +93,7 @@ func (r *ContestEntityRepository) ListAll() ([]models.Contest, error) {
 }
 
 func (r *ContestEntityRepository) GetScoreboardContests() ([]models.Contest, error) {
-	rows, err := r.db.Query("SELECT id, name, description, start_time, end_time, freeze_time, scoreboard_visibility, is_active, created_at, updated_at FROM contests WHERE is_active=1 AND start_time <= ? ORDER BY end_time DESC", time.Now().Format(time.RFC3339))
+	rows, err := r.db.Query("SELECT id, name, description, start_time, end_time, freeze_time, scoreboard_visibility, is_active, created_at, updated_at, flag_format, scoring_type, ranking_mode, penalty_minutes, tie_breaker FROM contests WHERE is_active=1 AND start_time <= ? ORDER BY end_time DESC", time.Now().Format(time.RFC3339))
 	if err != nil {
 		return nil, err
 	}
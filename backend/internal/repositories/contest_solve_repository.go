@@ -25,15 +25,74 @@ func (r *ContestSolveRepository) GetContestSolveCount(contestID, challengeID str
 	return count, err
 }
 
-// IncrementContestSolveCount atomically increments the solve count for a challenge in a contest.
-// Creates the row if it doesn't exist (upsert).
-func (r *ContestSolveRepository) IncrementContestSolveCount(contestID, challengeID string) error {
-	query := `INSERT INTO contest_challenge_solves (contest_id, challenge_id, solve_count) VALUES (?, ?, 1)
-			  ON CONFLICT(contest_id, challenge_id) DO UPDATE SET solve_count = solve_count + 1`
-	_, err := r.db.Exec(query, contestID, challengeID)
+// solveOwnerExpr identifies who a submission's solve belongs to: the team, or the user
+// when they have none
+const solveOwnerExpr = `CASE WHEN COALESCE(team_id, '') != '' THEN 'team:' || team_id ELSE user_id END`
+
+// RecountSolves rebuilds the per-contest solve counts of a challenge from its valid correct
+// submissions, counting each team (or user without a team) once per contest
+func (r *ContestSolveRepository) RecountSolves(challengeID string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := recountContestSolves(tx, challengeID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// recountContestSolves rebuilds the per-contest solve counts of a challenge inside tx
+func recountContestSolves(tx *sql.Tx, challengeID string) error {
+	if _, err := tx.Exec("DELETE FROM contest_challenge_solves WHERE challenge_id=?", challengeID); err != nil {
+		return err
+	}
+	_, err := tx.Exec(`INSERT INTO contest_challenge_solves (contest_id, challenge_id, solve_count)
+		SELECT contest_id, challenge_id, COUNT(DISTINCT `+solveOwnerExpr+`)
+		FROM submissions
+		WHERE challenge_id=? AND COALESCE(contest_id, '') != '' AND is_correct=1 AND invalidated_at IS NULL
+		GROUP BY contest_id, challenge_id`, challengeID)
 	return err
 }
 
+// GetAllSolveCounts returns every stored solve count, as contest ID -> challenge ID -> count
+func (r *ContestSolveRepository) GetAllSolveCounts() (map[string]map[string]int, error) {
+	return r.queryCounts("SELECT contest_id, challenge_id, solve_count FROM contest_challenge_solves")
+}
+
+// CountValidSolves counts the solves of every challenge in every contest from the valid
+// correct submissions, the way RecountSolves does
+func (r *ContestSolveRepository) CountValidSolves() (map[string]map[string]int, error) {
+	return r.queryCounts(`SELECT contest_id, challenge_id, COUNT(DISTINCT ` + solveOwnerExpr + `)
+		FROM submissions
+		WHERE COALESCE(contest_id, '') != '' AND is_correct=1 AND invalidated_at IS NULL
+		GROUP BY contest_id, challenge_id`)
+}
+
+func (r *ContestSolveRepository) queryCounts(query string) (map[string]map[string]int, error) {
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[string]map[string]int)
+	for rows.Next() {
+		var contestID, challengeID string
+		var count int
+		if err := rows.Scan(&contestID, &challengeID, &count); err != nil {
+			return nil, err
+		}
+		if counts[contestID] == nil {
+			counts[contestID] = make(map[string]int)
+		}
+		counts[contestID][challengeID] = count
+	}
+	return counts, rows.Err()
+}
+
 // GetContestSolveCounts returns solve counts for all challenges in a contest.
 func (r *ContestSolveRepository) GetContestSolveCounts(contestID string) (map[string]int, error) {
 	rows, err := r.db.Query(
@@ -2,6 +2,7 @@ package repositories
 
 import (
 	"database/sql"
+	"time"
 
 	"github.com/Uttam-Mahata/RootAccess/backend/internal/models"
 	"github.com/google/uuid"
@@ -15,37 +16,24 @@ func NewHintRepository(db *sql.DB) *HintRepository {
 	return &HintRepository{db: db}
 }
 
-func (r *HintRepository) FindReveal(hintID, userID string) (*models.HintReveal, error) {
-	var h models.HintReveal
-	err := r.db.QueryRow("SELECT id, hint_id, challenge_id, user_id, team_id, cost FROM hint_reveals WHERE hint_id=? AND user_id=?", hintID, userID).
-		Scan(&h.ID, &h.HintID, &h.ChallengeID, &h.UserID, &h.TeamID, &h.Cost)
-	if err != nil {
-		return nil, err
-	}
-	return &h, nil
-}
+const hintRevealColumns = "id, hint_id, challenge_id, user_id, team_id, cost, contest_id, created_at"
 
-func (r *HintRepository) FindRevealByTeam(hintID, teamID string) (*models.HintReveal, error) {
+func scanHintReveal(scan func(dest ...interface{}) error) (*models.HintReveal, error) {
 	var h models.HintReveal
-	err := r.db.QueryRow("SELECT id, hint_id, challenge_id, user_id, team_id, cost FROM hint_reveals WHERE hint_id=? AND team_id=?", hintID, teamID).
-		Scan(&h.ID, &h.HintID, &h.ChallengeID, &h.UserID, &h.TeamID, &h.Cost)
-	if err != nil {
+	var teamID, contestID, created sql.NullString
+	if err := scan(&h.ID, &h.HintID, &h.ChallengeID, &h.UserID, &teamID, &h.Cost, &contestID, &created); err != nil {
 		return nil, err
 	}
-	return &h, nil
-}
-
-func (r *HintRepository) CreateReveal(reveal *models.HintReveal) error {
-	if reveal.ID == "" {
-		reveal.ID = uuid.New().String()
+	h.TeamID = teamID.String
+	h.ContestID = contestID.String
+	if created.Valid {
+		h.CreatedAt, _ = time.Parse(time.RFC3339, created.String)
 	}
-	_, err := r.db.Exec("INSERT INTO hint_reveals (id, hint_id, challenge_id, user_id, team_id, cost) VALUES (?, ?, ?, ?, ?, ?)",
-		reveal.ID, reveal.HintID, reveal.ChallengeID, reveal.UserID, reveal.TeamID, reveal.Cost)
-	return err
+	return &h, nil
 }
 
-func (r *HintRepository) GetRevealsByUserAndChallenge(userID, challengeID string) ([]models.HintReveal, error) {
-	rows, err := r.db.Query("SELECT id, hint_id, challenge_id, user_id, team_id, cost FROM hint_reveals WHERE user_id=? AND challenge_id=?", userID, challengeID)
+func (r *HintRepository) queryReveals(query string, args ...interface{}) ([]models.HintReveal, error) {
+	rows, err := r.db.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -53,29 +41,65 @@ func (r *HintRepository) GetRevealsByUserAndChallenge(userID, challengeID string
 
 	var reveals []models.HintReveal
 	for rows.Next() {
-		var h models.HintReveal
-		if err := rows.Scan(&h.ID, &h.HintID, &h.ChallengeID, &h.UserID, &h.TeamID, &h.Cost); err != nil {
+		h, err := scanHintReveal(rows.Scan)
+		if err != nil {
 			return nil, err
 		}
-		reveals = append(reveals, h)
+		reveals = append(reveals, *h)
 	}
 	return reveals, nil
 }
 
-func (r *HintRepository) GetRevealsByTeamAndChallenge(teamID, challengeID string) ([]models.HintReveal, error) {
-	rows, err := r.db.Query("SELECT id, hint_id, challenge_id, user_id, team_id, cost FROM hint_reveals WHERE team_id=? AND challenge_id=?", teamID, challengeID)
+func (r *HintRepository) FindReveal(hintID, userID string) (*models.HintReveal, error) {
+	return scanHintReveal(r.db.QueryRow("SELECT "+hintRevealColumns+" FROM hint_reveals WHERE hint_id=? AND user_id=?", hintID, userID).Scan)
+}
+
+func (r *HintRepository) FindRevealByTeam(hintID, teamID string) (*models.HintReveal, error) {
+	return scanHintReveal(r.db.QueryRow("SELECT "+hintRevealColumns+" FROM hint_reveals WHERE hint_id=? AND team_id=?", hintID, teamID).Scan)
+}
+
+// CreateReveal stores a reveal together with the score ledger entries charge derives from
+// it, in one transaction, so a reveal is never stored without its cost. charge may be nil.
+func (r *HintRepository) CreateReveal(reveal *models.HintReveal, charge func(*models.HintReveal) []models.ScoreLedgerEntry) error {
+	if reveal.ID == "" {
+		reveal.ID = uuid.New().String()
+	}
+	reveal.CreatedAt = time.Now()
+
+	tx, err := r.db.Begin()
 	if err != nil {
-		return nil, err
+		return err
 	}
-	defer rows.Close()
+	defer tx.Rollback()
 
-	var reveals []models.HintReveal
-	for rows.Next() {
-		var h models.HintReveal
-		if err := rows.Scan(&h.ID, &h.HintID, &h.ChallengeID, &h.UserID, &h.TeamID, &h.Cost); err != nil {
-			return nil, err
+	_, err = tx.Exec("INSERT INTO hint_reveals ("+hintRevealColumns+") VALUES (?, ?, ?, ?, ?, ?, ?, ?)",
+		reveal.ID, reveal.HintID, reveal.ChallengeID, reveal.UserID, reveal.TeamID, reveal.Cost,
+		reveal.ContestID, reveal.CreatedAt.Format(time.RFC3339))
+	if err != nil {
+		return err
+	}
+	if charge != nil {
+		if err := appendLedgerEntries(tx, charge(reveal)); err != nil {
+			return err
 		}
-		reveals = append(reveals, h)
 	}
-	return reveals, nil
+	return tx.Commit()
+}
+
+func (r *HintRepository) GetRevealsByUserAndChallenge(userID, challengeID string) ([]models.HintReveal, error) {
+	return r.queryReveals("SELECT "+hintRevealColumns+" FROM hint_reveals WHERE user_id=? AND challenge_id=?", userID, challengeID)
+}
+
+func (r *HintRepository) GetRevealsByTeamAndChallenge(teamID, challengeID string) ([]models.HintReveal, error) {
+	return r.queryReveals("SELECT "+hintRevealColumns+" FROM hint_reveals WHERE team_id=? AND challenge_id=?", teamID, challengeID)
+}
+
+// GetRevealsByContest returns the reveals charged in a contest, oldest first
+func (r *HintRepository) GetRevealsByContest(contestID string) ([]models.HintReveal, error) {
+	return r.queryReveals("SELECT "+hintRevealColumns+" FROM hint_reveals WHERE contest_id=? ORDER BY created_at", contestID)
+}
+
+// GetAllReveals returns every reveal, oldest first
+func (r *HintRepository) GetAllReveals() ([]models.HintReveal, error) {
+	return r.queryReveals("SELECT " + hintRevealColumns + " FROM hint_reveals ORDER BY created_at")
 }
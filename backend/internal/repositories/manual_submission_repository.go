@@ -0,0 +1,192 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/Uttam-Mahata/RootAccess/backend/internal/models"
+	"github.com/google/uuid"
+)
+
+// ManualSubmissionRepository stores answers to manually graded challenges
+type ManualSubmissionRepository struct {
+	db *sql.DB
+}
+
+func NewManualSubmissionRepository(db *sql.DB) *ManualSubmissionRepository {
+	return &ManualSubmissionRepository{db: db}
+}
+
+func (r *ManualSubmissionRepository) Create(entry *models.ManualSubmission) error {
+	if entry.ID == "" {
+		entry.ID = uuid.New().String()
+	}
+	entry.CreatedAt = time.Now()
+	if entry.Status == "" {
+		entry.Status = models.JudgementPending
+	}
+
+	var teamID interface{}
+	if entry.TeamID != "" {
+		teamID = entry.TeamID
+	}
+
+	_, err := r.db.Exec(`INSERT INTO manual_submissions (id, challenge_id, user_id, team_id, contest_id, answer, status, points_awarded, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		entry.ID, entry.ChallengeID, entry.UserID, teamID, entry.ContestID, entry.Answer,
+		entry.Status, entry.PointsAwarded, entry.CreatedAt.Format(time.RFC3339))
+	return err
+}
+
+const manualSubmissionSelect = `SELECT ms.id, ms.challenge_id, c.title, ms.user_id, u.username, ms.team_id, t.name,
+		ms.contest_id, ms.answer, ms.status, ms.points_awarded, ms.feedback, ms.judged_by, ms.judged_at, ms.created_at
+	FROM manual_submissions ms
+	LEFT JOIN challenges c ON c.id = ms.challenge_id
+	LEFT JOIN users u ON u.id = ms.user_id
+	LEFT JOIN teams t ON t.id = ms.team_id`
+
+func (r *ManualSubmissionRepository) scanEntries(rows *sql.Rows) ([]models.ManualSubmission, error) {
+	entries := []models.ManualSubmission{}
+	for rows.Next() {
+		var e models.ManualSubmission
+		var title, username, teamID, teamName, feedback, judgedBy, judgedAt sql.NullString
+		var created string
+		if err := rows.Scan(&e.ID, &e.ChallengeID, &title, &e.UserID, &username, &teamID, &teamName,
+			&e.ContestID, &e.Answer, &e.Status, &e.PointsAwarded, &feedback, &judgedBy, &judgedAt, &created); err != nil {
+			return nil, err
+		}
+		e.ChallengeTitle = title.String
+		e.Username = username.String
+		e.TeamID = teamID.String
+		e.TeamName = teamName.String
+		e.Feedback = feedback.String
+		e.JudgedBy = judgedBy.String
+		if judgedAt.String != "" {
+			if t, err := time.Parse(time.RFC3339, judgedAt.String); err == nil {
+				e.JudgedAt = &t
+			}
+		}
+		e.CreatedAt, _ = time.Parse(time.RFC3339, created)
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+func (r *ManualSubmissionRepository) query(where string, args ...interface{}) ([]models.ManualSubmission, error) {
+	rows, err := r.db.Query(manualSubmissionSelect+" WHERE "+where, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return r.scanEntries(rows)
+}
+
+func (r *ManualSubmissionRepository) GetByID(id string) (*models.ManualSubmission, error) {
+	entries, err := r.query("ms.id=?", id)
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, errors.New("submission not found")
+	}
+	return &entries[0], nil
+}
+
+// List returns the answers in a state, optionally narrowed to a contest and challenge,
+// oldest first so the judging queue is worked through in submission order
+func (r *ManualSubmissionRepository) List(status, contestID, challengeID string) ([]models.ManualSubmission, error) {
+	where := "ms.status=?"
+	args := []interface{}{status}
+	if contestID != "" {
+		where += " AND ms.contest_id=?"
+		args = append(args, contestID)
+	}
+	if challengeID != "" {
+		where += " AND ms.challenge_id=?"
+		args = append(args, challengeID)
+	}
+	return r.query(where+" ORDER BY ms.created_at ASC, ms.rowid ASC", args...)
+}
+
+// GetByOwner returns the answers a team (or a user without a team) gave to a challenge, newest first
+func (r *ManualSubmissionRepository) GetByOwner(challengeID, ownerID string, isTeam bool) ([]models.ManualSubmission, error) {
+	ownerFilter := "ms.team_id=?"
+	if !isTeam {
+		ownerFilter = "ms.user_id=? AND (ms.team_id IS NULL OR ms.team_id='')"
+	}
+	return r.query("ms.challenge_id=? AND "+ownerFilter+" ORDER BY ms.created_at DESC, ms.rowid DESC", challengeID, ownerID)
+}
+
+// HasPending reports whether an owner already has an answer awaiting judgement in a contest
+func (r *ManualSubmissionRepository) HasPending(challengeID, ownerID string, isTeam bool, contestID string) (bool, error) {
+	ownerFilter := "team_id=?"
+	if !isTeam {
+		ownerFilter = "user_id=? AND (team_id IS NULL OR team_id='')"
+	}
+	var count int
+	err := r.db.QueryRow(`SELECT COUNT(*) FROM manual_submissions
+		WHERE challenge_id=? AND `+ownerFilter+` AND contest_id=? AND status=?`,
+		challengeID, ownerID, contestID, models.JudgementPending).Scan(&count)
+	return count > 0, err
+}
+
+// ErrAlreadyJudged is returned when a verdict is given on an answer that is no longer pending
+var ErrAlreadyJudged = errors.New("submission has already been judged")
+
+// ManualVerdict is a judge's verdict on a pending answer
+type ManualVerdict struct {
+	ID       string // the answer judged
+	Status   string
+	Points   int
+	Feedback string
+	JudgedBy string
+}
+
+// Judge records a verdict on a pending answer. With settle set, the answer's challenge is
+// settled in the ledger for its contest in the same transaction. Returns ErrAlreadyJudged
+// if the answer was already judged, so two admins cannot grade the same answer.
+func (r *ManualSubmissionRepository) Judge(v ManualVerdict, settle SolveSettler) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := judgeAnswer(tx, v); err != nil {
+		return err
+	}
+	if settle != nil {
+		var challengeID, contestID string
+		if err := tx.QueryRow("SELECT challenge_id, contest_id FROM manual_submissions WHERE id=?", v.ID).Scan(&challengeID, &contestID); err != nil {
+			return err
+		}
+		if err := settleChallenge(tx, challengeID, contestID, settle); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// judgeAnswer stores a verdict inside tx if the answer is still pending
+func judgeAnswer(tx *sql.Tx, v ManualVerdict) error {
+	res, err := tx.Exec(`UPDATE manual_submissions SET status=?, points_awarded=?, feedback=?, judged_by=?, judged_at=?
+		WHERE id=? AND status=?`,
+		v.Status, v.Points, v.Feedback, v.JudgedBy, time.Now().Format(time.RFC3339), v.ID, models.JudgementPending)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrAlreadyJudged
+	}
+	return nil
+}
+
+// GetPartialAwardsByContest returns the answers in a contest that earned partial credit
+func (r *ManualSubmissionRepository) GetPartialAwardsByContest(contestID string) ([]models.ManualSubmission, error) {
+	return r.query("ms.contest_id=? AND ms.status=?", contestID, models.JudgementPartial)
+}
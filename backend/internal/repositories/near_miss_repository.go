@@ -0,0 +1,107 @@
+package repositories
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/Uttam-Mahata/RootAccess/backend/internal/models"
+	"github.com/google/uuid"
+)
+
+// NearMissRepository stores wrong submissions that were very close to an accepted flag
+type NearMissRepository struct {
+	db *sql.DB
+}
+
+func NewNearMissRepository(db *sql.DB) *NearMissRepository {
+	return &NearMissRepository{db: db}
+}
+
+func (r *NearMissRepository) Create(nm *models.NearMiss) error {
+	if nm.ID == "" {
+		nm.ID = uuid.New().String()
+	}
+	nm.CreatedAt = time.Now()
+
+	_, err := r.db.Exec(`INSERT INTO near_misses (id, challenge_id, contest_id, submission_id, user_id, team_id, flag_id, kind, distance, detail, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		nm.ID, nm.ChallengeID, nm.ContestID, nm.SubmissionID, nm.UserID, nm.TeamID, nm.FlagID,
+		nm.Kind, nm.Distance, nm.Detail, nm.CreatedAt.Format(time.RFC3339))
+	return err
+}
+
+// GetByChallenge returns the near misses of a challenge newest first, limited to a contest
+// unless contestID is empty
+func (r *NearMissRepository) GetByChallenge(challengeID, contestID string) ([]models.NearMiss, error) {
+	query := `SELECT nm.id, nm.challenge_id, nm.contest_id, nm.submission_id, nm.user_id, u.username, nm.team_id, t.name,
+			nm.flag_id, nm.kind, nm.distance, nm.detail, nm.created_at
+		FROM near_misses nm
+		LEFT JOIN users u ON u.id = nm.user_id
+		LEFT JOIN teams t ON t.id = nm.team_id
+		WHERE nm.challenge_id=?`
+	args := []interface{}{challengeID}
+	if contestID != "" {
+		query += " AND nm.contest_id=?"
+		args = append(args, contestID)
+	}
+	query += " ORDER BY nm.created_at DESC, nm.rowid DESC"
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	nearMisses := []models.NearMiss{}
+	for rows.Next() {
+		var nm models.NearMiss
+		var submissionID, username, teamID, teamName, flagID, detail sql.NullString
+		var created string
+		if err := rows.Scan(&nm.ID, &nm.ChallengeID, &nm.ContestID, &submissionID, &nm.UserID, &username, &teamID, &teamName,
+			&flagID, &nm.Kind, &nm.Distance, &detail, &created); err != nil {
+			return nil, err
+		}
+		nm.SubmissionID = submissionID.String
+		nm.Username = username.String
+		nm.TeamID = teamID.String
+		nm.TeamName = teamName.String
+		nm.FlagID = flagID.String
+		nm.Detail = detail.String
+		nm.CreatedAt, _ = time.Parse(time.RFC3339, created)
+		nearMisses = append(nearMisses, nm)
+	}
+	return nearMisses, nil
+}
+
+// Summarize counts near misses per challenge, most first, limited to a contest unless
+// contestID is empty
+func (r *NearMissRepository) Summarize(contestID string) ([]models.NearMissSummary, error) {
+	query := `SELECT nm.challenge_id, c.title, c.category, COUNT(*),
+			COUNT(DISTINCT CASE WHEN COALESCE(nm.team_id,'') != '' THEN 'team:' || nm.team_id ELSE nm.user_id END),
+			MAX(nm.created_at)
+		FROM near_misses nm JOIN challenges c ON c.id = nm.challenge_id`
+	var args []interface{}
+	if contestID != "" {
+		query += " WHERE nm.contest_id=?"
+		args = append(args, contestID)
+	}
+	query += " GROUP BY nm.challenge_id, c.title, c.category ORDER BY COUNT(*) DESC, MAX(nm.created_at) DESC"
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	summaries := []models.NearMissSummary{}
+	for rows.Next() {
+		var s models.NearMissSummary
+		var latest string
+		if err := rows.Scan(&s.ChallengeID, &s.Title, &s.Category, &s.Total, &s.Owners, &latest); err != nil {
+			return nil, err
+		}
+		s.LatestAt, _ = time.Parse(time.RFC3339, latest)
+		summaries = append(summaries, s)
+	}
+	return summaries, nil
+}
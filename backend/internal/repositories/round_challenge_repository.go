@@ -93,3 +93,23 @@ func (r *RoundChallengeRepository) GetChallengesByRound(roundID string) ([]strin
 	}
 	return ids, nil
 }
+
+// GetChallengeIDsByContest maps every contest to the challenges attached to its rounds
+func (r *RoundChallengeRepository) GetChallengeIDsByContest() (map[string][]string, error) {
+	rows, err := r.db.Query(`SELECT DISTINCT cr.contest_id, rc.challenge_id FROM round_challenges rc
+		JOIN contest_rounds cr ON cr.id = rc.round_id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	contests := make(map[string][]string)
+	for rows.Next() {
+		var contestID, challengeID string
+		if err := rows.Scan(&contestID, &challengeID); err != nil {
+			return nil, err
+		}
+		contests[contestID] = append(contests[contestID], challengeID)
+	}
+	return contests, rows.Err()
+}
@@ -2,8 +2,6 @@ package repositories
 
 import (
 	"database/sql"
-	"fmt"
-	"strings"
 	"time"
 
 	"github.com/Uttam-Mahata/RootAccess/backend/internal/models"
@@ -18,57 +16,54 @@ func NewScoreAdjustmentRepository(db *sql.DB) *ScoreAdjustmentRepository {
 	return &ScoreAdjustmentRepository{db: db}
 }
 
-func (r *ScoreAdjustmentRepository) Create(adj *models.ScoreAdjustment) error {
+// Create stores an adjustment together with the score ledger entries credit derives from
+// it, in one transaction, so an adjustment is never stored without taking effect. credit
+// may be nil.
+func (r *ScoreAdjustmentRepository) Create(adj *models.ScoreAdjustment, credit func(*models.ScoreAdjustment) []models.ScoreLedgerEntry) error {
 	if adj.ID == "" {
 		adj.ID = uuid.New().String()
 	}
 	adj.CreatedAt = time.Now()
 
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
 	query := `INSERT INTO score_adjustments (id, target_type, target_id, delta, reason, created_by, created_at)
 			  VALUES (?, ?, ?, ?, ?, ?, ?)`
-	_, err := r.db.Exec(query, adj.ID, adj.TargetType, adj.TargetID, adj.Delta, adj.Reason, adj.CreatedBy, adj.CreatedAt.Format(time.RFC3339))
-	return err
-}
-
-func (r *ScoreAdjustmentRepository) GetAdjustmentsForUsers(userIDs []string) (map[string]int, error) {
-	return r.getAdjustmentsByTargets(models.ScoreAdjustmentTargetUser, userIDs)
-}
-
-func (r *ScoreAdjustmentRepository) GetAdjustmentsForTeams(teamIDs []string) (map[string]int, error) {
-	return r.getAdjustmentsByTargets(models.ScoreAdjustmentTargetTeam, teamIDs)
-}
-
-func (r *ScoreAdjustmentRepository) getAdjustmentsByTargets(targetType string, ids []string) (map[string]int, error) {
-	result := make(map[string]int)
-	if len(ids) == 0 {
-		return result, nil
+	_, err = tx.Exec(query, adj.ID, adj.TargetType, adj.TargetID, adj.Delta, adj.Reason, adj.CreatedBy, adj.CreatedAt.Format(time.RFC3339))
+	if err != nil {
+		return err
 	}
-
-	placeholders := make([]string, len(ids))
-	args := make([]interface{}, len(ids)+1)
-	args[0] = targetType
-	for i, id := range ids {
-		placeholders[i] = "?"
-		args[i+1] = id
+	if credit != nil {
+		if err := appendLedgerEntries(tx, credit(adj)); err != nil {
+			return err
+		}
 	}
+	return tx.Commit()
+}
 
-	query := fmt.Sprintf(`SELECT target_id, SUM(delta) FROM score_adjustments 
-						  WHERE target_type=? AND target_id IN (%s) GROUP BY target_id`, strings.Join(placeholders, ","))
-
-	rows, err := r.db.Query(query, args...)
+// GetAll returns every adjustment, oldest first
+func (r *ScoreAdjustmentRepository) GetAll() ([]models.ScoreAdjustment, error) {
+	rows, err := r.db.Query("SELECT id, target_type, target_id, delta, reason, created_by, created_at FROM score_adjustments ORDER BY created_at")
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
+	var adjustments []models.ScoreAdjustment
 	for rows.Next() {
-		var targetID string
-		var sum int
-		if err := rows.Scan(&targetID, &sum); err != nil {
+		var adj models.ScoreAdjustment
+		var reason sql.NullString
+		var created string
+		if err := rows.Scan(&adj.ID, &adj.TargetType, &adj.TargetID, &adj.Delta, &reason, &adj.CreatedBy, &created); err != nil {
 			return nil, err
 		}
-		result[targetID] = sum
+		adj.Reason = reason.String
+		adj.CreatedAt, _ = time.Parse(time.RFC3339, created)
+		adjustments = append(adjustments, adj)
 	}
-
-	return result, nil
+	return adjustments, rows.Err()
 }
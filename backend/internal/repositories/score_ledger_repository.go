@@ -0,0 +1,254 @@
+package repositories
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/Uttam-Mahata/RootAccess/backend/internal/models"
+	"github.com/google/uuid"
+)
+
+// ScoreLedgerRepository stores the append-only score ledger. Entries are never updated
+// or deleted; teams.score is kept as a cached sum of a team's entries.
+type ScoreLedgerRepository struct {
+	db *sql.DB
+}
+
+func NewScoreLedgerRepository(db *sql.DB) *ScoreLedgerRepository {
+	return &ScoreLedgerRepository{db: db}
+}
+
+const scoreLedgerColumns = "id, contest_id, team_id, user_id, challenge_id, kind, points, actor_id, reference_id, reason, created_at"
+
+// contestLedgerFilter selects a contest's entries plus adjustments made outside any contest,
+// which count on every contest's scoreboard
+const contestLedgerFilter = "(contest_id=? OR (contest_id='' AND kind='" + models.LedgerAdjustment + "'))"
+
+// Append records entries and refreshes the stored score of every team they touch, in one
+// transaction. IDs and times are filled in where missing.
+func (r *ScoreLedgerRepository) Append(entries []models.ScoreLedgerEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := appendLedgerEntries(tx, entries); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// AppendIfEmpty records entries only when the ledger is still empty, so that seeding it
+// happens once. Reports whether the ledger was empty.
+func (r *ScoreLedgerRepository) AppendIfEmpty(entries []models.ScoreLedgerEntry) (bool, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	var exists int
+	err = tx.QueryRow("SELECT 1 FROM score_ledger LIMIT 1").Scan(&exists)
+	if err == nil {
+		return false, nil
+	}
+	if err != sql.ErrNoRows {
+		return false, err
+	}
+	if err := appendLedgerEntries(tx, entries); err != nil {
+		return false, err
+	}
+	return true, tx.Commit()
+}
+
+// Settle appends the entries plan derives from a challenge's current entries in a contest.
+// Reading and appending share a transaction, so concurrent settlements of one challenge
+// cannot record the same change twice.
+func (r *ScoreLedgerRepository) Settle(contestID, challengeID string, plan func(current []models.ScoreLedgerEntry) []models.ScoreLedgerEntry) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Query(challengeLedgerQuery, contestID, challengeID)
+	if err != nil {
+		return err
+	}
+	current, err := scanLedgerEntries(rows)
+	if err != nil {
+		return err
+	}
+
+	entries := plan(current)
+	if len(entries) == 0 {
+		return nil
+	}
+	if err := appendLedgerEntries(tx, entries); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+const challengeLedgerQuery = "SELECT " + scoreLedgerColumns + " FROM score_ledger WHERE contest_id=? AND challenge_id=? ORDER BY created_at, rowid"
+
+// GetByChallenge returns a challenge's entries in a contest ("" outside contests), oldest
+// first, as Settle sees them
+func (r *ScoreLedgerRepository) GetByChallenge(contestID, challengeID string) ([]models.ScoreLedgerEntry, error) {
+	return r.query(challengeLedgerQuery, contestID, challengeID)
+}
+
+// GetCreditScopes returns every contest and challenge the ledger credits solves or partial
+// credit on
+func (r *ScoreLedgerRepository) GetCreditScopes() ([]models.LedgerScope, error) {
+	rows, err := r.db.Query("SELECT DISTINCT contest_id, challenge_id FROM score_ledger WHERE challenge_id != '' AND kind NOT IN (?, ?)",
+		models.LedgerHint, models.LedgerAdjustment)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var scopes []models.LedgerScope
+	for rows.Next() {
+		var sc models.LedgerScope
+		if err := rows.Scan(&sc.ContestID, &sc.ChallengeID); err != nil {
+			return nil, err
+		}
+		scopes = append(scopes, sc)
+	}
+	return scopes, rows.Err()
+}
+
+// appendLedgerEntries records entries and refreshes the stored score of every team they
+// touch. Times are stored in UTC so that comparing and ordering them as text is sound.
+func appendLedgerEntries(tx *sql.Tx, entries []models.ScoreLedgerEntry) error {
+	now := time.Now()
+	teams := make(map[string]bool)
+	for i := range entries {
+		e := &entries[i]
+		if e.ID == "" {
+			e.ID = uuid.New().String()
+		}
+		if e.CreatedAt.IsZero() {
+			e.CreatedAt = now
+		}
+		_, err := tx.Exec(`INSERT INTO score_ledger (`+scoreLedgerColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			e.ID, e.ContestID, e.TeamID, e.UserID, e.ChallengeID, e.Kind, e.Points, e.ActorID, e.ReferenceID, e.Reason, e.CreatedAt.UTC().Format(time.RFC3339))
+		if err != nil {
+			return err
+		}
+		if e.TeamID != "" {
+			teams[e.TeamID] = true
+		}
+	}
+	for teamID := range teams {
+		_, err := tx.Exec(`UPDATE teams SET score = (SELECT COALESCE(SUM(points), 0) FROM score_ledger WHERE team_id=?), updated_at=? WHERE id=?`,
+			teamID, now.Format(time.RFC3339), teamID)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func scanLedgerEntries(rows *sql.Rows) ([]models.ScoreLedgerEntry, error) {
+	defer rows.Close()
+	var entries []models.ScoreLedgerEntry
+	for rows.Next() {
+		var e models.ScoreLedgerEntry
+		var created string
+		if err := rows.Scan(&e.ID, &e.ContestID, &e.TeamID, &e.UserID, &e.ChallengeID, &e.Kind, &e.Points,
+			&e.ActorID, &e.ReferenceID, &e.Reason, &created); err != nil {
+			return nil, err
+		}
+		if t, err := time.Parse(time.RFC3339, created); err == nil {
+			e.CreatedAt = t.Local()
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+func (r *ScoreLedgerRepository) query(query string, args ...interface{}) ([]models.ScoreLedgerEntry, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	return scanLedgerEntries(rows)
+}
+
+// GetByContest returns the entries counting in a contest, oldest first, optionally only
+// those recorded up to until
+func (r *ScoreLedgerRepository) GetByContest(contestID string, until *time.Time) ([]models.ScoreLedgerEntry, error) {
+	if until != nil {
+		// Times are stored and compared as text in UTC
+		return r.query("SELECT "+scoreLedgerColumns+" FROM score_ledger WHERE "+contestLedgerFilter+" AND created_at <= ? ORDER BY created_at, rowid",
+			contestID, until.UTC().Format(time.RFC3339))
+	}
+	return r.query("SELECT "+scoreLedgerColumns+" FROM score_ledger WHERE "+contestLedgerFilter+" ORDER BY created_at, rowid", contestID)
+}
+
+// GetByTeam returns a team's entries, oldest first: those counting in one contest, or
+// all of them when contestID is empty
+func (r *ScoreLedgerRepository) GetByTeam(teamID, contestID string) ([]models.ScoreLedgerEntry, error) {
+	if contestID != "" {
+		return r.query("SELECT "+scoreLedgerColumns+" FROM score_ledger WHERE team_id=? AND "+contestLedgerFilter+" ORDER BY created_at, rowid", teamID, contestID)
+	}
+	return r.query("SELECT "+scoreLedgerColumns+" FROM score_ledger WHERE team_id=? ORDER BY created_at, rowid", teamID)
+}
+
+// GetByUser returns the entries credited to a user, oldest first: those counting in one
+// contest, or all of them when contestID is empty
+func (r *ScoreLedgerRepository) GetByUser(userID, contestID string) ([]models.ScoreLedgerEntry, error) {
+	if contestID != "" {
+		return r.query("SELECT "+scoreLedgerColumns+" FROM score_ledger WHERE user_id=? AND "+contestLedgerFilter+" ORDER BY created_at, rowid", userID, contestID)
+	}
+	return r.query("SELECT "+scoreLedgerColumns+" FROM score_ledger WHERE user_id=? ORDER BY created_at, rowid", userID)
+}
+
+// GetTeamTotal returns a team's score across all contests
+func (r *ScoreLedgerRepository) GetTeamTotal(teamID string) (int, error) {
+	var total int
+	err := r.db.QueryRow("SELECT COALESCE(SUM(points), 0) FROM score_ledger WHERE team_id=?", teamID).Scan(&total)
+	return total, err
+}
+
+// GetTeamTotals returns every team's score across all contests
+func (r *ScoreLedgerRepository) GetTeamTotals() (map[string]int, error) {
+	return r.totals("SELECT team_id, SUM(points) FROM score_ledger WHERE team_id != '' GROUP BY team_id")
+}
+
+// RefreshTeamScores sets the stored score of every team that drifted from its entries
+func (r *ScoreLedgerRepository) RefreshTeamScores() error {
+	_, err := r.db.Exec(`UPDATE teams SET score = (SELECT COALESCE(SUM(points), 0) FROM score_ledger WHERE team_id=teams.id), updated_at=?
+		WHERE score != (SELECT COALESCE(SUM(points), 0) FROM score_ledger WHERE team_id=teams.id)`, time.Now().Format(time.RFC3339))
+	return err
+}
+
+// GetUserTotals returns every user's score across all contests
+func (r *ScoreLedgerRepository) GetUserTotals() (map[string]int, error) {
+	return r.totals("SELECT user_id, SUM(points) FROM score_ledger WHERE user_id != '' GROUP BY user_id")
+}
+
+func (r *ScoreLedgerRepository) totals(query string) (map[string]int, error) {
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	totals := make(map[string]int)
+	for rows.Next() {
+		var id string
+		var total int
+		if err := rows.Scan(&id, &total); err != nil {
+			return nil, err
+		}
+		totals[id] = total
+	}
+	return totals, rows.Err()
+}
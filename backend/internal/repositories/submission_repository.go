@@ -2,6 +2,7 @@ package repositories
 
 import (
 	"database/sql"
+	"errors"
 	"time"
 
 	"github.com/Uttam-Mahata/RootAccess/backend/internal/models"
@@ -16,48 +17,320 @@ func NewSubmissionRepository(db *sql.DB) *SubmissionRepository {
 	return &SubmissionRepository{db: db}
 }
 
-func (r *SubmissionRepository) CreateSubmission(sub *models.Submission) error {
+// ErrAttemptLimitReached is returned when a team has used up a challenge's wrong attempts
+var ErrAttemptLimitReached = errors.New("no attempts remaining for this challenge")
+
+// Errors returned when invalidating or restoring a submission that is already in that state
+var (
+	ErrAlreadyInvalidated = errors.New("submission is already invalidated")
+	ErrNotInvalidated     = errors.New("submission is not invalidated")
+)
+
+// SolveSettler plans the score ledger entries a claimed solve leads to on its challenge in
+// a contest ("" outside contests), from the challenge's valid correct submissions there,
+// its solve count there and the ledger entries already recorded on it, all as the claiming
+// transaction sees them
+type SolveSettler func(contestID string, solves []models.Submission, solveCount int, current []models.ScoreLedgerEntry) ([]models.ScoreLedgerEntry, error)
+
+// RecordOptions are the checks and follow-ups RecordSubmission runs in its transaction
+type RecordOptions struct {
+	// MaxAttempts caps the wrong attempts of the submission's owner since their last reset;
+	// 0 leaves them uncapped. Submissions rejected for their format are never capped.
+	MaxAttempts int
+	// Settle, when set, is run for a claimed solve in the submission's contest and, for a
+	// contest submission, outside contests too, and its entries are appended to the ledger
+	Settle SolveSettler
+	// Verdict, when set, is the judgement of a manually graded answer the submission records.
+	// It is stored first, and ErrAlreadyJudged is returned if the answer is no longer pending.
+	Verdict *ManualVerdict
+}
+
+// RecordSubmission stores a submission. A correct one that is the first solve of its team
+// (or user without a team) on the challenge in its contest also claims the solve and bumps
+// the challenge's global and per-contest solve counts. It all happens in one transaction,
+// and the claim rests on the primary key of solves, so teammates submitting at the same
+// moment cannot both count. The attempt cap is checked in the same transaction, after the
+// insert has taken the write lock, so a burst of guesses cannot all slip under it, and the
+// solve is credited in the score ledger before it commits, so a claim never goes without
+// its points. Reports whether the submission claimed the solve.
+func (r *SubmissionRepository) RecordSubmission(sub *models.Submission, opts RecordOptions) (bool, error) {
 	if sub.ID == "" {
 		sub.ID = uuid.New().String()
 	}
-	sub.Timestamp = time.Now()
+	if sub.Timestamp.IsZero() {
+		sub.Timestamp = time.Now()
+	}
 
 	isCorrect := 0
 	if sub.IsCorrect {
 		isCorrect = 1
 	}
 
-	query := `INSERT INTO submissions (id, user_id, team_id, challenge_id, contest_id, flag, is_correct, ip_address, timestamp)
-			  VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	invalidFormat := 0
+	if sub.InvalidFormat {
+		invalidFormat = 1
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	if opts.Verdict != nil {
+		if err := judgeAnswer(tx, *opts.Verdict); err != nil {
+			return false, err
+		}
+	}
+
+	query := `INSERT INTO submissions (id, user_id, team_id, challenge_id, contest_id, flag, is_correct, ip_address, timestamp, invalid_format)
+			  VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	_, err = tx.Exec(query, sub.ID, sub.UserID, sub.TeamID, sub.ChallengeID, sub.ContestID, sub.Flag, isCorrect, sub.IPAddress, sub.Timestamp.Format(time.RFC3339), invalidFormat)
+	if err != nil {
+		return false, err
+	}
+	if opts.MaxAttempts > 0 && !sub.InvalidFormat {
+		ownerID, isTeam := sub.UserID, sub.TeamID != ""
+		if isTeam {
+			ownerID = sub.TeamID
+		}
+		var used int
+		err := tx.QueryRow(wrongAttemptsQuery(isTeam)+" AND id != ?",
+			sub.ChallengeID, ownerID, sub.ContestID, sub.ChallengeID, ownerID, sub.ContestID, sub.ID).Scan(&used)
+		if err != nil {
+			return false, err
+		}
+		if used >= opts.MaxAttempts {
+			return false, ErrAttemptLimitReached
+		}
+	}
+	if !sub.IsCorrect {
+		return false, tx.Commit()
+	}
+
+	owner := sub.UserID
+	if sub.TeamID != "" {
+		owner = "team:" + sub.TeamID
+	}
+	res, err := tx.Exec(`INSERT INTO solves (challenge_id, contest_id, owner_id, submission_id, solved_at)
+		VALUES (?, ?, ?, ?, ?) ON CONFLICT DO NOTHING`,
+		sub.ChallengeID, sub.ContestID, owner, sub.ID, sub.Timestamp.Format(time.RFC3339))
+	if err != nil {
+		return false, err
+	}
+	claimed, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	if claimed == 0 {
+		return false, tx.Commit()
+	}
+
+	if _, err := tx.Exec("UPDATE challenges SET solve_count = solve_count + 1 WHERE id=?", sub.ChallengeID); err != nil {
+		return false, err
+	}
+	if sub.ContestID != "" {
+		_, err := tx.Exec(`INSERT INTO contest_challenge_solves (contest_id, challenge_id, solve_count) VALUES (?, ?, 1)
+			ON CONFLICT(contest_id, challenge_id) DO UPDATE SET solve_count = solve_count + 1`, sub.ContestID, sub.ChallengeID)
+		if err != nil {
+			return false, err
+		}
+	}
 
-	_, err := r.db.Exec(query, sub.ID, sub.UserID, sub.TeamID, sub.ChallengeID, sub.ContestID, sub.Flag, isCorrect, sub.IPAddress, sub.Timestamp.Format(time.RFC3339))
-	return err
+	if opts.Settle != nil {
+		scopes := []string{sub.ContestID}
+		if sub.ContestID != "" {
+			scopes = append(scopes, "")
+		}
+		for _, contestID := range scopes {
+			if err := settleChallenge(tx, sub.ChallengeID, contestID, opts.Settle); err != nil {
+				return false, err
+			}
+		}
+	}
+	return true, tx.Commit()
 }
 
-func (r *SubmissionRepository) scanSubmissions(rows *sql.Rows) ([]models.Submission, error) {
+// settleChallenge runs a SolveSettler for a challenge in a contest inside tx and appends the
+// entries it plans
+func settleChallenge(tx *sql.Tx, challengeID, contestID string, settle SolveSettler) error {
+	rows, err := tx.Query("SELECT id, user_id, team_id, challenge_id, contest_id, flag, is_correct, ip_address, timestamp, invalidated_at, invalidated_by, invalidation_reason FROM submissions WHERE contest_id=? AND challenge_id=? AND is_correct=1 AND invalidated_at IS NULL ORDER BY timestamp ASC",
+		contestID, challengeID)
+	if err != nil {
+		return err
+	}
+	solves, err := scanSubmissions(rows)
+	rows.Close()
+	if err != nil {
+		return err
+	}
+
+	var solveCount int
+	if contestID == "" {
+		err = tx.QueryRow("SELECT solve_count FROM challenges WHERE id=?", challengeID).Scan(&solveCount)
+	} else {
+		err = tx.QueryRow("SELECT solve_count FROM contest_challenge_solves WHERE contest_id=? AND challenge_id=?", contestID, challengeID).Scan(&solveCount)
+	}
+	if err != nil && err != sql.ErrNoRows {
+		return err
+	}
+
+	rows, err = tx.Query(challengeLedgerQuery, contestID, challengeID)
+	if err != nil {
+		return err
+	}
+	current, err := scanLedgerEntries(rows)
+	if err != nil {
+		return err
+	}
+
+	entries, err := settle(contestID, solves, solveCount, current)
+	if err != nil {
+		return err
+	}
+	return appendLedgerEntries(tx, entries)
+}
+
+// claimSolvesQuery claims the unclaimed solves a challenge's valid correct submissions
+// entitle their owners to, earliest submission first
+const claimSolvesQuery = `INSERT OR IGNORE INTO solves (challenge_id, contest_id, owner_id, submission_id, solved_at)
+	SELECT challenge_id, COALESCE(contest_id, ''), ` + solveOwnerExpr + `, id, timestamp
+	FROM submissions WHERE challenge_id=? AND is_correct=1 AND invalidated_at IS NULL
+	ORDER BY timestamp, rowid`
+
+// updateSolveClaims changes whether a submission is valid and settles everything that rests
+// on it in the same transaction: the submission gives up its solve claim, owners left without
+// one claim their earliest valid correct submission, the challenge's solve counts are rebuilt,
+// and settle, when set, is run as in RecordSubmission. Returns unchanged if query updates
+// nothing, so two admins cannot apply the same change twice.
+func (r *SubmissionRepository) updateSolveClaims(id string, settle SolveSettler, unchanged error, query string, args ...interface{}) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(query, args...)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return unchanged
+	}
+
+	var challengeID, contestID string
+	if err := tx.QueryRow("SELECT challenge_id, COALESCE(contest_id, '') FROM submissions WHERE id=?", id).Scan(&challengeID, &contestID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec("DELETE FROM solves WHERE submission_id=?", id); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(claimSolvesQuery, challengeID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(recountSolvesQuery, challengeID, challengeID); err != nil {
+		return err
+	}
+	if err := recountContestSolves(tx, challengeID); err != nil {
+		return err
+	}
+
+	if settle != nil {
+		scopes := []string{contestID}
+		if contestID != "" {
+			scopes = append(scopes, "")
+		}
+		for _, scope := range scopes {
+			if err := settleChallenge(tx, challengeID, scope, settle); err != nil {
+				return err
+			}
+		}
+	}
+	return tx.Commit()
+}
+
+func (r *SubmissionRepository) GetByID(id string) (*models.Submission, error) {
+	query := "SELECT id, user_id, team_id, challenge_id, contest_id, flag, is_correct, ip_address, timestamp, invalidated_at, invalidated_by, invalidation_reason FROM submissions WHERE id=?"
+	rows, err := r.db.Query(query, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	subs, err := scanSubmissions(rows)
+	if err != nil || len(subs) == 0 {
+		return nil, sql.ErrNoRows
+	}
+	return &subs[0], nil
+}
+
+// Invalidate stops a correct submission from counting as a solve, rebuilding the solve
+// claims and counts and, with settle set, the ledger credit of its challenge in the same
+// transaction. Returns ErrAlreadyInvalidated if it no longer counts.
+func (r *SubmissionRepository) Invalidate(id, invalidatedBy, reason string, at time.Time, settle SolveSettler) error {
+	return r.updateSolveClaims(id, settle, ErrAlreadyInvalidated,
+		"UPDATE submissions SET invalidated_at=?, invalidated_by=?, invalidation_reason=? WHERE id=? AND invalidated_at IS NULL",
+		at.Format(time.RFC3339), invalidatedBy, reason, id)
+}
+
+// Restore undoes Invalidate the same way. Returns ErrNotInvalidated if the submission
+// already counts.
+func (r *SubmissionRepository) Restore(id string, settle SolveSettler) error {
+	return r.updateSolveClaims(id, settle, ErrNotInvalidated,
+		"UPDATE submissions SET invalidated_at=NULL, invalidated_by=NULL, invalidation_reason=NULL WHERE id=? AND invalidated_at IS NOT NULL", id)
+}
+
+// GetInvalidatedSubmissions lists invalidated submissions newest first, limited to a contest
+// unless contestID is empty
+func (r *SubmissionRepository) GetInvalidatedSubmissions(contestID string) ([]models.Submission, error) {
+	query := "SELECT id, user_id, team_id, challenge_id, contest_id, flag, is_correct, ip_address, timestamp, invalidated_at, invalidated_by, invalidation_reason FROM submissions WHERE invalidated_at IS NOT NULL"
+	var args []interface{}
+	if contestID != "" {
+		query += " AND contest_id=?"
+		args = append(args, contestID)
+	}
+	query += " ORDER BY invalidated_at DESC"
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanSubmissions(rows)
+}
+
+func scanSubmissions(rows *sql.Rows) ([]models.Submission, error) {
 	var subs []models.Submission
 	for rows.Next() {
 		var s models.Submission
 		var isCorrect int
 		var ts string
-		if err := rows.Scan(&s.ID, &s.UserID, &s.TeamID, &s.ChallengeID, &s.ContestID, &s.Flag, &isCorrect, &s.IPAddress, &ts); err != nil {
+		var invalidatedAt, invalidatedBy, reason sql.NullString
+		if err := rows.Scan(&s.ID, &s.UserID, &s.TeamID, &s.ChallengeID, &s.ContestID, &s.Flag, &isCorrect, &s.IPAddress, &ts,
+			&invalidatedAt, &invalidatedBy, &reason); err != nil {
 			return nil, err
 		}
 		s.IsCorrect = isCorrect == 1
 		s.Timestamp, _ = time.Parse(time.RFC3339, ts)
+		if invalidatedAt.Valid {
+			t, _ := time.Parse(time.RFC3339, invalidatedAt.String)
+			s.InvalidatedAt = &t
+		}
+		s.InvalidatedBy = invalidatedBy.String
+		s.InvalidationReason = reason.String
 		subs = append(subs, s)
 	}
 	return subs, nil
 }
 
 func (r *SubmissionRepository) FindByChallengeAndUser(challengeID, userID string) (*models.Submission, error) {
-	query := "SELECT id, user_id, team_id, challenge_id, contest_id, flag, is_correct, ip_address, timestamp FROM submissions WHERE challenge_id=? AND user_id=? AND is_correct=1 LIMIT 1"
+	query := "SELECT id, user_id, team_id, challenge_id, contest_id, flag, is_correct, ip_address, timestamp, invalidated_at, invalidated_by, invalidation_reason FROM submissions WHERE challenge_id=? AND user_id=? AND is_correct=1 AND invalidated_at IS NULL LIMIT 1"
 	rows, err := r.db.Query(query, challengeID, userID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	subs, err := r.scanSubmissions(rows)
+	subs, err := scanSubmissions(rows)
 	if err != nil || len(subs) == 0 {
 		return nil, sql.ErrNoRows
 	}
@@ -65,13 +338,13 @@ func (r *SubmissionRepository) FindByChallengeAndUser(challengeID, userID string
 }
 
 func (r *SubmissionRepository) FindByChallengeAndTeam(challengeID, teamID string) (*models.Submission, error) {
-	query := "SELECT id, user_id, team_id, challenge_id, contest_id, flag, is_correct, ip_address, timestamp FROM submissions WHERE challenge_id=? AND team_id=? AND is_correct=1 LIMIT 1"
+	query := "SELECT id, user_id, team_id, challenge_id, contest_id, flag, is_correct, ip_address, timestamp, invalidated_at, invalidated_by, invalidation_reason FROM submissions WHERE challenge_id=? AND team_id=? AND is_correct=1 AND invalidated_at IS NULL LIMIT 1"
 	rows, err := r.db.Query(query, challengeID, teamID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	subs, err := r.scanSubmissions(rows)
+	subs, err := scanSubmissions(rows)
 	if err != nil || len(subs) == 0 {
 		return nil, sql.ErrNoRows
 	}
@@ -79,13 +352,13 @@ func (r *SubmissionRepository) FindByChallengeAndTeam(challengeID, teamID string
 }
 
 func (r *SubmissionRepository) FindByChallengeAndUserInContest(challengeID, userID, contestID string) (*models.Submission, error) {
-	query := "SELECT id, user_id, team_id, challenge_id, contest_id, flag, is_correct, ip_address, timestamp FROM submissions WHERE challenge_id=? AND user_id=? AND contest_id=? AND is_correct=1 LIMIT 1"
+	query := "SELECT id, user_id, team_id, challenge_id, contest_id, flag, is_correct, ip_address, timestamp, invalidated_at, invalidated_by, invalidation_reason FROM submissions WHERE challenge_id=? AND user_id=? AND contest_id=? AND is_correct=1 AND invalidated_at IS NULL LIMIT 1"
 	rows, err := r.db.Query(query, challengeID, userID, contestID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	subs, err := r.scanSubmissions(rows)
+	subs, err := scanSubmissions(rows)
 	if err != nil || len(subs) == 0 {
 		return nil, sql.ErrNoRows
 	}
@@ -93,77 +366,112 @@ func (r *SubmissionRepository) FindByChallengeAndUserInContest(challengeID, user
 }
 
 func (r *SubmissionRepository) FindByChallengeAndTeamInContest(challengeID, teamID, contestID string) (*models.Submission, error) {
-	query := "SELECT id, user_id, team_id, challenge_id, contest_id, flag, is_correct, ip_address, timestamp FROM submissions WHERE challenge_id=? AND team_id=? AND contest_id=? AND is_correct=1 LIMIT 1"
+	query := "SELECT id, user_id, team_id, challenge_id, contest_id, flag, is_correct, ip_address, timestamp, invalidated_at, invalidated_by, invalidation_reason FROM submissions WHERE challenge_id=? AND team_id=? AND contest_id=? AND is_correct=1 AND invalidated_at IS NULL LIMIT 1"
 	rows, err := r.db.Query(query, challengeID, teamID, contestID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	subs, err := r.scanSubmissions(rows)
+	subs, err := scanSubmissions(rows)
 	if err != nil || len(subs) == 0 {
 		return nil, sql.ErrNoRows
 	}
 	return &subs[0], nil
 }
 
+// CountWrongAttempts counts the wrong submissions an owner (a team, or a user without a team)
+// made on a challenge in a contest since the owner's attempt counter was last reset.
+// Submissions rejected for their format are not attempts.
+func (r *SubmissionRepository) CountWrongAttempts(challengeID, ownerID string, isTeam bool, contestID string) (int, error) {
+	var count int
+	err := r.db.QueryRow(wrongAttemptsQuery(isTeam), challengeID, ownerID, contestID, challengeID, ownerID, contestID).Scan(&count)
+	return count, err
+}
+
+// wrongAttemptsQuery counts an owner's wrong attempts since their last reset, taking the
+// challenge, owner and contest, then the same again for the reset lookup. Times are compared
+// with julianday, since they are stored with whatever UTC offset the server had.
+func wrongAttemptsQuery(isTeam bool) string {
+	ownerFilter := "team_id=?"
+	if !isTeam {
+		ownerFilter = "user_id=? AND (team_id IS NULL OR team_id='')"
+	}
+	return `SELECT COUNT(*) FROM submissions
+		WHERE challenge_id=? AND ` + ownerFilter + ` AND COALESCE(contest_id, '')=? AND is_correct=0 AND invalid_format=0
+		AND julianday(timestamp) > COALESCE((SELECT MAX(julianday(created_at)) FROM attempt_resets
+			WHERE challenge_id=? AND owner_id=? AND contest_id=?), 0)`
+}
+
 func (r *SubmissionRepository) GetCorrectSubmissionsByContestAndChallenge(contestID, challengeID string) ([]models.Submission, error) {
-	query := "SELECT id, user_id, team_id, challenge_id, contest_id, flag, is_correct, ip_address, timestamp FROM submissions WHERE contest_id=? AND challenge_id=? AND is_correct=1 ORDER BY timestamp ASC"
+	query := "SELECT id, user_id, team_id, challenge_id, contest_id, flag, is_correct, ip_address, timestamp, invalidated_at, invalidated_by, invalidation_reason FROM submissions WHERE contest_id=? AND challenge_id=? AND is_correct=1 AND invalidated_at IS NULL ORDER BY timestamp ASC"
 	rows, err := r.db.Query(query, contestID, challengeID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	return r.scanSubmissions(rows)
+	return scanSubmissions(rows)
 }
 
 func (r *SubmissionRepository) GetTeamSubmissions(teamID string) ([]models.Submission, error) {
-	query := "SELECT id, user_id, team_id, challenge_id, contest_id, flag, is_correct, ip_address, timestamp FROM submissions WHERE team_id=? AND is_correct=1"
+	query := "SELECT id, user_id, team_id, challenge_id, contest_id, flag, is_correct, ip_address, timestamp, invalidated_at, invalidated_by, invalidation_reason FROM submissions WHERE team_id=? AND is_correct=1 AND invalidated_at IS NULL"
 	rows, err := r.db.Query(query, teamID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	return r.scanSubmissions(rows)
+	return scanSubmissions(rows)
 }
 
 func (r *SubmissionRepository) GetCorrectSubmissionsByContest(contestID string) ([]models.Submission, error) {
-	query := "SELECT id, user_id, team_id, challenge_id, contest_id, flag, is_correct, ip_address, timestamp FROM submissions WHERE contest_id=? AND is_correct=1"
+	query := "SELECT id, user_id, team_id, challenge_id, contest_id, flag, is_correct, ip_address, timestamp, invalidated_at, invalidated_by, invalidation_reason FROM submissions WHERE contest_id=? AND is_correct=1 AND invalidated_at IS NULL"
 	rows, err := r.db.Query(query, contestID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	return r.scanSubmissions(rows)
+	return scanSubmissions(rows)
 }
 
 func (r *SubmissionRepository) GetCorrectSubmissionsByContestBefore(contestID string, before time.Time) ([]models.Submission, error) {
-	query := "SELECT id, user_id, team_id, challenge_id, contest_id, flag, is_correct, ip_address, timestamp FROM submissions WHERE contest_id=? AND is_correct=1 AND timestamp <= ?"
+	query := "SELECT id, user_id, team_id, challenge_id, contest_id, flag, is_correct, ip_address, timestamp, invalidated_at, invalidated_by, invalidation_reason FROM submissions WHERE contest_id=? AND is_correct=1 AND invalidated_at IS NULL AND timestamp <= ?"
 	rows, err := r.db.Query(query, contestID, before.Format(time.RFC3339))
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	return r.scanSubmissions(rows)
+	return scanSubmissions(rows)
+}
+
+// GetAttemptsByContest returns the well-formed, valid submissions of a contest, right or
+// wrong, oldest first
+func (r *SubmissionRepository) GetAttemptsByContest(contestID string) ([]models.Submission, error) {
+	query := "SELECT id, user_id, team_id, challenge_id, contest_id, flag, is_correct, ip_address, timestamp, invalidated_at, invalidated_by, invalidation_reason FROM submissions WHERE contest_id=? AND invalid_format=0 AND invalidated_at IS NULL ORDER BY timestamp ASC"
+	rows, err := r.db.Query(query, contestID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanSubmissions(rows)
 }
 
 func (r *SubmissionRepository) GetAllCorrectSubmissions() ([]models.Submission, error) {
-	query := "SELECT id, user_id, team_id, challenge_id, contest_id, flag, is_correct, ip_address, timestamp FROM submissions WHERE is_correct=1"
+	query := "SELECT id, user_id, team_id, challenge_id, contest_id, flag, is_correct, ip_address, timestamp, invalidated_at, invalidated_by, invalidation_reason FROM submissions WHERE is_correct=1 AND invalidated_at IS NULL"
 	rows, err := r.db.Query(query)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	return r.scanSubmissions(rows)
+	return scanSubmissions(rows)
 }
 
 func (r *SubmissionRepository) GetUserCorrectSubmissions(userID string) ([]models.Submission, error) {
-	query := "SELECT id, user_id, team_id, challenge_id, contest_id, flag, is_correct, ip_address, timestamp FROM submissions WHERE user_id=? AND is_correct=1"
+	query := "SELECT id, user_id, team_id, challenge_id, contest_id, flag, is_correct, ip_address, timestamp, invalidated_at, invalidated_by, invalidation_reason FROM submissions WHERE user_id=? AND is_correct=1 AND invalidated_at IS NULL"
 	rows, err := r.db.Query(query, userID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	return r.scanSubmissions(rows)
+	return scanSubmissions(rows)
 }
 
 func (r *SubmissionRepository) GetUserSubmissionCount(userID string) (int64, error) {
@@ -174,7 +482,7 @@ func (r *SubmissionRepository) GetUserSubmissionCount(userID string) (int64, err
 
 func (r *SubmissionRepository) GetUserCorrectSubmissionCount(userID string) (int64, error) {
 	var count int64
-	err := r.db.QueryRow("SELECT COUNT(*) FROM submissions WHERE user_id=? AND is_correct=1", userID).Scan(&count)
+	err := r.db.QueryRow("SELECT COUNT(*) FROM submissions WHERE user_id=? AND is_correct=1 AND invalidated_at IS NULL", userID).Scan(&count)
 	return count, err
 }
 
@@ -184,68 +492,136 @@ func (r *SubmissionRepository) CountSubmissions() (int64, error) {
 	return count, err
 }
 
+// CountInvalidFormatSubmissions counts submissions rejected for not matching the flag format
+func (r *SubmissionRepository) CountInvalidFormatSubmissions() (int64, error) {
+	var count int64
+	err := r.db.QueryRow("SELECT COUNT(*) FROM submissions WHERE invalid_format=1").Scan(&count)
+	return count, err
+}
+
 func (r *SubmissionRepository) CountCorrectSubmissions() (int64, error) {
 	var count int64
-	err := r.db.QueryRow("SELECT COUNT(*) FROM submissions WHERE is_correct=1").Scan(&count)
+	err := r.db.QueryRow("SELECT COUNT(*) FROM submissions WHERE is_correct=1 AND invalidated_at IS NULL").Scan(&count)
 	return count, err
 }
 
 func (r *SubmissionRepository) GetAllSubmissions() ([]models.Submission, error) {
-	query := "SELECT id, user_id, team_id, challenge_id, contest_id, flag, is_correct, ip_address, timestamp FROM submissions"
+	query := "SELECT id, user_id, team_id, challenge_id, contest_id, flag, is_correct, ip_address, timestamp, invalidated_at, invalidated_by, invalidation_reason FROM submissions"
 	rows, err := r.db.Query(query)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	return r.scanSubmissions(rows)
+	return scanSubmissions(rows)
 }
 
 func (r *SubmissionRepository) GetRecentSubmissions(limit int64) ([]models.Submission, error) {
-	query := "SELECT id, user_id, team_id, challenge_id, contest_id, flag, is_correct, ip_address, timestamp FROM submissions ORDER BY timestamp DESC LIMIT ?"
+	query := "SELECT id, user_id, team_id, challenge_id, contest_id, flag, is_correct, ip_address, timestamp, invalidated_at, invalidated_by, invalidation_reason FROM submissions ORDER BY timestamp DESC LIMIT ?"
 	rows, err := r.db.Query(query, limit)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	return r.scanSubmissions(rows)
+	return scanSubmissions(rows)
 }
 
 func (r *SubmissionRepository) GetCorrectSubmissionsSince(since time.Time) ([]models.Submission, error) {
-	query := "SELECT id, user_id, team_id, challenge_id, contest_id, flag, is_correct, ip_address, timestamp FROM submissions WHERE is_correct=1 AND timestamp >= ?"
+	query := "SELECT id, user_id, team_id, challenge_id, contest_id, flag, is_correct, ip_address, timestamp, invalidated_at, invalidated_by, invalidation_reason FROM submissions WHERE is_correct=1 AND invalidated_at IS NULL AND timestamp >= ?"
 	rows, err := r.db.Query(query, since.Format(time.RFC3339))
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	return r.scanSubmissions(rows)
+	return scanSubmissions(rows)
 }
 
 func (r *SubmissionRepository) GetSubmissionsSince(since time.Time) ([]models.Submission, error) {
-	query := "SELECT id, user_id, team_id, challenge_id, contest_id, flag, is_correct, ip_address, timestamp FROM submissions WHERE timestamp >= ?"
+	query := "SELECT id, user_id, team_id, challenge_id, contest_id, flag, is_correct, ip_address, timestamp, invalidated_at, invalidated_by, invalidation_reason FROM submissions WHERE timestamp >= ?"
 	rows, err := r.db.Query(query, since.Format(time.RFC3339))
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	return r.scanSubmissions(rows)
+	return scanSubmissions(rows)
 }
 
 func (r *SubmissionRepository) GetCorrectSubmissionsByChallenge(challengeID string) ([]models.Submission, error) {
-	query := "SELECT id, user_id, team_id, challenge_id, contest_id, flag, is_correct, ip_address, timestamp FROM submissions WHERE challenge_id=? AND is_correct=1 ORDER BY timestamp ASC"
+	query := "SELECT id, user_id, team_id, challenge_id, contest_id, flag, is_correct, ip_address, timestamp, invalidated_at, invalidated_by, invalidation_reason FROM submissions WHERE challenge_id=? AND is_correct=1 AND invalidated_at IS NULL ORDER BY timestamp ASC"
+	rows, err := r.db.Query(query, challengeID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanSubmissions(rows)
+}
+
+// GetSubmissionsByChallenge returns every well-formed, valid submission for a challenge, oldest first
+func (r *SubmissionRepository) GetSubmissionsByChallenge(challengeID string) ([]models.Submission, error) {
+	query := "SELECT id, user_id, team_id, challenge_id, contest_id, flag, is_correct, ip_address, timestamp, invalidated_at, invalidated_by, invalidation_reason FROM submissions WHERE challenge_id=? AND invalid_format=0 AND invalidated_at IS NULL ORDER BY timestamp ASC"
 	rows, err := r.db.Query(query, challengeID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	return r.scanSubmissions(rows)
+	return scanSubmissions(rows)
 }
 
 func (r *SubmissionRepository) GetCorrectSubmissionsBefore(before time.Time) ([]models.Submission, error) {
-	query := "SELECT id, user_id, team_id, challenge_id, contest_id, flag, is_correct, ip_address, timestamp FROM submissions WHERE is_correct=1 AND timestamp <= ?"
+	query := "SELECT id, user_id, team_id, challenge_id, contest_id, flag, is_correct, ip_address, timestamp, invalidated_at, invalidated_by, invalidation_reason FROM submissions WHERE is_correct=1 AND invalidated_at IS NULL AND timestamp <= ?"
 	rows, err := r.db.Query(query, before.Format(time.RFC3339))
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	return r.scanSubmissions(rows)
+	return scanSubmissions(rows)
+}
+
+// GetTeamSolvedChallengeCategories returns the challenges a team has solved in a contest,
+// mapped to each challenge's category. Used to evaluate challenge prerequisites.
+// GetSolvedChallengeIDs returns the challenges a user, or their team when teamID is set, has
+// a valid correct submission for: in one contest, or in any when contestID is empty
+func (r *SubmissionRepository) GetSolvedChallengeIDs(userID, teamID, contestID string) (map[string]bool, error) {
+	query := `SELECT DISTINCT challenge_id FROM submissions
+			  WHERE (user_id=? OR (team_id=? AND team_id != '')) AND is_correct=1 AND invalidated_at IS NULL`
+	args := []interface{}{userID, teamID}
+	if contestID != "" {
+		query += " AND contest_id=?"
+		args = append(args, contestID)
+	}
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	solved := make(map[string]bool)
+	for rows.Next() {
+		var challengeID string
+		if err := rows.Scan(&challengeID); err != nil {
+			return nil, err
+		}
+		solved[challengeID] = true
+	}
+	return solved, rows.Err()
+}
+
+func (r *SubmissionRepository) GetTeamSolvedChallengeCategories(teamID, contestID string) (map[string]string, error) {
+	query := `SELECT DISTINCT s.challenge_id, c.category FROM submissions s
+			  JOIN challenges c ON c.id = s.challenge_id
+			  WHERE s.team_id=? AND s.contest_id=? AND s.is_correct=1 AND s.invalidated_at IS NULL`
+	rows, err := r.db.Query(query, teamID, contestID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	solved := make(map[string]string)
+	for rows.Next() {
+		var challengeID, category string
+		if err := rows.Scan(&challengeID, &category); err != nil {
+			return nil, err
+		}
+		solved[challengeID] = category
+	}
+	return solved, nil
 }
@@ -0,0 +1,463 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Uttam-Mahata/RootAccess/backend/internal/database"
+	"github.com/Uttam-Mahata/RootAccess/backend/internal/models"
+	_ "modernc.org/sqlite"
+)
+
+// newTestDB opens a fresh SQLite database file with the full schema. Every connection
+// waits for the write lock instead of failing, as concurrent submissions do against Turso.
+func newTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	dsn := "file:" + filepath.Join(t.TempDir(), "test.db") + "?_pragma=busy_timeout(10000)&_pragma=journal_mode(WAL)"
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		t.Fatalf("sql.Open() error = %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	database.BootstrapSchema(db)
+	return db
+}
+
+func createTestChallenge(t *testing.T, db *sql.DB, id string) {
+	t.Helper()
+	_, err := db.Exec(`INSERT INTO challenges (id, title, description, description_format, category, difficulty,
+		max_points, min_points, decay, scoring_type, flag_hash) VALUES (?, ?, '', 'markdown', 'web', 'easy', 500, 100, 10, 'dynamic', '')`,
+		id, id)
+	if err != nil {
+		t.Fatalf("creating challenge %s: %v", id, err)
+	}
+}
+
+// solveCounts returns a challenge's global and per-contest solve counts and its claimed solves
+func solveCounts(t *testing.T, db *sql.DB, challengeID, contestID string) (global, contest, claims int) {
+	t.Helper()
+	if err := db.QueryRow("SELECT solve_count FROM challenges WHERE id=?", challengeID).Scan(&global); err != nil {
+		t.Fatalf("reading solve count: %v", err)
+	}
+	if contestID != "" {
+		contest, _ = NewContestSolveRepository(db).GetContestSolveCount(contestID, challengeID)
+	}
+	if err := db.QueryRow("SELECT COUNT(*) FROM solves WHERE challenge_id=? AND contest_id=?", challengeID, contestID).Scan(&claims); err != nil {
+		t.Fatalf("counting solves: %v", err)
+	}
+	return global, contest, claims
+}
+
+// submitConcurrently records one correct submission per entry at once and returns how
+// many of them claimed the solve, per team
+func submitConcurrently(t *testing.T, repo *SubmissionRepository, subs []*models.Submission) map[string]int {
+	t.Helper()
+	var (
+		wg    sync.WaitGroup
+		mu    sync.Mutex
+		start = make(chan struct{})
+		fails atomic.Int32
+		first = make(map[string]int)
+	)
+	for _, sub := range subs {
+		wg.Add(1)
+		go func(sub *models.Submission) {
+			defer wg.Done()
+			<-start
+			claimed, err := repo.RecordSubmission(sub, RecordOptions{})
+			if err != nil {
+				fails.Add(1)
+				t.Errorf("RecordSubmission() error = %v", err)
+				return
+			}
+			if claimed {
+				mu.Lock()
+				first[sub.TeamID]++
+				mu.Unlock()
+			}
+		}(sub)
+	}
+	close(start)
+	wg.Wait()
+	if fails.Load() > 0 {
+		t.FailNow()
+	}
+	return first
+}
+
+func TestRecordSubmission_ConcurrentTeammatesCountOnce(t *testing.T) {
+	db := newTestDB(t)
+	createTestChallenge(t, db, "chal")
+	repo := NewSubmissionRepository(db)
+
+	var subs []*models.Submission
+	for i := 0; i < 24; i++ {
+		subs = append(subs, &models.Submission{
+			UserID:      fmt.Sprintf("user-%d", i%4),
+			TeamID:      "team-1",
+			ChallengeID: "chal",
+			ContestID:   "ctf",
+			IsCorrect:   true,
+		})
+	}
+	first := submitConcurrently(t, repo, subs)
+
+	if first["team-1"] != 1 {
+		t.Errorf("%d submissions claimed the solve, want exactly 1", first["team-1"])
+	}
+	if global, contest, claims := solveCounts(t, db, "chal", "ctf"); global != 1 || contest != 1 || claims != 1 {
+		t.Errorf("solve count = %d, contest solve count = %d, solves = %d; want 1 each", global, contest, claims)
+	}
+	var stored int
+	db.QueryRow("SELECT COUNT(*) FROM submissions WHERE challenge_id='chal' AND is_correct=1").Scan(&stored)
+	if stored != len(subs) {
+		t.Errorf("stored %d correct submissions, want all %d", stored, len(subs))
+	}
+}
+
+func TestRecordSubmission_ConcurrentTeamsEachCountOnce(t *testing.T) {
+	db := newTestDB(t)
+	createTestChallenge(t, db, "chal")
+	repo := NewSubmissionRepository(db)
+
+	const teams, members = 8, 4
+	var subs []*models.Submission
+	for m := 0; m < members; m++ {
+		for tm := 0; tm < teams; tm++ {
+			subs = append(subs, &models.Submission{
+				UserID:      fmt.Sprintf("user-%d-%d", tm, m),
+				TeamID:      fmt.Sprintf("team-%d", tm),
+				ChallengeID: "chal",
+				ContestID:   "ctf",
+				IsCorrect:   true,
+			})
+		}
+	}
+	first := submitConcurrently(t, repo, subs)
+
+	for tm := 0; tm < teams; tm++ {
+		if got := first[fmt.Sprintf("team-%d", tm)]; got != 1 {
+			t.Errorf("team-%d claimed the solve %d times, want 1", tm, got)
+		}
+	}
+	if global, contest, claims := solveCounts(t, db, "chal", "ctf"); global != teams || contest != teams || claims != teams {
+		t.Errorf("solve count = %d, contest solve count = %d, solves = %d; want %d each", global, contest, claims, teams)
+	}
+}
+
+func TestRecordSubmission_ConcurrentRetriesWithoutTeam(t *testing.T) {
+	db := newTestDB(t)
+	createTestChallenge(t, db, "chal")
+	repo := NewSubmissionRepository(db)
+
+	var subs []*models.Submission
+	for i := 0; i < 10; i++ {
+		subs = append(subs, &models.Submission{UserID: "solo", ChallengeID: "chal", IsCorrect: true})
+	}
+	first := submitConcurrently(t, repo, subs)
+
+	if first[""] != 1 {
+		t.Errorf("%d retries claimed the solve, want exactly 1", first[""])
+	}
+	if global, _, claims := solveCounts(t, db, "chal", ""); global != 1 || claims != 1 {
+		t.Errorf("solve count = %d, solves = %d; want 1 each", global, claims)
+	}
+}
+
+func TestRecordSubmission_SolvesArePerContest(t *testing.T) {
+	db := newTestDB(t)
+	createTestChallenge(t, db, "chal")
+	repo := NewSubmissionRepository(db)
+
+	steps := []struct {
+		contestID string
+		correct   bool
+		want      bool
+	}{
+		{"ctf-a", false, false},
+		{"ctf-a", true, true},
+		{"ctf-b", true, true},
+		{"", true, true},
+		{"ctf-a", true, false},
+	}
+	for i, step := range steps {
+		sub := &models.Submission{UserID: "u1", TeamID: "team-1", ChallengeID: "chal", ContestID: step.contestID, IsCorrect: step.correct}
+		claimed, err := repo.RecordSubmission(sub, RecordOptions{})
+		if err != nil {
+			t.Fatalf("step %d: RecordSubmission() error = %v", i, err)
+		}
+		if claimed != step.want {
+			t.Errorf("step %d (contest %q, correct %v): claimed = %v, want %v", i, step.contestID, step.correct, claimed, step.want)
+		}
+	}
+
+	if global, contest, _ := solveCounts(t, db, "chal", "ctf-a"); global != 3 || contest != 1 {
+		t.Errorf("solve count = %d, ctf-a solve count = %d; want 3 and 1", global, contest)
+	}
+	if _, contest, _ := solveCounts(t, db, "chal", "ctf-b"); contest != 1 {
+		t.Errorf("ctf-b solve count = %d, want 1", contest)
+	}
+}
+
+func TestRecordSubmission_ConcurrentGuessesRespectAttemptCap(t *testing.T) {
+	db := newTestDB(t)
+	createTestChallenge(t, db, "chal")
+	repo := NewSubmissionRepository(db)
+
+	const maxAttempts, guesses = 2, 12
+	var (
+		wg       sync.WaitGroup
+		start    = make(chan struct{})
+		recorded atomic.Int32
+		refused  atomic.Int32
+	)
+	for i := 0; i < guesses; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			<-start
+			sub := &models.Submission{
+				UserID:      fmt.Sprintf("user-%d", i%4),
+				TeamID:      "team-1",
+				ChallengeID: "chal",
+				ContestID:   "ctf",
+				Flag:        fmt.Sprintf("guess-%d", i),
+			}
+			_, err := repo.RecordSubmission(sub, RecordOptions{MaxAttempts: maxAttempts})
+			switch {
+			case err == ErrAttemptLimitReached:
+				refused.Add(1)
+			case err != nil:
+				t.Errorf("RecordSubmission() error = %v", err)
+			default:
+				recorded.Add(1)
+			}
+		}(i)
+	}
+	close(start)
+	wg.Wait()
+
+	if recorded.Load() != maxAttempts || refused.Load() != guesses-maxAttempts {
+		t.Errorf("recorded %d guesses and refused %d, want %d and %d", recorded.Load(), refused.Load(), maxAttempts, guesses-maxAttempts)
+	}
+	used, err := repo.CountWrongAttempts("chal", "team-1", true, "ctf")
+	if err != nil {
+		t.Fatalf("CountWrongAttempts() error = %v", err)
+	}
+	if used != maxAttempts {
+		t.Errorf("stored %d wrong attempts, want %d", used, maxAttempts)
+	}
+
+	// A correct flag is refused too once the cap is used up
+	sub := &models.Submission{UserID: "user-0", TeamID: "team-1", ChallengeID: "chal", ContestID: "ctf", IsCorrect: true}
+	if _, err := repo.RecordSubmission(sub, RecordOptions{MaxAttempts: maxAttempts}); err != ErrAttemptLimitReached {
+		t.Errorf("correct submission after the cap: error = %v, want %v", err, ErrAttemptLimitReached)
+	}
+}
+
+func TestCountWrongAttempts_ResetAcrossTimeZones(t *testing.T) {
+	db := newTestDB(t)
+	createTestChallenge(t, db, "chal")
+	repo := NewSubmissionRepository(db)
+
+	// The reset was stored at +05:30 and comes half an hour before the guess stored in UTC,
+	// although its text sorts after it
+	reset := time.Date(2026, 1, 1, 9, 30, 0, 0, time.UTC)
+	_, err := db.Exec(`INSERT INTO attempt_resets (id, challenge_id, owner_id, contest_id, reset_by, created_at)
+		VALUES ('reset-1', 'chal', 'team-1', 'ctf', 'admin', ?)`,
+		reset.In(time.FixedZone("IST", 5*3600+1800)).Format(time.RFC3339))
+	if err != nil {
+		t.Fatalf("inserting reset: %v", err)
+	}
+	for i, at := range []time.Time{reset.Add(-time.Hour), reset.Add(30 * time.Minute)} {
+		sub := &models.Submission{UserID: "user-1", TeamID: "team-1", ChallengeID: "chal", ContestID: "ctf",
+			Flag: fmt.Sprintf("guess-%d", i), Timestamp: at.UTC()}
+		if _, err := repo.RecordSubmission(sub, RecordOptions{}); err != nil {
+			t.Fatalf("RecordSubmission() error = %v", err)
+		}
+	}
+
+	used, err := repo.CountWrongAttempts("chal", "team-1", true, "ctf")
+	if err != nil {
+		t.Fatalf("CountWrongAttempts() error = %v", err)
+	}
+	if used != 1 {
+		t.Errorf("CountWrongAttempts() = %d, want 1 (only the guess after the reset)", used)
+	}
+}
+
+func TestRecordSubmission_SettlesSolveWithClaim(t *testing.T) {
+	db := newTestDB(t)
+	createTestChallenge(t, db, "chal")
+	repo := NewSubmissionRepository(db)
+
+	var scopes []string
+	settle := func(contestID string, solves []models.Submission, solveCount int, current []models.ScoreLedgerEntry) ([]models.ScoreLedgerEntry, error) {
+		scopes = append(scopes, fmt.Sprintf("%q:%d:%d", contestID, len(solves), solveCount))
+		return []models.ScoreLedgerEntry{{ContestID: contestID, TeamID: "team-1", ChallengeID: "chal", Kind: models.LedgerSolve, Points: 500}}, nil
+	}
+	sub := &models.Submission{UserID: "u1", TeamID: "team-1", ChallengeID: "chal", ContestID: "ctf", IsCorrect: true}
+	if _, err := repo.RecordSubmission(sub, RecordOptions{Settle: settle}); err != nil {
+		t.Fatalf("RecordSubmission() error = %v", err)
+	}
+	if want := []string{`"ctf":1:1`, `"":0:1`}; fmt.Sprint(scopes) != fmt.Sprint(want) {
+		t.Errorf("settled %v, want %v", scopes, want)
+	}
+	var entries int
+	db.QueryRow("SELECT COUNT(*) FROM score_ledger WHERE challenge_id='chal'").Scan(&entries)
+	if entries != 2 {
+		t.Errorf("ledger holds %d entries, want 2", entries)
+	}
+
+	// A failed settlement takes the claim, the counts and the submission down with it
+	failing := func(string, []models.Submission, int, []models.ScoreLedgerEntry) ([]models.ScoreLedgerEntry, error) {
+		return nil, fmt.Errorf("ledger unavailable")
+	}
+	sub = &models.Submission{UserID: "u2", TeamID: "team-2", ChallengeID: "chal", ContestID: "ctf", IsCorrect: true}
+	if _, err := repo.RecordSubmission(sub, RecordOptions{Settle: failing}); err == nil {
+		t.Fatal("RecordSubmission() succeeded with a failing settlement")
+	}
+	if global, contest, claims := solveCounts(t, db, "chal", "ctf"); global != 1 || contest != 1 || claims != 1 {
+		t.Errorf("solve count = %d, contest solve count = %d, solves = %d; want 1 each", global, contest, claims)
+	}
+	var stored int
+	db.QueryRow("SELECT COUNT(*) FROM submissions WHERE id=?", sub.ID).Scan(&stored)
+	if stored != 0 {
+		t.Error("submission stored although its settlement failed")
+	}
+}
+
+func TestRecordSubmission_StoresVerdictWithSubmission(t *testing.T) {
+	db := newTestDB(t)
+	createTestChallenge(t, db, "chal")
+	repo := NewSubmissionRepository(db)
+
+	answer := &models.ManualSubmission{ChallengeID: "chal", UserID: "u1", TeamID: "team-1", ContestID: "ctf", Answer: "writeup"}
+	if err := NewManualSubmissionRepository(db).Create(answer); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	accepted := &models.Submission{UserID: "u1", TeamID: "team-1", ChallengeID: "chal", ContestID: "ctf", IsCorrect: true}
+	verdict := ManualVerdict{ID: answer.ID, Status: models.JudgementAccepted, JudgedBy: "admin-1"}
+	if _, err := repo.RecordSubmission(accepted, RecordOptions{Verdict: &verdict}); err != nil {
+		t.Fatalf("RecordSubmission() error = %v", err)
+	}
+	var status string
+	db.QueryRow("SELECT status FROM manual_submissions WHERE id=?", answer.ID).Scan(&status)
+	if status != models.JudgementAccepted {
+		t.Errorf("answer status = %q, want %q", status, models.JudgementAccepted)
+	}
+
+	// A second judge loses the race: neither their verdict nor their submission is stored
+	rejected := &models.Submission{UserID: "u1", TeamID: "team-1", ChallengeID: "chal", ContestID: "ctf"}
+	verdict = ManualVerdict{ID: answer.ID, Status: models.JudgementRejected, JudgedBy: "admin-2"}
+	if _, err := repo.RecordSubmission(rejected, RecordOptions{Verdict: &verdict}); err != ErrAlreadyJudged {
+		t.Fatalf("second verdict: error = %v, want %v", err, ErrAlreadyJudged)
+	}
+	var stored int
+	db.QueryRow("SELECT COUNT(*) FROM submissions WHERE id=?", rejected.ID).Scan(&stored)
+	db.QueryRow("SELECT status FROM manual_submissions WHERE id=?", answer.ID).Scan(&status)
+	if stored != 0 || status != models.JudgementAccepted {
+		t.Errorf("after the second verdict: %d submissions stored and status %q, want 0 and %q", stored, status, models.JudgementAccepted)
+	}
+}
+
+func TestInvalidate_PassesSolveToNextSubmission(t *testing.T) {
+	db := newTestDB(t)
+	createTestChallenge(t, db, "chal")
+	repo := NewSubmissionRepository(db)
+
+	base := time.Now().Add(-time.Hour)
+	first := &models.Submission{UserID: "u1", TeamID: "team-1", ChallengeID: "chal", ContestID: "ctf", IsCorrect: true, Timestamp: base}
+	second := &models.Submission{UserID: "u2", TeamID: "team-1", ChallengeID: "chal", ContestID: "ctf", IsCorrect: true, Timestamp: base.Add(time.Minute)}
+	for _, sub := range []*models.Submission{first, second} {
+		if _, err := repo.RecordSubmission(sub, RecordOptions{}); err != nil {
+			t.Fatalf("RecordSubmission() error = %v", err)
+		}
+	}
+
+	claimant := func() string {
+		var id string
+		err := db.QueryRow("SELECT submission_id FROM solves WHERE challenge_id='chal' AND contest_id='ctf' AND owner_id='team:team-1'").Scan(&id)
+		if err == sql.ErrNoRows {
+			return ""
+		}
+		if err != nil {
+			t.Fatalf("reading solve: %v", err)
+		}
+		return id
+	}
+
+	steps := []struct {
+		name string
+		do   func() error
+		want string
+	}{
+		{"recorded", func() error { return nil }, first.ID},
+		{"first invalidated", func() error { return repo.Invalidate(first.ID, "admin", "shared flag", time.Now(), nil) }, second.ID},
+		{"both invalidated", func() error { return repo.Invalidate(second.ID, "admin", "shared flag", time.Now(), nil) }, ""},
+		{"first restored", func() error { return repo.Restore(first.ID, nil) }, first.ID},
+	}
+	for _, step := range steps {
+		if err := step.do(); err != nil {
+			t.Fatalf("%s: %v", step.name, err)
+		}
+		if got := claimant(); got != step.want {
+			t.Errorf("%s: solve claimed by %q, want %q", step.name, got, step.want)
+		}
+	}
+}
+
+func TestInvalidate_SettlesWithRecountInOneTransaction(t *testing.T) {
+	db := newTestDB(t)
+	createTestChallenge(t, db, "chal")
+	repo := NewSubmissionRepository(db)
+
+	sub := &models.Submission{UserID: "u1", TeamID: "team-1", ChallengeID: "chal", ContestID: "ctf", IsCorrect: true}
+	if _, err := repo.RecordSubmission(sub, RecordOptions{}); err != nil {
+		t.Fatalf("RecordSubmission() error = %v", err)
+	}
+
+	// The settlement sees the invalidation and the rebuilt counts
+	var settled []string
+	settle := func(contestID string, solves []models.Submission, solveCount int, current []models.ScoreLedgerEntry) ([]models.ScoreLedgerEntry, error) {
+		settled = append(settled, fmt.Sprintf("%q:%d:%d", contestID, len(solves), solveCount))
+		return nil, nil
+	}
+	if err := repo.Invalidate(sub.ID, "admin", "shared flag", time.Now(), settle); err != nil {
+		t.Fatalf("Invalidate() error = %v", err)
+	}
+	if want := []string{`"ctf":0:0`, `"":0:0`}; fmt.Sprint(settled) != fmt.Sprint(want) {
+		t.Errorf("settled %v, want %v", settled, want)
+	}
+	if global, contest, claims := solveCounts(t, db, "chal", "ctf"); global != 0 || contest != 0 || claims != 0 {
+		t.Errorf("solve count = %d, contest solve count = %d, solves = %d; want 0 each", global, contest, claims)
+	}
+
+	// A second admin invalidating the same submission changes nothing
+	if err := repo.Invalidate(sub.ID, "admin-2", "again", time.Now(), settle); err != ErrAlreadyInvalidated {
+		t.Errorf("second Invalidate() error = %v, want %v", err, ErrAlreadyInvalidated)
+	}
+
+	// A failed settlement leaves the submission invalidated
+	failing := func(string, []models.Submission, int, []models.ScoreLedgerEntry) ([]models.ScoreLedgerEntry, error) {
+		return nil, fmt.Errorf("ledger unavailable")
+	}
+	if err := repo.Restore(sub.ID, failing); err == nil {
+		t.Fatal("Restore() succeeded with a failing settlement")
+	}
+	if global, contest, claims := solveCounts(t, db, "chal", "ctf"); global != 0 || contest != 0 || claims != 0 {
+		t.Errorf("after a failed restore: solve count = %d, contest solve count = %d, solves = %d; want 0 each", global, contest, claims)
+	}
+	if err := repo.Restore(sub.ID, nil); err != nil {
+		t.Fatalf("Restore() error = %v", err)
+	}
+	if err := repo.Restore(sub.ID, nil); err != ErrNotInvalidated {
+		t.Errorf("second Restore() error = %v, want %v", err, ErrNotInvalidated)
+	}
+}
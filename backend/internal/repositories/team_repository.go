@@ -127,12 +127,6 @@ func (r *TeamRepository) RemoveMemberFromTeam(teamID, userID string) error {
 	return err
 }
 
-func (r *TeamRepository) UpdateTeamScore(teamID string, points int) error {
-	query := `UPDATE teams SET score = score + ?, updated_at = ? WHERE id=?`
-	_, err := r.db.Exec(query, points, time.Now().Format(time.RFC3339), teamID)
-	return err
-}
-
 func (r *TeamRepository) GetAllTeamsWithScores() ([]models.Team, error) {
 	query := fmt.Sprintf("SELECT %s FROM teams ORDER BY score DESC", r.selectTeamFields())
 	rows, err := r.db.Query(query)
@@ -13,13 +13,16 @@ import (
 	"github.com/Uttam-Mahata/RootAccess/backend/internal/middleware"
 	"github.com/Uttam-Mahata/RootAccess/backend/internal/repositories"
 	"github.com/Uttam-Mahata/RootAccess/backend/internal/services"
+	"github.com/Uttam-Mahata/RootAccess/backend/internal/storage"
 	websocketPkg "github.com/Uttam-Mahata/RootAccess/backend/internal/websocket"
 	awsconfig "github.com/aws/aws-sdk-go-v2/config"
 	"github.com/gin-gonic/gin"
 	"github.com/redis/go-redis/v9"
 )
 
-func SetupRouter(cfg *config.Config) *gin.Engine {
+// SetupRouter builds the API router and the challenge release scheduler sharing its WebSocket hub.
+// The caller decides how the scheduler runs (background loop or Lambda tick).
+func SetupRouter(cfg *config.Config) (*gin.Engine, *services.ChallengeScheduler) {
 	r := gin.Default()
 
 	// Configure trusted proxies for accurate client IP detection.
@@ -68,6 +71,7 @@ func SetupRouter(cfg *config.Config) *gin.Engine {
 		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
 		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
 		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE")
+		c.Writer.Header().Set("Access-Control-Expose-Headers", "X-Next-Cursor, X-Total-Count")
 
 		if c.Request.Method == "OPTIONS" {
 			c.AbortWithStatus(204)
@@ -95,25 +99,57 @@ func SetupRouter(cfg *config.Config) *gin.Engine {
 	scoreAdjustmentRepo := repositories.NewScoreAdjustmentRepository(database.TursoDB)
 	teamContestRegistrationRepo := repositories.NewTeamContestRegistrationRepository(database.TursoDB)
 	contestSolveRepo := repositories.NewContestSolveRepository(database.TursoDB)
+	cheatingIncidentRepo := repositories.NewCheatingIncidentRepository(database.TursoDB)
+	attachmentRepo := repositories.NewAttachmentRepository(database.TursoDB)
+	challengeRevisionRepo := repositories.NewChallengeRevisionRepository(database.TursoDB)
+	challengeReviewRepo := repositories.NewChallengeReviewRepository(database.TursoDB)
+	challengeFeedbackRepo := repositories.NewChallengeFeedbackRepository(database.TursoDB)
+	attemptResetRepo := repositories.NewAttemptResetRepository(database.TursoDB)
+	manualSubmissionRepo := repositories.NewManualSubmissionRepository(database.TursoDB)
+	nearMissRepo := repositories.NewNearMissRepository(database.TursoDB)
+	scoreLedgerRepo := repositories.NewScoreLedgerRepository(database.TursoDB)
 	// Indexes removed, Turso schema handles it
 
 	// Services
 	emailService := services.NewEmailService(cfg)
 	authService := services.NewAuthService(userRepo, emailService, cfg)
 	oauthService := services.NewOAuthService(userRepo, cfg)
-	challengeService := services.NewChallengeService(challengeRepo, submissionRepo, teamRepo, contestSolveRepo)
-	scoreboardService := services.NewScoreboardService(userRepo, submissionRepo, challengeRepo, teamRepo, contestRepo, scoreAdjustmentRepo, contestEntityRepo, contestRoundRepo, roundChallengeRepo, teamContestRegistrationRepo, contestSolveRepo)
-	teamService := services.NewTeamService(teamRepo, teamInvitationRepo, userRepo, emailService, submissionRepo, challengeRepo)
+	scoreLedgerService := services.NewScoreLedgerService(scoreLedgerRepo, challengeRepo, submissionRepo, contestSolveRepo, contestEntityRepo, teamContestRegistrationRepo, manualSubmissionRepo, hintRepo, scoreAdjustmentRepo)
+	if err := scoreLedgerService.SeedIfEmpty(); err != nil {
+		log.Printf("Warning: failed to seed the score ledger: %v", err)
+	}
+	challengeService := services.NewChallengeService(challengeRepo, submissionRepo, teamRepo, contestSolveRepo, cheatingIncidentRepo, challengeRevisionRepo, challengeReviewRepo, attemptResetRepo, manualSubmissionRepo, contestEntityRepo, roundChallengeRepo, nearMissRepo, scoreLedgerService, cfg.DynamicFlagSecret)
+	scoreboardService := services.NewScoreboardService(userRepo, submissionRepo, challengeRepo, teamRepo, contestRepo, scoreLedgerRepo, contestEntityRepo, contestRoundRepo, roundChallengeRepo, teamContestRegistrationRepo, hintRepo)
+	teamService := services.NewTeamService(teamRepo, teamInvitationRepo, userRepo, emailService, scoreLedgerRepo)
 	notificationService := services.NewNotificationService(notificationRepo)
-	hintService := services.NewHintService(hintRepo, challengeRepo, teamRepo)
+	hintService := services.NewHintService(hintRepo, challengeRepo, teamRepo, scoreLedgerService)
 	contestService := services.NewContestService(contestRepo)
-	contestAdminService := services.NewContestAdminService(contestRepo, contestEntityRepo, contestRoundRepo, roundChallengeRepo, challengeRepo, teamContestRegistrationRepo)
+	contestAdminService := services.NewContestAdminService(contestRepo, contestEntityRepo, contestRoundRepo, roundChallengeRepo, challengeRepo, teamContestRegistrationRepo, submissionRepo)
 	contestRegistrationService := services.NewContestRegistrationService(contestEntityRepo, teamContestRegistrationRepo, teamRepo)
 	writeupService := services.NewWriteupService(writeupRepo, submissionRepo, teamRepo)
 	auditLogService := services.NewAuditLogService(auditLogRepo)
 	achievementService := services.NewAchievementService(achievementRepo, submissionRepo, challengeRepo)
-	analyticsService := services.NewAnalyticsService(userRepo, submissionRepo, challengeRepo, teamRepo, scoreAdjustmentRepo)
-	activityService := services.NewActivityService(userRepo, submissionRepo, challengeRepo, achievementRepo, teamRepo)
+	analyticsService := services.NewAnalyticsService(userRepo, submissionRepo, challengeRepo, teamRepo, scoreLedgerRepo, challengeFeedbackRepo, nearMissRepo)
+	activityService := services.NewActivityService(userRepo, submissionRepo, challengeRepo, achievementRepo, teamRepo, scoreLedgerRepo)
+	cheatingIncidentService := services.NewCheatingIncidentService(cheatingIncidentRepo)
+	challengeReviewService := services.NewChallengeReviewService(challengeRepo, challengeReviewRepo)
+	challengeFeedbackService := services.NewChallengeFeedbackService(challengeFeedbackRepo, challengeRepo, submissionRepo, teamRepo)
+	judgingService := services.NewJudgingService(manualSubmissionRepo, challengeRepo, submissionRepo, teamRepo, contestSolveRepo, challengeService, scoreLedgerService)
+	reconciliationService := services.NewReconciliationService(challengeRepo, contestSolveRepo, teamRepo, scoreLedgerRepo, scoreLedgerService)
+	submissionInvalidationService := services.NewSubmissionInvalidationService(submissionRepo, challengeRepo, contestSolveRepo, teamRepo, scoreLedgerService)
+
+	// Attachment storage provider
+	storageProvider, err := storage.NewProvider(storage.Options{
+		Type:       cfg.StorageType,
+		LocalPath:  cfg.StorageLocalPath,
+		PublicURL:  cfg.PublicAPIURL,
+		SigningKey: cfg.StorageSigningKey,
+	})
+	if err != nil {
+		log.Printf("Warning: attachment storage disabled: %v", err)
+	}
+	localStorage, _ := storageProvider.(*storage.LocalProvider)
+	attachmentService := services.NewAttachmentService(attachmentRepo, challengeRepo, challengeService, storageProvider, int64(cfg.AttachmentMaxSizeMB)<<20)
 
 	// WebSocket hub selection
 	var wsRedis *redis.Client
@@ -138,6 +174,8 @@ func SetupRouter(cfg *config.Config) *gin.Engine {
 	}
 	go wsHub.Run()
 
+	challengeScheduler := services.NewChallengeScheduler(challengeRepo, challengeReviewRepo, notificationService, wsHub)
+
 	// Handlers
 	authHandler := handlers.NewAuthHandler(authService)
 	var authRedis *redis.Client
@@ -149,8 +187,8 @@ func SetupRouter(cfg *config.Config) *gin.Engine {
 	scoreboardHandler := handlers.NewScoreboardHandler(scoreboardService, contestEntityRepo, contestRepo)
 	teamHandler := handlers.NewTeamHandler(teamService)
 	notificationHandler := handlers.NewNotificationHandler(notificationService, wsHub)
-	profileHandler := handlers.NewProfileHandler(userRepo, submissionRepo, challengeRepo, teamRepo)
-	hintHandler := handlers.NewHintHandler(hintService)
+	profileHandler := handlers.NewProfileHandler(userRepo, submissionRepo, challengeRepo, teamRepo, scoreLedgerRepo)
+	hintHandler := handlers.NewHintHandler(hintService, contestAdminService)
 	contestHandler := handlers.NewContestHandler(contestService)
 	contestAdminHandler := handlers.NewContestAdminHandler(contestAdminService)
 	contestRegistrationHandler := handlers.NewContestRegistrationHandler(contestRegistrationService, teamService)
@@ -162,8 +200,18 @@ func SetupRouter(cfg *config.Config) *gin.Engine {
 	wsHandler := handlers.NewWebSocketHandler(wsHub, cfg)
 	bulkChallengeHandler := handlers.NewBulkChallengeHandler(challengeService)
 	leaderboardHandler := handlers.NewLeaderboardHandler(scoreboardService)
-	adminUserHandler := handlers.NewAdminUserHandlerWithRepos(userRepo, teamRepo, submissionRepo, scoreAdjustmentRepo)
-	adminTeamHandler := handlers.NewAdminTeamHandler(teamRepo, userRepo, submissionRepo, teamInvitationRepo, scoreAdjustmentRepo)
+	adminUserHandler := handlers.NewAdminUserHandlerWithRepos(userRepo, teamRepo, submissionRepo, scoreLedgerService)
+	adminTeamHandler := handlers.NewAdminTeamHandler(teamRepo, userRepo, submissionRepo, teamInvitationRepo, scoreLedgerService)
+	cheatingIncidentHandler := handlers.NewCheatingIncidentHandler(cheatingIncidentService)
+	schedulerHandler := handlers.NewSchedulerHandler(challengeScheduler, cfg.SchedulerToken)
+	challengeRevisionHandler := handlers.NewChallengeRevisionHandler(challengeService)
+	challengeReviewHandler := handlers.NewChallengeReviewHandler(challengeReviewService)
+	challengeFeedbackHandler := handlers.NewChallengeFeedbackHandler(challengeFeedbackService)
+	judgingHandler := handlers.NewJudgingHandler(judgingService, wsHub)
+	submissionHandler := handlers.NewSubmissionHandler(submissionInvalidationService, wsHub)
+	reconciliationHandler := handlers.NewReconciliationHandler(reconciliationService)
+	scoringHandler := handlers.NewScoringHandler()
+	attachmentHandler := handlers.NewAttachmentHandler(attachmentService, contestAdminService, teamRepo, localStorage)
 
 	// Define routes in a helper to apply to both root and /api
 	registerRoutes := func(rg *gin.RouterGroup) {
@@ -203,6 +251,11 @@ func SetupRouter(cfg *config.Config) *gin.Engine {
 
 		rg.GET("/users/:username/profile", profileHandler.GetUserProfile)
 
+		// Signed, time-limited attachment downloads (local storage provider)
+		rg.GET(storage.LocalFilesRoute+"/:bucket/*filename", attachmentHandler.ServeLocalFile)
+
+		rg.POST("/scheduler/tick", schedulerHandler.Tick)
+
 		rg.GET("/health", func(c *gin.Context) {
 			c.JSON(200, gin.H{"status": "healthy", "time": time.Now().Format(time.RFC3339)})
 		})
@@ -231,14 +284,21 @@ func SetupRouter(cfg *config.Config) *gin.Engine {
 
 			protected.GET("/challenges/:id", challengeHandler.GetChallengeByID)
 			protected.GET("/challenges/:id/solves", challengeHandler.GetChallengeSolves)
+			protected.GET("/challenges/:id/feedback", challengeFeedbackHandler.GetMyFeedback)
+			protected.POST("/challenges/:id/feedback", challengeFeedbackHandler.SubmitFeedback)
+			protected.GET("/challenges/:id/answers", judgingHandler.GetOwnAnswers)
 			protected.POST("/challenges/:id/submit", middleware.RateLimitMiddleware(5, time.Minute), challengeHandler.SubmitFlag)
 			protected.GET("/challenges/:id/hints", hintHandler.GetHints)
+			protected.GET("/challenges/:id/attachments", attachmentHandler.GetAttachments)
+			protected.GET("/challenges/:id/attachments/:attachmentId/download", attachmentHandler.GetDownloadURL)
 			protected.POST("/challenges/:id/hints/:hintId/reveal", hintHandler.RevealHint)
 			protected.POST("/challenges/:id/writeups", writeupHandler.CreateWriteup)
 			protected.GET("/challenges/:id/writeups", writeupHandler.GetWriteups)
 			protected.GET("/writeups/my", writeupHandler.GetMyWriteups)
 			protected.GET("/activity/me", activityHandler.GetMyActivity)
 			protected.GET("/achievements/me", achievementHandler.GetMyAchievements)
+			protected.GET("/scoreboard/breakdown", scoreboardHandler.GetMyScoreBreakdown)
+			protected.GET("/scoreboard/teams/breakdown", scoreboardHandler.GetMyTeamScoreBreakdown)
 			protected.PUT("/writeups/:id", writeupHandler.UpdateWriteup)
 			protected.POST("/writeups/:id/upvote", writeupHandler.ToggleUpvote)
 
@@ -262,16 +322,53 @@ func SetupRouter(cfg *config.Config) *gin.Engine {
 				teams.POST("/:id/regenerate-code", teamHandler.RegenerateInviteCode)
 			}
 
-			admin := protected.Group("/admin")
+			// Challenge authoring is shared by admins and authors; authors only reach
+			// the challenges they own. Everything else under /admin is admin-only.
+			staff := protected.Group("/admin")
+			staff.Use(middleware.StaffMiddleware())
+			staff.Use(middleware.AuditMiddleware(auditLogService))
+			{
+				staff.GET("/challenges", challengeHandler.GetAllChallengesWithFlags)
+				staff.POST("/challenges", challengeHandler.CreateChallenge)
+				staff.GET("/scoring/strategies", scoringHandler.ListStrategies)
+				staff.GET("/scoring/preview", scoringHandler.PreviewCurve)
+
+				owned := staff.Group("/challenges/:id")
+				owned.Use(middleware.ChallengeOwnerMiddleware(challengeRepo))
+				liveEdit := middleware.ChallengeEditMiddleware(challengeRepo)
+				{
+					owned.PUT("", liveEdit, challengeHandler.UpdateChallenge)
+					owned.GET("/stats", analyticsHandler.GetChallengeStats)
+					owned.POST("/test-flag", challengeHandler.TestFlag)
+					owned.PUT("/official-writeup", challengeHandler.UpdateOfficialWriteup)
+					owned.POST("/official-writeup/publish", challengeHandler.PublishOfficialWriteup)
+					owned.GET("/workflow", challengeReviewHandler.GetWorkflow)
+					owned.POST("/workflow", challengeReviewHandler.TransitionChallenge)
+					owned.GET("/revisions", challengeRevisionHandler.GetRevisions)
+					owned.GET("/revisions/diff", challengeRevisionHandler.DiffRevisions)
+					owned.GET("/revisions/:revision", challengeRevisionHandler.GetRevision)
+					owned.POST("/revisions/:revision/rollback", liveEdit, challengeRevisionHandler.RollbackChallenge)
+					owned.GET("/attachments", attachmentHandler.GetAttachments)
+					owned.POST("/attachments", liveEdit, attachmentHandler.UploadAttachment)
+					owned.DELETE("/attachments/:attachmentId", liveEdit, attachmentHandler.DeleteAttachment)
+				}
+			}
+
+			admin := staff.Group("")
 			admin.Use(middleware.AdminMiddleware())
-			admin.Use(middleware.AuditMiddleware(auditLogService))
 			{
-				admin.GET("/challenges", challengeHandler.GetAllChallengesWithFlags)
-				admin.POST("/challenges", challengeHandler.CreateChallenge)
-				admin.PUT("/challenges/:id", challengeHandler.UpdateChallenge)
+				admin.GET("/challenges/prerequisite-graph", challengeHandler.GetPrerequisiteGraph)
+				admin.GET("/challenges/review-queue", challengeReviewHandler.GetReviewQueue)
+				admin.POST("/challenges/:id/attempts/reset", challengeHandler.ResetAttempts)
 				admin.DELETE("/challenges/:id", challengeHandler.DeleteChallenge)
-				admin.PUT("/challenges/:id/official-writeup", challengeHandler.UpdateOfficialWriteup)
-				admin.POST("/challenges/:id/official-writeup/publish", challengeHandler.PublishOfficialWriteup)
+				admin.GET("/judging", judgingHandler.GetQueue)
+				admin.GET("/judging/:id", judgingHandler.GetSubmission)
+				admin.POST("/judging/:id", judgingHandler.JudgeSubmission)
+				admin.GET("/submissions/invalidated", submissionHandler.GetInvalidatedSubmissions)
+				admin.POST("/submissions/:id/invalidate", submissionHandler.InvalidateSubmission)
+				admin.POST("/submissions/:id/restore", submissionHandler.RestoreSubmission)
+				admin.GET("/reconciliation", reconciliationHandler.PreviewReconciliation)
+				admin.POST("/reconciliation", reconciliationHandler.ApplyReconciliation)
 				admin.GET("/notifications", notificationHandler.GetAllNotifications)
 				admin.POST("/notifications", notificationHandler.CreateNotification)
 				admin.PUT("/notifications/:id", notificationHandler.UpdateNotification)
@@ -285,6 +382,7 @@ func SetupRouter(cfg *config.Config) *gin.Engine {
 				admin.GET("/contest-entities/:id", contestAdminHandler.GetContest)
 				admin.PUT("/contest-entities/:id", contestAdminHandler.UpdateContest)
 				admin.DELETE("/contest-entities/:id", contestAdminHandler.DeleteContest)
+				admin.POST("/contest-entities/:id/test-flags", challengeHandler.TestContestFlags)
 				admin.GET("/contest-entities/:id/rounds", contestAdminHandler.ListRounds)
 				admin.POST("/contest-entities/:id/rounds", contestAdminHandler.CreateRound)
 				admin.PUT("/contest-entities/:id/rounds/:roundId", contestAdminHandler.UpdateRound)
@@ -297,6 +395,11 @@ func SetupRouter(cfg *config.Config) *gin.Engine {
 				admin.DELETE("/writeups/:id", writeupHandler.DeleteWriteup)
 				admin.GET("/audit-logs", auditLogHandler.GetAuditLogs)
 				admin.GET("/analytics", analyticsHandler.GetPlatformAnalytics)
+				admin.GET("/analytics/feedback", analyticsHandler.GetFeedbackStats)
+				admin.GET("/analytics/feedback/challenges/:id", analyticsHandler.GetChallengeFeedback)
+				admin.GET("/analytics/feedback/contests/:id", analyticsHandler.GetContestFeedbackStats)
+				admin.GET("/analytics/near-misses", analyticsHandler.GetNearMisses)
+				admin.GET("/analytics/near-misses/challenges/:id", analyticsHandler.GetChallengeNearMisses)
 				admin.POST("/challenges/import", bulkChallengeHandler.ImportChallenges)
 				admin.GET("/challenges/export", bulkChallengeHandler.ExportChallenges)
 				admin.POST("/challenges/:id/duplicate", bulkChallengeHandler.DuplicateChallenge)
@@ -311,8 +414,12 @@ func SetupRouter(cfg *config.Config) *gin.Engine {
 				admin.PUT("/teams/:id", adminTeamHandler.UpdateTeam)
 				admin.PUT("/teams/:id/leader", adminTeamHandler.UpdateTeamLeader)
 				admin.POST("/teams/:id/score-adjust", adminTeamHandler.AdjustTeamScore)
+				admin.GET("/teams/:id/ledger", adminTeamHandler.GetTeamLedger)
 				admin.DELETE("/teams/:id/members/:memberId", adminTeamHandler.RemoveMember)
 				admin.DELETE("/teams/:id", adminTeamHandler.DeleteTeam)
+				admin.POST("/scheduler/tick", schedulerHandler.AdminTick)
+				admin.GET("/cheating-incidents", cheatingIncidentHandler.GetIncidents)
+				admin.PUT("/cheating-incidents/:id/review", cheatingIncidentHandler.ReviewIncident)
 			}
 		}
 	}
@@ -324,5 +431,5 @@ func SetupRouter(cfg *config.Config) *gin.Engine {
 
 	registerSwagger(r)
 
-	return r
+	return r, challengeScheduler
 }
@@ -19,6 +19,7 @@ type ActivityService struct {
 	challengeRepo   *repositories.ChallengeRepository
 	achievementRepo *repositories.AchievementRepository
 	teamRepo        *repositories.TeamRepository
+	ledgerRepo      *repositories.ScoreLedgerRepository
 }
 
 func NewActivityService(
@@ -27,6 +28,7 @@ func NewActivityService(
 	challengeRepo *repositories.ChallengeRepository,
 	achievementRepo *repositories.AchievementRepository,
 	teamRepo *repositories.TeamRepository,
+	ledgerRepo *repositories.ScoreLedgerRepository,
 ) *ActivityService {
 	return &ActivityService{
 		userRepo:        userRepo,
@@ -34,6 +36,7 @@ func NewActivityService(
 		challengeRepo:   challengeRepo,
 		achievementRepo: achievementRepo,
 		teamRepo:        teamRepo,
+		ledgerRepo:      ledgerRepo,
 	}
 }
 
@@ -58,8 +61,13 @@ func (s *ActivityService) GetUserActivity(userID string) (*models.UserActivity,
 		challengeMap[challenges[i].ID] = &challenges[i]
 	}
 
-	// Calculate total points and build recent solves
-	totalPoints := 0
+	ledger, err := s.ledgerRepo.GetByUser(userID, "")
+	if err != nil {
+		return nil, err
+	}
+	challengePoints := models.LedgerChallengePoints(ledger)
+
+	// Build recent solves, with points as credited in the score ledger
 	solvedByCategory := make(map[string]int)
 	pointsByCategory := make(map[string]int)
 
@@ -70,8 +78,7 @@ func (s *ActivityService) GetUserActivity(userID string) (*models.UserActivity,
 		if !ok {
 			continue
 		}
-		points := c.CurrentPoints()
-		totalPoints += points
+		points := challengePoints[c.ID]
 		solvedByCategory[c.Category]++
 		pointsByCategory[c.Category] += points
 
@@ -130,7 +137,7 @@ func (s *ActivityService) GetUserActivity(userID string) (*models.UserActivity,
 		UserID:           userID,
 		Username:         user.Username,
 		TotalSolves:      len(correctSubs),
-		TotalPoints:      totalPoints,
+		TotalPoints:      models.LedgerTotal(ledger),
 		CategoryProgress: categoryProgress,
 		RecentSolves:     recentSolves,
 		Achievements:     achievements,
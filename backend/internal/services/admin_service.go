@@ -81,7 +81,36 @@ func (s *AdminService) PromoteToAdmin(usernameOrEmail string) (*models.User, err
 	return user, nil
 }
 
-// DemoteToUser demotes an admin to regular user role
+// PromoteToAuthor gives an existing user the challenge author role
+func (s *AdminService) PromoteToAuthor(usernameOrEmail string) (*models.User, error) {
+	// Try to find by username first
+	user, err := s.userRepo.FindByUsername(usernameOrEmail)
+	if err != nil {
+		// Try to find by email
+		user, err = s.userRepo.FindByEmail(usernameOrEmail)
+		if err != nil {
+			return nil, errors.New("user not found")
+		}
+	}
+
+	switch user.Role {
+	case models.RoleAuthor:
+		return nil, errors.New("user is already an author")
+	case models.RoleAdmin:
+		return nil, errors.New("user is an admin, demote them first")
+	}
+
+	user.Role = models.RoleAuthor
+	user.UpdatedAt = time.Now()
+
+	if err := s.userRepo.UpdateUser(user); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// DemoteToUser demotes an admin or author to regular user role
 func (s *AdminService) DemoteToUser(usernameOrEmail string) (*models.User, error) {
 	// Try to find by username first
 	user, err := s.userRepo.FindByUsername(usernameOrEmail)
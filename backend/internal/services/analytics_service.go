@@ -16,7 +16,9 @@ type AnalyticsService struct {
 	submissionRepo *repositories.SubmissionRepository
 	challengeRepo  *repositories.ChallengeRepository
 	teamRepo       *repositories.TeamRepository
-	adjustmentRepo *repositories.ScoreAdjustmentRepository
+	ledgerRepo     *repositories.ScoreLedgerRepository
+	feedbackRepo   *repositories.ChallengeFeedbackRepository
+	nearMissRepo   *repositories.NearMissRepository
 }
 
 func NewAnalyticsService(
@@ -24,14 +26,18 @@ func NewAnalyticsService(
 	submissionRepo *repositories.SubmissionRepository,
 	challengeRepo *repositories.ChallengeRepository,
 	teamRepo *repositories.TeamRepository,
-	adjustmentRepo *repositories.ScoreAdjustmentRepository,
+	ledgerRepo *repositories.ScoreLedgerRepository,
+	feedbackRepo *repositories.ChallengeFeedbackRepository,
+	nearMissRepo *repositories.NearMissRepository,
 ) *AnalyticsService {
 	return &AnalyticsService{
 		userRepo:       userRepo,
 		submissionRepo: submissionRepo,
 		challengeRepo:  challengeRepo,
 		teamRepo:       teamRepo,
-		adjustmentRepo: adjustmentRepo,
+		ledgerRepo:     ledgerRepo,
+		feedbackRepo:   feedbackRepo,
+		nearMissRepo:   nearMissRepo,
 	}
 }
 
@@ -74,6 +80,8 @@ func (s *AnalyticsService) GetPlatformAnalytics() (*models.AdminAnalytics, error
 		return nil, err
 	}
 
+	totalInvalidFormat, _ := s.submissionRepo.CountInvalidFormatSubmissions()
+
 	var successRate float64
 	if totalSubmissions > 0 {
 		successRate = float64(totalCorrect) / float64(totalSubmissions)
@@ -110,13 +118,13 @@ func (s *AnalyticsService) GetPlatformAnalytics() (*models.AdminAnalytics, error
 		newTeamsThisWeek++
 	}
 
-	// Submissions and solves today
+	// Submissions and solves today; invalidated solves do not count as solves
 	submissionsToday := 0
 	solvesToday := 0
 	recentSubs, _ := s.submissionRepo.GetSubmissionsSince(today)
 	for _, sub := range recentSubs {
 		submissionsToday++
-		if sub.IsCorrect {
+		if sub.IsCorrect && sub.InvalidatedAt == nil {
 			solvesToday++
 		}
 	}
@@ -189,7 +197,7 @@ func (s *AnalyticsService) GetPlatformAnalytics() (*models.AdminAnalytics, error
 	recentActivity := make([]models.RecentActivityEntry, 0, len(recentActivitySubs))
 	for _, sub := range recentActivitySubs {
 		action := "attempted"
-		if sub.IsCorrect {
+		if sub.IsCorrect && sub.InvalidatedAt == nil {
 			action = "solved"
 		}
 		recentActivity = append(recentActivity, models.RecentActivityEntry{
@@ -306,18 +314,16 @@ func (s *AnalyticsService) GetPlatformAnalytics() (*models.AdminAnalytics, error
 		})
 	}
 
-	// Top users - calculate scores from correct submissions
-	// Deduplicate submissions per user-challenge to avoid counting the same solve multiple times
-	userScores := make(map[string]int)
+	// Top users - scores come from the score ledger; solves are deduplicated per
+	// user-challenge to avoid counting the same solve multiple times
+	userScores, err := s.ledgerRepo.GetUserTotals()
+	if err != nil {
+		return nil, err
+	}
 	userSolveCounts := make(map[string]int)
 	userChallengeSolved := make(map[string]map[string]bool) // userID -> challengeID -> solved
-	challengeScores := make(map[string]int)
-	for _, c := range challenges {
-		challengeScores[c.ID] = c.CurrentPoints()
-	}
-
 	for _, sub := range allSubmissions {
-		if sub.IsCorrect {
+		if sub.IsCorrect && sub.InvalidatedAt == nil {
 			userID := sub.UserID
 			challengeID := sub.ChallengeID
 
@@ -329,29 +335,11 @@ func (s *AnalyticsService) GetPlatformAnalytics() (*models.AdminAnalytics, error
 			// Only count each user-challenge pair once
 			if !userChallengeSolved[userID][challengeID] {
 				userChallengeSolved[userID][challengeID] = true
-				userScores[userID] += challengeScores[challengeID]
 				userSolveCounts[userID]++
 			}
 		}
 	}
 
-	// Apply manual user score adjustments (if any) to analytics as well
-	if s.adjustmentRepo != nil && len(userScores) > 0 {
-		userIDs := make([]string, 0, len(userScores))
-		for uid := range userScores {
-			if oid := uid; err == nil {
-				userIDs = append(userIDs, oid)
-			}
-		}
-		if len(userIDs) > 0 {
-			if deltas, err := s.adjustmentRepo.GetAdjustmentsForUsers(userIDs); err == nil {
-				for uid, delta := range deltas {
-					userScores[uid] += delta
-				}
-			}
-		}
-	}
-
 	type userScoreEntry struct {
 		ID         string
 		Username   string
@@ -390,6 +378,7 @@ func (s *AnalyticsService) GetPlatformAnalytics() (*models.AdminAnalytics, error
 		TotalChallenges:     int(totalChallenges),
 		TotalSubmissions:    int(totalSubmissions),
 		TotalCorrect:        int(totalCorrect),
+		TotalInvalidFormat:  int(totalInvalidFormat),
 		SuccessRate:         successRate,
 		ChallengePopularity: challengePopularity,
 		CategoryBreakdown:   categoryBreakdown,
@@ -424,3 +413,148 @@ func (s *AnalyticsService) GetPlatformAnalytics() (*models.AdminAnalytics, error
 
 	return result, nil
 }
+
+// GetFeedbackStats aggregates solver feedback per challenge, next to the official difficulty.
+// When contestID is set only feedback from solves in that contest is counted.
+func (s *AnalyticsService) GetFeedbackStats(contestID string) ([]models.ChallengeFeedbackStats, error) {
+	feedback, err := s.feedbackRepo.GetAll(contestID)
+	if err != nil {
+		return nil, err
+	}
+
+	byChallenge := make(map[string][]models.ChallengeFeedback)
+	var ids []string
+	for _, f := range feedback {
+		if _, ok := byChallenge[f.ChallengeID]; !ok {
+			ids = append(ids, f.ChallengeID)
+		}
+		byChallenge[f.ChallengeID] = append(byChallenge[f.ChallengeID], f)
+	}
+
+	challenges, err := s.challengeRepo.GetChallengesByIDs(ids, false)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := make([]models.ChallengeFeedbackStats, 0, len(challenges))
+	for _, ch := range challenges {
+		stats = append(stats, models.ChallengeFeedbackStats{
+			ChallengeID:     ch.ID,
+			Title:           ch.Title,
+			Category:        ch.Category,
+			Difficulty:      ch.Difficulty,
+			FeedbackSummary: models.SummarizeFeedback(byChallenge[ch.ID]),
+		})
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].ResponseCount > stats[j].ResponseCount
+	})
+	return stats, nil
+}
+
+// GetContestFeedbackStats aggregates solver feedback for a contest overall and per challenge
+func (s *AnalyticsService) GetContestFeedbackStats(contestID string) (*models.ContestFeedbackStats, error) {
+	feedback, err := s.feedbackRepo.GetAll(contestID)
+	if err != nil {
+		return nil, err
+	}
+	perChallenge, err := s.GetFeedbackStats(contestID)
+	if err != nil {
+		return nil, err
+	}
+	return &models.ContestFeedbackStats{
+		ContestID:       contestID,
+		FeedbackSummary: models.SummarizeFeedback(feedback),
+		Challenges:      perChallenge,
+	}, nil
+}
+
+// GetChallengeFeedback returns the aggregated ratings of a challenge together with every comment
+func (s *AnalyticsService) GetChallengeFeedback(challengeID string) (*models.ChallengeFeedbackStats, []models.ChallengeFeedback, error) {
+	challenge, err := s.challengeRepo.GetChallengeByID(challengeID)
+	if err != nil {
+		return nil, nil, err
+	}
+	feedback, err := s.feedbackRepo.GetByChallenge(challengeID)
+	if err != nil {
+		return nil, nil, err
+	}
+	stats := &models.ChallengeFeedbackStats{
+		ChallengeID:     challenge.ID,
+		Title:           challenge.Title,
+		Category:        challenge.Category,
+		Difficulty:      challenge.Difficulty,
+		FeedbackSummary: models.SummarizeFeedback(feedback),
+	}
+	return stats, feedback, nil
+}
+
+// GetChallengeStats returns attempt and solve figures for one challenge across all contests
+func (s *AnalyticsService) GetChallengeStats(challengeID string) (*models.ChallengeSolveStats, error) {
+	challenge, err := s.challengeRepo.GetChallengeByID(challengeID)
+	if err != nil {
+		return nil, err
+	}
+	submissions, err := s.submissionRepo.GetSubmissionsByChallenge(challengeID)
+	if err != nil {
+		return nil, err
+	}
+	feedback, err := s.feedbackRepo.GetByChallenge(challengeID)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &models.ChallengeSolveStats{
+		ChallengePopularity: models.ChallengePopularity{
+			ChallengeID:  challenge.ID,
+			Title:        challenge.Title,
+			Category:     challenge.Category,
+			SolveCount:   challenge.SolveCount,
+			AttemptCount: len(submissions),
+		},
+		Feedback: models.SummarizeFeedback(feedback),
+	}
+
+	solvers := make(map[string]bool)
+	for _, sub := range submissions {
+		if !sub.IsCorrect {
+			stats.WrongAttempts++
+			continue
+		}
+		key := sub.UserID
+		if sub.TeamID != "" {
+			key = "team:" + sub.TeamID
+		}
+		solvers[key] = true
+		solvedAt := sub.Timestamp
+		if stats.FirstSolveAt == nil {
+			stats.FirstSolveAt = &solvedAt
+		}
+		stats.LatestSolveAt = &solvedAt
+	}
+	stats.UniqueSolvers = len(solvers)
+	if stats.AttemptCount > 0 {
+		stats.SuccessRate = float64(stats.SolveCount) / float64(stats.AttemptCount)
+	}
+	return stats, nil
+}
+
+// GetNearMissSummary lists the challenges with near-miss submissions, most first
+func (s *AnalyticsService) GetNearMissSummary(contestID string) ([]models.NearMissSummary, error) {
+	return s.nearMissRepo.Summarize(contestID)
+}
+
+// GetNearMissReport returns every near miss on a challenge with a breakdown by kind,
+// accepted flag and difference
+func (s *AnalyticsService) GetNearMissReport(challengeID, contestID string) (*models.NearMissReport, error) {
+	challenge, err := s.challengeRepo.GetChallengeByID(challengeID)
+	if err != nil {
+		return nil, err
+	}
+	nearMisses, err := s.nearMissRepo.GetByChallenge(challengeID, contestID)
+	if err != nil {
+		return nil, err
+	}
+	report := models.SummarizeNearMisses(challenge.ID, challenge.Title, nearMisses)
+	return &report, nil
+}
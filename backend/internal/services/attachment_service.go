@@ -0,0 +1,178 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/Uttam-Mahata/RootAccess/backend/internal/models"
+	"github.com/Uttam-Mahata/RootAccess/backend/internal/repositories"
+	"github.com/Uttam-Mahata/RootAccess/backend/internal/storage"
+	"github.com/google/uuid"
+)
+
+// AttachmentBucket is the storage bucket holding challenge attachments
+const AttachmentBucket = "attachments"
+
+// attachmentURLTTL is how long a signed download URL stays valid
+const attachmentURLTTL = 10 * time.Minute
+
+type AttachmentService struct {
+	attachmentRepo   *repositories.AttachmentRepository
+	challengeRepo    *repositories.ChallengeRepository
+	challengeService *ChallengeService
+	provider         storage.StorageProvider
+	maxSize          int64
+}
+
+func NewAttachmentService(
+	attachmentRepo *repositories.AttachmentRepository,
+	challengeRepo *repositories.ChallengeRepository,
+	challengeService *ChallengeService,
+	provider storage.StorageProvider,
+	maxSize int64,
+) *AttachmentService {
+	return &AttachmentService{
+		attachmentRepo:   attachmentRepo,
+		challengeRepo:    challengeRepo,
+		challengeService: challengeService,
+		provider:         provider,
+		maxSize:          maxSize,
+	}
+}
+
+// reopenReview sends the challenge back to draft if it was signed off, since its files changed
+func (s *AttachmentService) reopenReview(challengeID, actorID string) {
+	if s.challengeService != nil {
+		s.challengeService.ReopenAfterChange(challengeID, actorID, "Attachments changed after being submitted for review")
+	}
+}
+
+// MaxSize returns the upload size limit in bytes
+func (s *AttachmentService) MaxSize() int64 {
+	return s.maxSize
+}
+
+// sanitizeFilename keeps only the base name with a conservative character set
+func sanitizeFilename(name string) string {
+	name = path.Base(strings.ReplaceAll(name, "\\", "/"))
+	var b strings.Builder
+	for _, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-', r == '_':
+			b.WriteRune(r)
+		case r == ' ':
+			b.WriteRune('_')
+		}
+	}
+	cleaned := strings.Trim(b.String(), ".")
+	if cleaned == "" {
+		cleaned = "file"
+	}
+	return cleaned
+}
+
+// ErrAttachmentTooLarge is returned when an upload exceeds the configured size limit
+var ErrAttachmentTooLarge = errors.New("file exceeds the maximum attachment size")
+
+// countingReader counts the bytes read through it
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// Upload streams a file for a challenge into storage and records its size and SHA-256
+// checksum, computed on the way through
+func (s *AttachmentService) Upload(ctx context.Context, challengeID, filename, contentType string, r io.Reader, uploadedBy string) (*models.ChallengeAttachment, error) {
+	if s.provider == nil {
+		return nil, errors.New("file storage is not configured")
+	}
+	if _, err := s.challengeRepo.GetChallengeByID(challengeID); err != nil {
+		return nil, errors.New("challenge not found")
+	}
+
+	name := sanitizeFilename(filename)
+	attachment := &models.ChallengeAttachment{
+		ID:          uuid.New().String(),
+		ChallengeID: challengeID,
+		Filename:    name,
+		ContentType: contentType,
+		UploadedBy:  uploadedBy,
+	}
+	attachment.StorageKey = challengeID + "/" + attachment.ID + "/" + name
+
+	if s.maxSize > 0 {
+		// One byte past the limit is enough to tell the file is too large
+		r = io.LimitReader(r, s.maxSize+1)
+	}
+	hash := sha256.New()
+	counter := &countingReader{r: io.TeeReader(r, hash)}
+	if err := s.provider.UploadFile(ctx, AttachmentBucket, attachment.StorageKey, counter); err != nil {
+		return nil, err
+	}
+	if s.maxSize > 0 && counter.n > s.maxSize {
+		_ = s.provider.DeleteFile(ctx, AttachmentBucket, attachment.StorageKey)
+		return nil, ErrAttachmentTooLarge
+	}
+	attachment.Size = counter.n
+	attachment.SHA256 = hex.EncodeToString(hash.Sum(nil))
+
+	if err := s.attachmentRepo.Create(attachment); err != nil {
+		_ = s.provider.DeleteFile(ctx, AttachmentBucket, attachment.StorageKey)
+		return nil, err
+	}
+	s.reopenReview(challengeID, uploadedBy)
+	return attachment, nil
+}
+
+// GetAttachments lists the attachments of a challenge
+func (s *AttachmentService) GetAttachments(challengeID string) ([]models.ChallengeAttachment, error) {
+	return s.attachmentRepo.GetByChallenge(challengeID)
+}
+
+// GetAttachment returns an attachment, ensuring it belongs to the given challenge
+func (s *AttachmentService) GetAttachment(challengeID, attachmentID string) (*models.ChallengeAttachment, error) {
+	attachment, err := s.attachmentRepo.FindByID(attachmentID)
+	if err != nil || attachment.ChallengeID != challengeID {
+		return nil, errors.New("attachment not found")
+	}
+	return attachment, nil
+}
+
+// GetDownloadURL returns a time-limited signed URL for the attachment
+func (s *AttachmentService) GetDownloadURL(ctx context.Context, attachment *models.ChallengeAttachment) (string, time.Time, error) {
+	if s.provider == nil {
+		return "", time.Time{}, errors.New("file storage is not configured")
+	}
+	expiresAt := time.Now().Add(attachmentURLTTL)
+	url, err := s.provider.GetDownloadURL(ctx, AttachmentBucket, attachment.StorageKey, attachmentURLTTL)
+	return url, expiresAt, err
+}
+
+// DeleteAttachment removes the attachment record and the stored file. actorID is recorded if
+// the removal sends the challenge back to draft.
+func (s *AttachmentService) DeleteAttachment(ctx context.Context, challengeID, attachmentID, actorID string) error {
+	attachment, err := s.GetAttachment(challengeID, attachmentID)
+	if err != nil {
+		return err
+	}
+	if err := s.attachmentRepo.Delete(attachment.ID); err != nil {
+		return err
+	}
+	s.reopenReview(challengeID, actorID)
+	if s.provider != nil {
+		return s.provider.DeleteFile(ctx, AttachmentBucket, attachment.StorageKey)
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Uttam-Mahata/RootAccess/backend/internal/models"
+	"github.com/Uttam-Mahata/RootAccess/backend/internal/repositories"
+)
+
+type ChallengeFeedbackService struct {
+	feedbackRepo   *repositories.ChallengeFeedbackRepository
+	challengeRepo  *repositories.ChallengeRepository
+	submissionRepo *repositories.SubmissionRepository
+	teamRepo       *repositories.TeamRepository
+}
+
+func NewChallengeFeedbackService(
+	feedbackRepo *repositories.ChallengeFeedbackRepository,
+	challengeRepo *repositories.ChallengeRepository,
+	submissionRepo *repositories.SubmissionRepository,
+	teamRepo *repositories.TeamRepository,
+) *ChallengeFeedbackService {
+	return &ChallengeFeedbackService{
+		feedbackRepo:   feedbackRepo,
+		challengeRepo:  challengeRepo,
+		submissionRepo: submissionRepo,
+		teamRepo:       teamRepo,
+	}
+}
+
+// SubmitFeedback stores a solver's ratings for a challenge (user's team must have solved it).
+// Users without a team must have solved it themselves. Submitting again replaces the earlier entry.
+func (s *ChallengeFeedbackService) SubmitFeedback(userID, challengeID string, difficulty, quality int, comment string) (*models.ChallengeFeedback, error) {
+	if !models.IsValidFeedbackRating(difficulty) || !models.IsValidFeedbackRating(quality) {
+		return nil, fmt.Errorf("ratings must be between %d and %d", models.FeedbackMinRating, models.FeedbackMaxRating)
+	}
+	comment = strings.TrimSpace(comment)
+	if len(comment) > models.FeedbackMaxCommentLength {
+		return nil, fmt.Errorf("comment must be at most %d characters", models.FeedbackMaxCommentLength)
+	}
+
+	if _, err := s.challengeRepo.GetChallengeByID(challengeID); err != nil {
+		return nil, err
+	}
+
+	feedback := &models.ChallengeFeedback{
+		ChallengeID:      challengeID,
+		UserID:           userID,
+		DifficultyRating: difficulty,
+		QualityRating:    quality,
+		Comment:          comment,
+	}
+
+	var solve *models.Submission
+	team, _ := s.teamRepo.FindTeamByMemberID(userID)
+	if team != nil {
+		feedback.TeamID = team.ID
+		solve, _ = s.submissionRepo.FindByChallengeAndTeam(challengeID, team.ID)
+	} else {
+		solve, _ = s.submissionRepo.FindByChallengeAndUser(challengeID, userID)
+	}
+	if solve == nil || !solve.IsCorrect {
+		return nil, errors.New("only solvers can leave feedback on a challenge")
+	}
+	feedback.ContestID = solve.ContestID
+
+	if err := s.feedbackRepo.Upsert(feedback); err != nil {
+		return nil, err
+	}
+	return feedback, nil
+}
+
+// GetUserFeedback returns the feedback a user left on a challenge
+func (s *ChallengeFeedbackService) GetUserFeedback(userID, challengeID string) (*models.ChallengeFeedback, error) {
+	feedback, err := s.feedbackRepo.FindByUserAndChallenge(userID, challengeID)
+	if err != nil {
+		return nil, errors.New("feedback not found")
+	}
+	return feedback, nil
+}
@@ -0,0 +1,110 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/Uttam-Mahata/RootAccess/backend/internal/models"
+	"github.com/Uttam-Mahata/RootAccess/backend/internal/repositories"
+)
+
+// ErrSelfReview is returned when an author tries to approve or reject their own challenge
+var ErrSelfReview = errors.New("a challenge must be reviewed by an admin other than its author")
+
+// ChallengeReviewService moves challenges through the authoring workflow
+// (draft -> in_review -> approved -> published) and records every transition.
+type ChallengeReviewService struct {
+	challengeRepo *repositories.ChallengeRepository
+	reviewRepo    *repositories.ChallengeReviewRepository
+}
+
+func NewChallengeReviewService(
+	challengeRepo *repositories.ChallengeRepository,
+	reviewRepo *repositories.ChallengeReviewRepository,
+) *ChallengeReviewService {
+	return &ChallengeReviewService{
+		challengeRepo: challengeRepo,
+		reviewRepo:    reviewRepo,
+	}
+}
+
+// Transition applies a workflow action to a challenge on behalf of actorID.
+// Approving and requesting changes must be done by someone other than the author
+// and the admin who submitted the challenge for review.
+func (s *ChallengeReviewService) Transition(challengeID, action, actorID, comment string) (*models.Challenge, error) {
+	challenge, err := s.challengeRepo.GetChallengeByID(challengeID)
+	if err != nil {
+		return nil, err
+	}
+
+	to, err := models.NextChallengeStatus(challenge.Status, action)
+	if err != nil {
+		return nil, err
+	}
+
+	comment = strings.TrimSpace(comment)
+	if action == models.ReviewActionRequestChanges && comment == "" {
+		return nil, errors.New("a comment is required when requesting changes")
+	}
+	if models.ActionRequiresSignOff(action) {
+		if actorID == challenge.AuthorID {
+			return nil, ErrSelfReview
+		}
+		submitter, err := s.reviewRepo.FindLatestActor(challengeID, models.ReviewActionSubmit)
+		if err != nil {
+			return nil, err
+		}
+		if actorID == submitter {
+			return nil, ErrSelfReview
+		}
+	}
+
+	moved, err := s.challengeRepo.TransitionStatus(challengeID, challenge.Status, to)
+	if err != nil {
+		return nil, err
+	}
+	if !moved {
+		return nil, errors.New("challenge status changed concurrently, reload and try again")
+	}
+
+	// An approved challenge with a schedule that already passed would be republished
+	// by the scheduler right away, so unpublishing drops the schedule.
+	if action == models.ReviewActionUnpublish && challenge.ScheduledAt != "" {
+		if t, err := time.Parse(time.RFC3339, challenge.ScheduledAt); err == nil && !t.After(time.Now()) {
+			if err := s.challengeRepo.ClearScheduledAt(challengeID); err != nil {
+				return nil, err
+			}
+			challenge.ScheduledAt = ""
+		}
+	}
+
+	review := &models.ChallengeReview{
+		ChallengeID: challengeID,
+		Action:      action,
+		FromStatus:  challenge.Status,
+		ToStatus:    to,
+		ActorID:     actorID,
+		Comment:     comment,
+	}
+	if err := s.reviewRepo.Create(review); err != nil {
+		return nil, err
+	}
+
+	challenge.Status = to
+	challenge.IsPublished = to == models.ChallengeStatusPublished
+	return challenge, nil
+}
+
+// GetHistory returns the workflow transitions of a challenge, oldest first
+func (s *ChallengeReviewService) GetHistory(challengeID string) ([]models.ChallengeReview, error) {
+	return s.reviewRepo.GetByChallenge(challengeID)
+}
+
+// GetQueue returns the challenges in a workflow state, e.g. those waiting for review
+func (s *ChallengeReviewService) GetQueue(status string) ([]models.Challenge, error) {
+	if !models.IsValidChallengeStatus(status) {
+		return nil, errors.New("invalid status")
+	}
+	return s.challengeRepo.GetChallengesByStatus(status)
+}
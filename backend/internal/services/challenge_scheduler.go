@@ -0,0 +1,156 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/Uttam-Mahata/RootAccess/backend/internal/models"
+	"github.com/Uttam-Mahata/RootAccess/backend/internal/repositories"
+	websocketPkg "github.com/Uttam-Mahata/RootAccess/backend/internal/websocket"
+)
+
+// SchedulerActor is recorded as the actor of reviews made by background runs. It is not a
+// user, so announcements from those runs are credited to the admin who approved the challenge.
+const SchedulerActor = "system"
+
+// ReleasedChallenge describes a challenge published by the scheduler
+type ReleasedChallenge struct {
+	ID          string `json:"id"`
+	Title       string `json:"title"`
+	Category    string `json:"category"`
+	ScheduledAt string `json:"scheduled_at"`
+}
+
+// ChallengeScheduler publishes approved challenges whose ScheduledAt has passed. It runs on a
+// ticker in long-running mode and through the tick endpoint (or EventBridge) on Lambda.
+type ChallengeScheduler struct {
+	challengeRepo       *repositories.ChallengeRepository
+	reviewRepo          *repositories.ChallengeReviewRepository
+	notificationService *NotificationService
+	wsHub               websocketPkg.Hub
+}
+
+func NewChallengeScheduler(
+	challengeRepo *repositories.ChallengeRepository,
+	reviewRepo *repositories.ChallengeReviewRepository,
+	notificationService *NotificationService,
+	wsHub websocketPkg.Hub,
+) *ChallengeScheduler {
+	return &ChallengeScheduler{
+		challengeRepo:       challengeRepo,
+		reviewRepo:          reviewRepo,
+		notificationService: notificationService,
+		wsHub:               wsHub,
+	}
+}
+
+// Tick publishes every due challenge, broadcasts challenge_released and posts an announcement.
+// actorID is stored as the announcement author unless it is SchedulerActor. Safe to run concurrently from several instances:
+// only the run that moves the challenge from approved to published announces the release.
+func (s *ChallengeScheduler) Tick(now time.Time, actorID string) ([]ReleasedChallenge, error) {
+	challenges, err := s.challengeRepo.GetScheduledUnpublishedChallenges()
+	if err != nil {
+		return nil, err
+	}
+
+	released := []ReleasedChallenge{}
+	for _, ch := range challenges {
+		scheduledAt, err := time.Parse(time.RFC3339, ch.ScheduledAt)
+		if err != nil {
+			log.Printf("Scheduler: challenge %s has invalid scheduled_at %q", ch.ID, ch.ScheduledAt)
+			continue
+		}
+		if scheduledAt.After(now) {
+			continue
+		}
+
+		published, err := s.challengeRepo.PublishScheduledChallenge(ch.ID)
+		if err != nil {
+			log.Printf("Scheduler: failed to publish challenge %s: %v", ch.ID, err)
+			continue
+		}
+		if !published {
+			continue
+		}
+
+		if s.reviewRepo != nil {
+			review := &models.ChallengeReview{
+				ChallengeID: ch.ID,
+				Action:      models.ReviewActionPublish,
+				FromStatus:  models.ChallengeStatusApproved,
+				ToStatus:    models.ChallengeStatusPublished,
+				ActorID:     actorID,
+				Comment:     "Released at scheduled time",
+			}
+			if err := s.reviewRepo.Create(review); err != nil {
+				log.Printf("Scheduler: failed to record release of challenge %s: %v", ch.ID, err)
+			}
+		}
+
+		rc := ReleasedChallenge{ID: ch.ID, Title: ch.Title, Category: ch.Category, ScheduledAt: ch.ScheduledAt}
+		released = append(released, rc)
+
+		if s.wsHub != nil {
+			s.wsHub.BroadcastMessage("challenge_released", rc)
+		}
+		if s.notificationService != nil {
+			author := s.announcementAuthor(ch.ID, actorID)
+			if author == "" {
+				log.Printf("Scheduler: no user to announce challenge %s as, skipping announcement", ch.ID)
+				continue
+			}
+			content := fmt.Sprintf("A new %s challenge is now available: %s", ch.Category, ch.Title)
+			notification, err := s.notificationService.CreateNotification("New challenge released", content, "info", author)
+			if err != nil {
+				log.Printf("Scheduler: failed to announce challenge %s: %v", ch.ID, err)
+			} else if s.wsHub != nil {
+				s.wsHub.BroadcastMessage("notification:created", notification)
+			}
+		}
+	}
+
+	if len(released) > 0 {
+		log.Printf("Scheduler: released %d challenge(s)", len(released))
+	}
+	return released, nil
+}
+
+// announcementAuthor returns the user to credit with a release announcement: actorID when a
+// user triggered the run, otherwise whoever approved the challenge
+func (s *ChallengeScheduler) announcementAuthor(challengeID, actorID string) string {
+	if actorID != SchedulerActor {
+		return actorID
+	}
+	if s.reviewRepo == nil {
+		return ""
+	}
+	approver, err := s.reviewRepo.FindLatestActor(challengeID, models.ReviewActionApprove)
+	if err != nil {
+		log.Printf("Scheduler: failed to look up approver of challenge %s: %v", challengeID, err)
+		return ""
+	}
+	if approver == SchedulerActor {
+		return ""
+	}
+	return approver
+}
+
+// Start runs Tick every interval until ctx is cancelled
+func (s *ChallengeScheduler) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	log.Printf("Challenge scheduler started (interval %s)", interval)
+	for {
+		if _, err := s.Tick(time.Now(), SchedulerActor); err != nil {
+			log.Printf("Scheduler: tick failed: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
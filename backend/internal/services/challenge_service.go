@@ -2,6 +2,10 @@ package services
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
 
 	"github.com/Uttam-Mahata/RootAccess/backend/internal/database"
 	"github.com/Uttam-Mahata/RootAccess/backend/internal/models"
@@ -10,10 +14,20 @@ import (
 )
 
 type ChallengeService struct {
-	challengeRepo    *repositories.ChallengeRepository
-	submissionRepo   *repositories.SubmissionRepository
-	teamRepo         *repositories.TeamRepository
-	contestSolveRepo *repositories.ContestSolveRepository
+	challengeRepo      *repositories.ChallengeRepository
+	submissionRepo     *repositories.SubmissionRepository
+	teamRepo           *repositories.TeamRepository
+	contestSolveRepo   *repositories.ContestSolveRepository
+	incidentRepo       *repositories.CheatingIncidentRepository
+	revisionRepo       *repositories.ChallengeRevisionRepository
+	reviewRepo         *repositories.ChallengeReviewRepository
+	attemptResetRepo   *repositories.AttemptResetRepository
+	manualRepo         *repositories.ManualSubmissionRepository
+	contestEntityRepo  *repositories.ContestEntityRepository
+	roundChallengeRepo *repositories.RoundChallengeRepository
+	nearMissRepo       *repositories.NearMissRepository
+	ledgerService      *ScoreLedgerService
+	flagSecret         string
 }
 
 func NewChallengeService(
@@ -21,12 +35,32 @@ func NewChallengeService(
 	submissionRepo *repositories.SubmissionRepository,
 	teamRepo *repositories.TeamRepository,
 	contestSolveRepo *repositories.ContestSolveRepository,
+	incidentRepo *repositories.CheatingIncidentRepository,
+	revisionRepo *repositories.ChallengeRevisionRepository,
+	reviewRepo *repositories.ChallengeReviewRepository,
+	attemptResetRepo *repositories.AttemptResetRepository,
+	manualRepo *repositories.ManualSubmissionRepository,
+	contestEntityRepo *repositories.ContestEntityRepository,
+	roundChallengeRepo *repositories.RoundChallengeRepository,
+	nearMissRepo *repositories.NearMissRepository,
+	ledgerService *ScoreLedgerService,
+	flagSecret string,
 ) *ChallengeService {
 	return &ChallengeService{
-		challengeRepo:    challengeRepo,
-		submissionRepo:   submissionRepo,
-		teamRepo:         teamRepo,
-		contestSolveRepo: contestSolveRepo,
+		challengeRepo:      challengeRepo,
+		submissionRepo:     submissionRepo,
+		teamRepo:           teamRepo,
+		contestSolveRepo:   contestSolveRepo,
+		incidentRepo:       incidentRepo,
+		revisionRepo:       revisionRepo,
+		reviewRepo:         reviewRepo,
+		attemptResetRepo:   attemptResetRepo,
+		manualRepo:         manualRepo,
+		contestEntityRepo:  contestEntityRepo,
+		roundChallengeRepo: roundChallengeRepo,
+		nearMissRepo:       nearMissRepo,
+		ledgerService:      ledgerService,
+		flagSecret:         flagSecret,
 	}
 }
 
@@ -38,10 +72,16 @@ func (s *ChallengeService) invalidateScoreboardCache() {
 	}
 }
 
-func (s *ChallengeService) CreateChallenge(challenge *models.Challenge) error {
+// CreateChallenge creates a draft challenge owned by authorID and stores its first revision
+func (s *ChallengeService) CreateChallenge(challenge *models.Challenge, authorID string) error {
+	challenge.Status = models.ChallengeStatusDraft
+	if challenge.AuthorID == "" {
+		challenge.AuthorID = authorID
+	}
 	err := s.challengeRepo.CreateChallenge(challenge)
 	if err == nil {
 		s.invalidateScoreboardCache()
+		s.recordRevision(challenge, authorID, "Created")
 	}
 	return err
 }
@@ -58,12 +98,147 @@ func (s *ChallengeService) GetChallengeByID(id string) (*models.Challenge, error
 	return s.challengeRepo.GetChallengeByID(id)
 }
 
-func (s *ChallengeService) UpdateChallenge(id string, challenge *models.Challenge) error {
-	err := s.challengeRepo.UpdateChallenge(id, challenge)
-	if err == nil {
-		s.invalidateScoreboardCache()
+// UpdateChallenge saves the challenge and stores the new state as a revision authored by authorID
+func (s *ChallengeService) UpdateChallenge(id string, challenge *models.Challenge, authorID string) error {
+	return s.updateChallengeWithNote(id, challenge, authorID, "")
+}
+
+func (s *ChallengeService) updateChallengeWithNote(id string, challenge *models.Challenge, authorID, note string) error {
+	existing, err := s.challengeRepo.GetChallengeByID(id)
+	if err != nil {
+		return err
 	}
-	return err
+
+	// Challenges created before revision history existed get their pre-edit state as a baseline
+	if s.revisionRepo != nil {
+		if count, err := s.revisionRepo.CountByChallenge(id); err == nil && count == 0 {
+			s.recordRevision(existing, "", "Initial state")
+		}
+	}
+
+	err = s.challengeRepo.UpdateChallenge(id, challenge)
+	if err != nil {
+		return err
+	}
+	s.invalidateScoreboardCache()
+
+	updated, err := s.challengeRepo.GetChallengeByID(id)
+	if err != nil {
+		return nil
+	}
+	s.recordRevision(updated, authorID, note)
+	s.reopenIfReviewed(existing, updated, authorID)
+	return nil
+}
+
+// reopenIfReviewed sends a challenge that is in review or approved back to draft when its
+// content changes, so a sign-off always covers what actually goes live. Changing only the
+// release time keeps the approval.
+func (s *ChallengeService) reopenIfReviewed(before, after *models.Challenge, actorID string) {
+	for _, change := range models.DiffChallengeSnapshots(models.NewChallengeSnapshot(before), models.NewChallengeSnapshot(after)) {
+		if change.Field != "scheduled_at" {
+			s.reopenReview(before, actorID, "Edited after being submitted for review")
+			return
+		}
+	}
+}
+
+// ReopenAfterChange sends a challenge that is in review or approved back to draft after
+// content kept outside the challenge row, such as its attachments, has changed
+func (s *ChallengeService) ReopenAfterChange(challengeID, actorID, comment string) {
+	challenge, err := s.challengeRepo.GetChallengeByID(challengeID)
+	if err != nil {
+		return
+	}
+	s.reopenReview(challenge, actorID, comment)
+}
+
+// reopenReview moves a challenge that is in review or approved back to draft and records why
+func (s *ChallengeService) reopenReview(challenge *models.Challenge, actorID, comment string) {
+	if s.reviewRepo == nil {
+		return
+	}
+	if challenge.Status != models.ChallengeStatusInReview && challenge.Status != models.ChallengeStatusApproved {
+		return
+	}
+
+	moved, err := s.challengeRepo.TransitionStatus(challenge.ID, challenge.Status, models.ChallengeStatusDraft)
+	if err != nil || !moved {
+		return
+	}
+	review := &models.ChallengeReview{
+		ChallengeID: challenge.ID,
+		Action:      models.ReviewActionReopen,
+		FromStatus:  challenge.Status,
+		ToStatus:    models.ChallengeStatusDraft,
+		ActorID:     actorID,
+		Comment:     comment,
+	}
+	if err := s.reviewRepo.Create(review); err != nil {
+		log.Printf("Failed to record workflow reset for challenge %s: %v", challenge.ID, err)
+	}
+}
+
+// recordRevision appends a snapshot of the challenge to its revision history
+func (s *ChallengeService) recordRevision(challenge *models.Challenge, authorID, note string) {
+	if s.revisionRepo == nil {
+		return
+	}
+	rev := &models.ChallengeRevision{
+		ChallengeID: challenge.ID,
+		AuthorID:    authorID,
+		Note:        note,
+		Snapshot:    models.NewChallengeSnapshot(challenge),
+	}
+	if err := s.revisionRepo.Create(rev); err != nil {
+		log.Printf("Failed to record revision for challenge %s: %v", challenge.ID, err)
+	}
+}
+
+// GetRevisions returns the revision history of a challenge, newest first
+func (s *ChallengeService) GetRevisions(challengeID string) ([]models.ChallengeRevision, error) {
+	return s.revisionRepo.GetByChallenge(challengeID)
+}
+
+// GetRevision returns a single revision of a challenge
+func (s *ChallengeService) GetRevision(challengeID string, revision int) (*models.ChallengeRevision, error) {
+	return s.revisionRepo.FindByNumber(challengeID, revision)
+}
+
+// DiffRevisions lists the fields that changed between two revisions of a challenge
+func (s *ChallengeService) DiffRevisions(challengeID string, from, to int) ([]models.ChallengeFieldChange, error) {
+	fromRev, err := s.revisionRepo.FindByNumber(challengeID, from)
+	if err != nil {
+		return nil, err
+	}
+	toRev, err := s.revisionRepo.FindByNumber(challengeID, to)
+	if err != nil {
+		return nil, err
+	}
+	return models.DiffChallengeSnapshots(fromRev.Snapshot, toRev.Snapshot), nil
+}
+
+// RollbackChallenge restores the editable state of a revision. The rollback itself is
+// recorded as a new revision, so history is never rewritten.
+func (s *ChallengeService) RollbackChallenge(challengeID string, revision int, authorID string) (*models.Challenge, error) {
+	rev, err := s.revisionRepo.FindByNumber(challengeID, revision)
+	if err != nil {
+		return nil, err
+	}
+	challenge, err := s.challengeRepo.GetChallengeByID(challengeID)
+	if err != nil {
+		return nil, err
+	}
+
+	rev.Snapshot.ApplyTo(challenge)
+	if err := s.ValidatePrerequisites(challenge); err != nil {
+		return nil, fmt.Errorf("cannot roll back: %w", err)
+	}
+
+	if err := s.updateChallengeWithNote(challengeID, challenge, authorID, fmt.Sprintf("Rolled back to revision %d", revision)); err != nil {
+		return nil, err
+	}
+	return s.challengeRepo.GetChallengeByID(challengeID)
 }
 
 func (s *ChallengeService) DeleteChallenge(id string) error {
@@ -84,15 +259,578 @@ func (s *ChallengeService) PublishOfficialWriteup(id string) error {
 	return s.challengeRepo.PublishOfficialWriteup(id)
 }
 
+// PrerequisiteGraphReport describes problems found in the challenge unlock graph
+type PrerequisiteGraphReport struct {
+	Valid       bool     `json:"valid"`
+	Cycle       []string `json:"cycle,omitempty"`       // challenge IDs, first and last are the same
+	Unreachable []string `json:"unreachable,omitempty"` // challenges that can never be unlocked
+	// UnreachableInContest lists, per contest ID, challenges that cannot be unlocked with
+	// solves made in that contest
+	UnreachableInContest map[string][]string `json:"unreachable_in_contest,omitempty"`
+}
+
+func prerequisiteNodes(challenges []models.Challenge) []models.PrerequisiteNode {
+	nodes := make([]models.PrerequisiteNode, 0, len(challenges))
+	for _, ch := range challenges {
+		nodes = append(nodes, models.PrerequisiteNode{ID: ch.ID, Category: ch.Category, Prerequisites: ch.Prerequisites})
+	}
+	return nodes
+}
+
+// CheckPrerequisiteGraph reports cycles and never-unlockable challenges across all challenges
+func (s *ChallengeService) CheckPrerequisiteGraph() (*PrerequisiteGraphReport, error) {
+	challenges, err := s.challengeRepo.GetAllChallengesForList()
+	if err != nil {
+		return nil, err
+	}
+	contests, err := s.roundChallengeRepo.GetChallengeIDsByContest()
+	if err != nil {
+		return nil, err
+	}
+	nodes := prerequisiteNodes(challenges)
+	report := &PrerequisiteGraphReport{
+		Cycle:                models.FindPrerequisiteCycle(nodes),
+		Unreachable:          models.FindUnreachableChallenges(nodes),
+		UnreachableInContest: models.FindUnreachableInContests(nodes, contests),
+	}
+	report.Valid = report.Cycle == nil && len(report.Unreachable) == 0 && len(report.UnreachableInContest) == 0
+	return report, nil
+}
+
+// ValidatePrerequisites checks the unlock rules of a challenge that is about to be created or
+// updated. It rejects malformed rules, cycles, and changes that would leave challenges that
+// are currently unlockable impossible to unlock, overall or within a contest they are played in.
+func (s *ChallengeService) ValidatePrerequisites(challenge *models.Challenge) error {
+	challenges, err := s.challengeRepo.GetAllChallengesForList()
+	if err != nil {
+		return err
+	}
+
+	titles := make(map[string]string, len(challenges))
+	for _, ch := range challenges {
+		titles[ch.ID] = ch.Title
+	}
+	titles[challenge.ID] = challenge.Title
+
+	for i, p := range challenge.Prerequisites {
+		switch p.Type {
+		case models.PrerequisiteTypeChallenge:
+			if p.RequiredChallengeID == "" {
+				return fmt.Errorf("prerequisite %d: challenge_id is required", i)
+			}
+			if p.RequiredChallengeID == challenge.ID {
+				return fmt.Errorf("prerequisite %d: a challenge cannot require itself", i)
+			}
+			if _, ok := titles[p.RequiredChallengeID]; !ok {
+				return fmt.Errorf("prerequisite %d: required challenge not found", i)
+			}
+		case models.PrerequisiteTypeCategory:
+			if p.Category == "" {
+				return fmt.Errorf("prerequisite %d: category is required", i)
+			}
+			if p.Count < 1 {
+				return fmt.Errorf("prerequisite %d: count must be at least 1", i)
+			}
+		default:
+			return fmt.Errorf("prerequisite %d: type must be 'challenge' or 'category'", i)
+		}
+	}
+
+	before := prerequisiteNodes(challenges)
+	wasUnreachable := make(map[string]bool)
+	for _, id := range models.FindUnreachableChallenges(before) {
+		wasUnreachable[id] = true
+	}
+
+	// Build the graph with the proposed rules, starting the cycle search from this challenge
+	after := []models.PrerequisiteNode{{ID: challenge.ID, Category: challenge.Category, Prerequisites: challenge.Prerequisites}}
+	for _, n := range before {
+		if n.ID != challenge.ID {
+			after = append(after, n)
+		}
+	}
+
+	if cycle := models.FindPrerequisiteCycle(after); cycle != nil {
+		names := make([]string, len(cycle))
+		for i, id := range cycle {
+			names[i] = titles[id]
+		}
+		return fmt.Errorf("prerequisites form a cycle: %s", strings.Join(names, " -> "))
+	}
+
+	var broken []string
+	for _, id := range models.FindUnreachableChallenges(after) {
+		if !wasUnreachable[id] {
+			broken = append(broken, titles[id])
+		}
+	}
+	if len(broken) > 0 {
+		return errors.New("prerequisites can never be satisfied for: " + strings.Join(broken, ", "))
+	}
+
+	// Unlocks only count solves made in the same contest, so check each contest's challenges
+	contests, err := s.roundChallengeRepo.GetChallengeIDsByContest()
+	if err != nil {
+		return err
+	}
+	wasUnreachableIn := models.FindUnreachableInContests(before, contests)
+	for contestID, ids := range models.FindUnreachableInContests(after, contests) {
+		previously := make(map[string]bool, len(wasUnreachableIn[contestID]))
+		for _, id := range wasUnreachableIn[contestID] {
+			previously[id] = true
+		}
+		for _, id := range ids {
+			if !previously[id] {
+				broken = append(broken, titles[id])
+			}
+		}
+		if len(broken) > 0 {
+			name := contestID
+			if contest, err := s.contestEntityRepo.FindByID(contestID); err == nil {
+				name = contest.Name
+			}
+			return fmt.Errorf("prerequisites can never be satisfied in contest %s for: %s", name, strings.Join(broken, ", "))
+		}
+	}
+	return nil
+}
+
+// verifyFlag checks a submitted flag against every accepted flag entry of the challenge.
+// ownerID is the submitting team (or user when not in a team) and selects the expected
+// dynamic flag. Challenges created before flag entries existed fall back to the single FlagHash.
+func (s *ChallengeService) verifyFlag(challenge *models.Challenge, flag string, ownerID string) bool {
+	_, ok := s.matchFlag(challenge, flag, ownerID)
+	return ok
+}
+
+// matchFlag is the verification behind verifyFlag and also returns the flag entry that
+// accepted the submission (nil for the legacy FlagHash).
+func (s *ChallengeService) matchFlag(challenge *models.Challenge, flag string, ownerID string) (*models.ChallengeFlag, bool) {
+	if len(challenge.Flags) == 0 {
+		return nil, utils.VerifyFlag(flag, challenge.FlagHash)
+	}
+
+	for i, f := range challenge.Flags {
+		matched := false
+		switch f.Type {
+		case models.FlagTypeCaseInsensitive:
+			matched = utils.VerifyFlagCaseInsensitive(flag, f.Value)
+		case models.FlagTypeRegex:
+			matched = utils.MatchFlagPattern(flag, f.Value)
+		case models.FlagTypeDynamic:
+			matched = ownerID != "" && utils.VerifyDynamicFlag(flag, s.flagSecret, models.DynamicFlagPrefix, f.Value, challenge.ID, ownerID)
+		default:
+			matched = utils.VerifyFlag(flag, f.Value)
+		}
+		if matched {
+			return &challenge.Flags[i], true
+		}
+	}
+	return nil, false
+}
+
+// FlagTestResult is the outcome of an admin dry-run flag check
+type FlagTestResult struct {
+	ChallengeID     string `json:"challenge_id"`
+	Title           string `json:"title,omitempty"`
+	Tested          bool   `json:"tested"`
+	Correct         bool   `json:"correct"`
+	MatchedFlagID   string `json:"matched_flag_id,omitempty"`
+	MatchedFlagType string `json:"matched_flag_type,omitempty"`
+	IssuedToTeamID  string `json:"issued_to_team_id,omitempty"` // owner of a dynamic flag that was checked
+	IssuedToUserID  string `json:"issued_to_user_id,omitempty"` // set instead when the owner has no team
+	// InvalidFormat is set when the flag does not match FlagFormat, so submitting it would be
+	// refused even if it matches an accepted flag
+	InvalidFormat bool   `json:"invalid_format,omitempty"`
+	FlagFormat    string `json:"flag_format,omitempty"`
+	Message       string `json:"message,omitempty"`
+}
+
+// TestFlag runs the SubmitFlag verification for a flag without recording anything:
+// no submission, solve count, achievement, score or broadcast changes. ownerID selects
+// the team (or teamless user) for dynamic flags; when empty, a dynamic flag is checked
+// against every team and every teamless user who submitted to the challenge. The flag
+// format is the one in force in the challenge's contest.
+func (s *ChallengeService) TestFlag(challengeID, flag, ownerID string) (*FlagTestResult, error) {
+	challenge, err := s.challengeRepo.GetChallengeByID(challengeID)
+	if err != nil {
+		return nil, err
+	}
+	result := s.testChallengeFlag(challenge, flag, ownerID, challenge.ContestID)
+	return &result, nil
+}
+
+func (s *ChallengeService) testChallengeFlag(challenge *models.Challenge, flag, ownerID, contestID string) FlagTestResult {
+	result := s.matchTestFlag(challenge, flag, ownerID)
+
+	// SubmitFlag refuses flags in the wrong format before checking them
+	if challenge.GradingMode != models.GradingManual {
+		if format := s.FlagFormatFor(challenge, contestID); format != "" && !utils.MatchFlagPattern(flag, format) {
+			result.InvalidFormat = true
+			result.FlagFormat = format
+			if result.Correct {
+				result.Correct = false
+				result.Message = fmt.Sprintf("Flag matches but not the flag format %s, so submissions of it would be refused", format)
+			} else {
+				result.Message = fmt.Sprintf("Flag does not match the flag format %s", format)
+			}
+		}
+	}
+	return result
+}
+
+// matchTestFlag checks a flag against the accepted flags of a challenge for a dry run
+func (s *ChallengeService) matchTestFlag(challenge *models.Challenge, flag, ownerID string) FlagTestResult {
+	result := FlagTestResult{ChallengeID: challenge.ID, Title: challenge.Title, Tested: true}
+
+	if matched, ok := s.matchFlag(challenge, flag, ownerID); ok {
+		result.Correct = true
+		if matched != nil {
+			result.MatchedFlagID = matched.ID
+			result.MatchedFlagType = matched.Type
+			if matched.Type == models.FlagTypeDynamic {
+				result.IssuedToTeamID = ownerID
+			}
+		} else {
+			result.MatchedFlagType = models.FlagTypeStatic
+		}
+		return result
+	}
+
+	if teamID, userID := s.findDynamicFlagOwner(challenge, flag); teamID != "" || userID != "" {
+		result.IssuedToTeamID = teamID
+		result.IssuedToUserID = userID
+		result.MatchedFlagType = models.FlagTypeDynamic
+		owner := "team " + teamID
+		if teamID == "" {
+			owner = "user " + userID
+		}
+		if ownerID == "" {
+			result.Correct = true
+			result.Message = fmt.Sprintf("Valid dynamic flag issued to %s", owner)
+		} else {
+			result.Message = fmt.Sprintf("Dynamic flag belongs to %s, not %s", owner, ownerID)
+		}
+		return result
+	}
+
+	result.Message = "Flag does not match any accepted flag"
+	return result
+}
+
+// TestFlags dry-runs a batch of flags keyed by challenge ID, checking formats as in the given
+// contest. Every challenge in challengeIDs gets a result; challenges without a supplied flag
+// are reported as untested.
+func (s *ChallengeService) TestFlags(challengeIDs []string, flags map[string]string, ownerID, contestID string) ([]FlagTestResult, error) {
+	results := make([]FlagTestResult, 0, len(challengeIDs))
+	if len(challengeIDs) == 0 {
+		return results, nil
+	}
+
+	challenges, err := s.challengeRepo.GetChallengesByIDs(challengeIDs, false)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[string]*models.Challenge, len(challenges))
+	for i := range challenges {
+		byID[challenges[i].ID] = &challenges[i]
+	}
+
+	for _, id := range challengeIDs {
+		challenge, ok := byID[id]
+		if !ok {
+			results = append(results, FlagTestResult{ChallengeID: id, Message: "Challenge not found"})
+			continue
+		}
+		flag, ok := flags[id]
+		if !ok || flag == "" {
+			results = append(results, FlagTestResult{ChallengeID: id, Title: challenge.Title, Message: "No flag supplied"})
+			continue
+		}
+		results = append(results, s.testChallengeFlag(challenge, flag, ownerID, contestID))
+	}
+	return results, nil
+}
+
+// GetDynamicFlag returns the flag issued to the owner (team, or user without a team)
+// for a challenge in dynamic flag mode, or an empty string if the challenge has none.
+func (s *ChallengeService) GetDynamicFlag(challenge *models.Challenge, ownerID string) string {
+	for _, f := range challenge.Flags {
+		if f.Type == models.FlagTypeDynamic {
+			return utils.GenerateDynamicFlag(s.flagSecret, models.DynamicFlagPrefix, f.Value, challenge.ID, ownerID)
+		}
+	}
+	return ""
+}
+
+// findDynamicFlagOwner returns the owner a submitted dynamic flag was issued to: a team, or
+// a user without a team who has submitted to the challenge. Both are empty when the flag
+// belongs to neither.
+func (s *ChallengeService) findDynamicFlagOwner(challenge *models.Challenge, flag string) (teamID, userID string) {
+	var bases []string
+	for _, f := range challenge.Flags {
+		if f.Type == models.FlagTypeDynamic && strings.HasPrefix(flag, models.DynamicFlagPrefix+"{"+f.Value+"_") {
+			bases = append(bases, f.Value)
+		}
+	}
+	if len(bases) == 0 {
+		return "", ""
+	}
+	issuedTo := func(ownerID string) bool {
+		for _, base := range bases {
+			if utils.VerifyDynamicFlag(flag, s.flagSecret, models.DynamicFlagPrefix, base, challenge.ID, ownerID) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if teams, err := s.teamRepo.GetAllTeams(); err == nil {
+		for _, team := range teams {
+			if issuedTo(team.ID) {
+				return team.ID, ""
+			}
+		}
+	}
+
+	// Users without a team are only known to have seen their flag once they submit
+	subs, err := s.submissionRepo.GetSubmissionsByChallenge(challenge.ID)
+	if err != nil {
+		return "", ""
+	}
+	checked := make(map[string]bool)
+	for _, sub := range subs {
+		if sub.TeamID != "" || checked[sub.UserID] {
+			continue
+		}
+		checked[sub.UserID] = true
+		if issuedTo(sub.UserID) {
+			return "", sub.UserID
+		}
+	}
+	return "", ""
+}
+
+// recordFlagSharing stores a cheating incident for a submission that used the flag issued to
+// another team, or to another user without a team
+func (s *ChallengeService) recordFlagSharing(submission *models.Submission, sourceTeamID, sourceUserID string) {
+	if s.incidentRepo == nil {
+		return
+	}
+	details := fmt.Sprintf("Submitted the dynamic flag issued to team %s", sourceTeamID)
+	if sourceTeamID == "" {
+		details = fmt.Sprintf("Submitted the dynamic flag issued to user %s", sourceUserID)
+	}
+	incident := &models.CheatingIncident{
+		Type:         models.CheatingIncidentFlagSharing,
+		ChallengeID:  submission.ChallengeID,
+		ContestID:    submission.ContestID,
+		UserID:       submission.UserID,
+		TeamID:       submission.TeamID,
+		SourceTeamID: sourceTeamID,
+		SourceUserID: sourceUserID,
+		SubmissionID: submission.ID,
+		IPAddress:    submission.IPAddress,
+		Details:      details,
+	}
+	if err := s.incidentRepo.Create(incident); err != nil {
+		log.Printf("Failed to record cheating incident for submission %s: %v", submission.ID, err)
+	}
+}
+
+// findNearMiss checks a wrong flag against the accepted flags of a challenge and describes
+// how close it came. Hashed flags are compared by hashing rewrites of the submission (stray
+// whitespace, case, wrappers and every one-edit typo); regex flags are matched against the
+// rewrites, and dynamic flags are compared in plain text with the flag issued to ownerID.
+func (s *ChallengeService) findNearMiss(challenge *models.Challenge, flag, ownerID, contestID string) *models.NearMiss {
+	prefixes := models.CommonFlagPrefixes
+	if p := models.FlagFormatPrefix(s.FlagFormatFor(challenge, contestID)); p != "" {
+		prefixes = append([]string{p}, prefixes...)
+	}
+
+	// Hash targets map stored hashes to the flag they belong to
+	static := map[string]string{}
+	caseInsensitive := map[string]string{}
+	if len(challenge.Flags) == 0 && challenge.FlagHash != "" {
+		static[challenge.FlagHash] = ""
+	}
+	var patterns, dynamic []models.ChallengeFlag
+	for _, f := range challenge.Flags {
+		switch f.Type {
+		case models.FlagTypeCaseInsensitive:
+			caseInsensitive[f.Value] = f.ID
+		case models.FlagTypeRegex:
+			patterns = append(patterns, f)
+		case models.FlagTypeDynamic:
+			dynamic = append(dynamic, f)
+		default:
+			static[f.Value] = f.ID
+		}
+	}
+	matchHash := func(candidate string) (string, bool) {
+		if id, ok := static[utils.HashFlag(candidate)]; ok {
+			return id, true
+		}
+		if len(caseInsensitive) > 0 {
+			if id, ok := caseInsensitive[utils.HashFlagCaseInsensitive(candidate)]; ok {
+				return id, true
+			}
+		}
+		return "", false
+	}
+	nearMiss := func(flagID, candidate, kind, detail string) *models.NearMiss {
+		return &models.NearMiss{
+			FlagID:   flagID,
+			Kind:     kind,
+			Distance: models.EditDistance(flag, candidate),
+			Detail:   detail,
+		}
+	}
+
+	for _, f := range dynamic {
+		if ownerID == "" {
+			continue
+		}
+		expected := utils.GenerateDynamicFlag(s.flagSecret, models.DynamicFlagPrefix, f.Value, challenge.ID, ownerID)
+		if v, ok := models.ClassifyNearMiss(flag, expected, prefixes); ok {
+			return nearMiss(f.ID, v.Value, v.Kind, v.Detail)
+		}
+	}
+
+	for _, v := range models.FlagVariants(flag, prefixes) {
+		if id, ok := matchHash(v.Value); ok {
+			return nearMiss(id, v.Value, v.Kind, v.Detail)
+		}
+		for _, f := range patterns {
+			if utils.MatchFlagPattern(v.Value, f.Value) {
+				return nearMiss(f.ID, v.Value, v.Kind, v.Detail)
+			}
+		}
+	}
+
+	if len(static) == 0 && len(caseInsensitive) == 0 {
+		return nil
+	}
+	var matchedID, matchedValue string
+	detail, found := models.EachTypo(strings.TrimSpace(flag), func(candidate string) bool {
+		id, ok := matchHash(candidate)
+		if ok {
+			matchedID, matchedValue = id, candidate
+		}
+		return ok
+	})
+	if !found {
+		return nil
+	}
+	return nearMiss(matchedID, matchedValue, models.NearMissTypo, detail)
+}
+
+// recordNearMiss stores a near miss for a wrong submission, if it was one
+func (s *ChallengeService) recordNearMiss(submission *models.Submission, challenge *models.Challenge, flag, ownerID string) {
+	if s.nearMissRepo == nil {
+		return
+	}
+	nm := s.findNearMiss(challenge, flag, ownerID, submission.ContestID)
+	if nm == nil {
+		return
+	}
+	nm.ChallengeID = submission.ChallengeID
+	nm.ContestID = submission.ContestID
+	nm.SubmissionID = submission.ID
+	nm.UserID = submission.UserID
+	nm.TeamID = submission.TeamID
+	if err := s.nearMissRepo.Create(nm); err != nil {
+		log.Printf("Failed to record near miss for submission %s: %v", submission.ID, err)
+	}
+}
+
+// applyFirstBlood fills in the team's solver place and bonus for a contest solve, using the
+// same ranking as the scoreboards
+func (s *ChallengeService) applyFirstBlood(result *SubmitFlagResult, challenge *models.Challenge, contestID, teamID string) {
+	if len(challenge.FirstBloodBonus) == 0 {
+		return
+	}
+	solves, err := s.submissionRepo.GetCorrectSubmissionsByContestAndChallenge(contestID, challenge.ID)
+	if err != nil {
+		return
+	}
+	solve, ok := models.FirstBloodPlaces(solves)[challenge.ID][teamID]
+	if !ok {
+		return
+	}
+	if bonus := models.FirstBloodBonus(result.Points, challenge.FirstBloodBonus, solve.Place); bonus > 0 {
+		result.FirstBloodPlace = solve.Place
+		result.FirstBloodBonus = bonus
+	}
+}
+
 // SubmitFlagResult contains the result of a flag submission
 type SubmitFlagResult struct {
-	IsCorrect     bool   `json:"is_correct"`
-	AlreadySolved bool   `json:"already_solved"`
-	TeamID        string `json:"team_id,omitempty"`
-	TeamName      string `json:"team_name,omitempty"`
-	Points        int    `json:"points,omitempty"`
-	SolveCount    int    `json:"solve_count,omitempty"`
-	Message       string `json:"message,omitempty"`
+	IsCorrect       bool   `json:"is_correct"`
+	AlreadySolved   bool   `json:"already_solved"`
+	TeamID          string `json:"team_id,omitempty"`
+	TeamName        string `json:"team_name,omitempty"`
+	Points          int    `json:"points,omitempty"`
+	SolveCount      int    `json:"solve_count,omitempty"`
+	Message         string `json:"message,omitempty"`
+	FirstBloodPlace int    `json:"first_blood_place,omitempty"` // solver place rewarded by the first blood schedule
+	FirstBloodBonus int    `json:"first_blood_bonus,omitempty"`
+	// AttemptsRemaining is set after a wrong submission on a challenge with an attempt cap
+	AttemptsRemaining *int `json:"attempts_remaining,omitempty"`
+	// Pending is set when an answer to a manually graded challenge was queued for judging
+	Pending            bool   `json:"pending,omitempty"`
+	ManualSubmissionID string `json:"manual_submission_id,omitempty"`
+	// InvalidFormat is set when the flag did not match FlagFormat and was not checked
+	InvalidFormat bool   `json:"invalid_format,omitempty"`
+	FlagFormat    string `json:"flag_format,omitempty"`
+}
+
+// FlagFormatFor returns the regex submissions to a challenge must match in a contest:
+// the challenge's override, else the contest's format, else "" for no check
+func (s *ChallengeService) FlagFormatFor(challenge *models.Challenge, contestID string) string {
+	contestFormat := ""
+	if contestID != "" && s.contestEntityRepo != nil {
+		if contest, err := s.contestEntityRepo.FindByID(contestID); err == nil {
+			contestFormat = contest.FlagFormat
+		}
+	}
+	return models.EffectiveFlagFormat(contestFormat, challenge.FlagFormat)
+}
+
+// ErrAttemptLimitReached is returned when a team has used up a challenge's wrong attempts
+var ErrAttemptLimitReached = repositories.ErrAttemptLimitReached
+
+// GetAttemptsRemaining returns the wrong submissions the user's team (or the user, without a
+// team) has left on a challenge in a contest, or nil when the challenge has no attempt cap
+func (s *ChallengeService) GetAttemptsRemaining(challenge *models.Challenge, userID, contestID string) (*int, error) {
+	if challenge.MaxAttempts <= 0 {
+		return nil, nil
+	}
+	ownerID := userID
+	team, _ := s.teamRepo.FindTeamByMemberID(userID)
+	if team != nil {
+		ownerID = team.ID
+	}
+	used, err := s.submissionRepo.CountWrongAttempts(challenge.ID, ownerID, team != nil, contestID)
+	if err != nil {
+		return nil, err
+	}
+	remaining := models.AttemptsRemaining(challenge.MaxAttempts, used)
+	return &remaining, nil
+}
+
+// ResetAttempts clears the wrong-attempt counter of a team (or a user without a team) on a
+// challenge in a contest. Past submissions are kept; only those made afterwards count.
+func (s *ChallengeService) ResetAttempts(challengeID, ownerID, contestID, resetBy string) error {
+	if _, err := s.challengeRepo.GetChallengeByID(challengeID); err != nil {
+		return err
+	}
+	return s.attemptResetRepo.Create(&models.AttemptReset{
+		ChallengeID: challengeID,
+		OwnerID:     ownerID,
+		ContestID:   contestID,
+		ResetBy:     resetBy,
+	})
 }
 
 func (s *ChallengeService) SubmitFlag(userID string, challengeID string, flag string, clientIP string, contestID *string) (*SubmitFlagResult, error) {
@@ -117,15 +855,7 @@ func (s *ChallengeService) SubmitFlag(userID string, challengeID string, flag st
 	if existingUserSolve != nil {
 		result.IsCorrect = true
 		result.AlreadySolved = true
-		// Return contest-specific points if in a contest
-		if cID != "" && s.contestSolveRepo != nil {
-			contestSolves, _ := s.contestSolveRepo.GetContestSolveCount(cID, challengeID)
-			result.Points = challenge.PointsForSolveCount(contestSolves)
-			result.SolveCount = contestSolves
-		} else {
-			result.Points = challenge.CurrentPoints()
-			result.SolveCount = challenge.SolveCount
-		}
+		s.setCurrentPoints(result, challenge, cID)
 
 		team, _ := s.teamRepo.FindTeamByMemberID(userID)
 		if team != nil {
@@ -136,30 +866,81 @@ func (s *ChallengeService) SubmitFlag(userID string, challengeID string, flag st
 		return result, nil
 	}
 
-	// Verify flag using hash comparison
-	isCorrect := utils.VerifyFlag(flag, challenge.FlagHash)
-	result.IsCorrect = isCorrect
-
 	// Check if user is in a team
 	team, _ := s.teamRepo.FindTeamByMemberID(userID)
 
+	ownerID := userID
 	if team != nil {
-		result.TeamID = team.ID
-		result.TeamName = team.Name
+		ownerID = team.ID
+	}
 
-		// 2. Check if TEAM already solved it IN THIS CONTEST
-		teamAlreadySolved := false
-		if cID != "" {
-			existingTeamSolve, _ := s.submissionRepo.FindByChallengeAndTeamInContest(challengeID, team.ID, cID)
-			if existingTeamSolve != nil {
-				teamAlreadySolved = true
+	// Flags in the wrong format are logged and refused before they count as attempts
+	if challenge.GradingMode != models.GradingManual {
+		if format := s.FlagFormatFor(challenge, cID); format != "" && !utils.MatchFlagPattern(flag, format) {
+			submission := &models.Submission{
+				UserID:        userID,
+				ChallengeID:   challengeID,
+				ContestID:     cID,
+				Flag:          utils.HashFlag(flag),
+				InvalidFormat: true,
+				IPAddress:     clientIP,
 			}
-		} else {
-			existingTeamSolve, _ := s.submissionRepo.FindByChallengeAndTeam(challengeID, team.ID)
-			if existingTeamSolve != nil {
-				teamAlreadySolved = true
+			if team != nil {
+				submission.TeamID = team.ID
+				result.TeamID = team.ID
+				result.TeamName = team.Name
 			}
+			if _, err := s.submissionRepo.RecordSubmission(submission, repositories.RecordOptions{}); err != nil {
+				return nil, err
+			}
+			result.InvalidFormat = true
+			result.FlagFormat = format
+			result.Message = "Flag does not match the expected format"
+			return result, nil
 		}
+	}
+
+	// The attempt cap is checked before the flag so a capped team learns nothing from it, and
+	// again when the submission is recorded, which settles concurrent guesses
+	usedAttempts := 0
+	if challenge.MaxAttempts > 0 {
+		usedAttempts, err = s.submissionRepo.CountWrongAttempts(challengeID, ownerID, team != nil, cID)
+		if err != nil {
+			return nil, err
+		}
+		if usedAttempts >= challenge.MaxAttempts {
+			return nil, ErrAttemptLimitReached
+		}
+	}
+
+	if challenge.GradingMode == models.GradingManual {
+		return s.submitForJudging(result, challenge, userID, team, flag, cID)
+	}
+
+	isCorrect := s.verifyFlag(challenge, flag, ownerID)
+	result.IsCorrect = isCorrect
+	if !isCorrect && challenge.MaxAttempts > 0 {
+		remaining := models.AttemptsRemaining(challenge.MaxAttempts, usedAttempts+1)
+		result.AttemptsRemaining = &remaining
+	}
+
+	// A dynamic flag issued to a different team or user is never accepted and is flagged for review
+	var sharedFromTeam, sharedFromUser string
+	if !isCorrect {
+		sourceTeamID, sourceUserID := s.findDynamicFlagOwner(challenge, flag)
+		switch {
+		case sourceTeamID != "" && sourceTeamID != ownerID:
+			sharedFromTeam = sourceTeamID
+			result.Message = "Flag rejected: this flag was issued to another team"
+		case sourceUserID != "" && sourceUserID != ownerID:
+			sharedFromUser = sourceUserID
+			result.Message = "Flag rejected: this flag was issued to another player"
+		}
+	}
+
+	if team != nil {
+		result.TeamID = team.ID
+		result.TeamName = team.Name
 
 		// Hash the submitted flag for storage
 		flagHash := utils.HashFlag(flag)
@@ -174,49 +955,30 @@ func (s *ChallengeService) SubmitFlag(userID string, challengeID string, flag st
 			IPAddress:   clientIP,
 		}
 
-		err = s.submissionRepo.CreateSubmission(submission)
+		// 2. Only the first correct submission of the team in this contest claims the solve
+		firstSolve, err := s.submissionRepo.RecordSubmission(submission, repositories.RecordOptions{
+			MaxAttempts: challenge.MaxAttempts,
+			Settle:      s.solveSettler(challenge, submission),
+		})
 		if err != nil {
 			return nil, err
 		}
 
+		if sharedFromTeam != "" || sharedFromUser != "" {
+			s.recordFlagSharing(submission, sharedFromTeam, sharedFromUser)
+		} else if !isCorrect {
+			s.recordNearMiss(submission, challenge, flag, ownerID)
+		}
+
 		if isCorrect {
 			s.invalidateScoreboardCache()
 
-			if !teamAlreadySolved {
-				// Increment global solve count
-				s.challengeRepo.IncrementSolveCount(challengeID)
-
-				// Also increment contest-specific solve count
-				if cID != "" && s.contestSolveRepo != nil {
-					s.contestSolveRepo.IncrementContestSolveCount(cID, challengeID)
-				}
-
-				// Use contest-specific solve count for point calculation
-				if cID != "" && s.contestSolveRepo != nil {
-					contestSolves, _ := s.contestSolveRepo.GetContestSolveCount(cID, challengeID)
-					points := challenge.PointsForSolveCount(contestSolves)
-					result.Points = points
-					result.SolveCount = contestSolves
-				} else {
-					challenge, _ = s.challengeRepo.GetChallengeByID(challengeID)
-					result.Points = challenge.CurrentPoints()
-					result.SolveCount = challenge.SolveCount
-				}
-
-				// Award points to team (global score still tracks cumulative)
-				s.teamRepo.UpdateTeamScore(team.ID, result.Points)
-
+			if firstSolve {
+				s.awardSolve(result, challenge, team, submission)
 				result.Message = "Flag correct! Points awarded to team " + team.Name
 			} else {
 				result.AlreadySolved = true
-				if cID != "" && s.contestSolveRepo != nil {
-					contestSolves, _ := s.contestSolveRepo.GetContestSolveCount(cID, challengeID)
-					result.Points = challenge.PointsForSolveCount(contestSolves)
-					result.SolveCount = contestSolves
-				} else {
-					result.Points = challenge.CurrentPoints()
-					result.SolveCount = challenge.SolveCount
-				}
+				s.setCurrentPoints(result, challenge, cID)
 				result.Message = "Flag correct! (Team already solved)"
 			}
 		}
@@ -236,26 +998,145 @@ func (s *ChallengeService) SubmitFlag(userID string, challengeID string, flag st
 		IPAddress:   clientIP,
 	}
 
-	err = s.submissionRepo.CreateSubmission(submission)
+	firstSolve, err := s.submissionRepo.RecordSubmission(submission, repositories.RecordOptions{
+		MaxAttempts: challenge.MaxAttempts,
+		Settle:      s.solveSettler(challenge, submission),
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	if isCorrect {
-		s.challengeRepo.IncrementSolveCount(challengeID)
+	if sharedFromTeam != "" || sharedFromUser != "" {
+		s.recordFlagSharing(submission, sharedFromTeam, sharedFromUser)
+	} else if !isCorrect {
+		s.recordNearMiss(submission, challenge, flag, ownerID)
+	}
 
-		if cID != "" && s.contestSolveRepo != nil {
-			s.contestSolveRepo.IncrementContestSolveCount(cID, challengeID)
-			contestSolves, _ := s.contestSolveRepo.GetContestSolveCount(cID, challengeID)
-			result.Points = challenge.PointsForSolveCount(contestSolves)
-			result.SolveCount = contestSolves
+	if isCorrect {
+		if firstSolve {
+			s.awardSolve(result, challenge, nil, submission)
 		} else {
-			challenge, _ = s.challengeRepo.GetChallengeByID(challengeID)
-			result.Points = challenge.CurrentPoints()
-			result.SolveCount = challenge.SolveCount
+			// A retry that raced the first correct submission
+			result.AlreadySolved = true
+			s.setCurrentPoints(result, challenge, cID)
 		}
 		s.invalidateScoreboardCache()
 	}
 
 	return result, nil
 }
+
+// Errors returned when queueing an answer to a manually graded challenge
+var (
+	ErrAnswerPending = errors.New("an earlier answer to this challenge is still awaiting judgement")
+	ErrAnswerTooLong = fmt.Errorf("answer must be at most %d characters", models.MaxManualAnswerLength)
+)
+
+// submitForJudging queues an answer to a manually graded challenge instead of checking it.
+// An owner can have one answer awaiting judgement at a time.
+func (s *ChallengeService) submitForJudging(result *SubmitFlagResult, challenge *models.Challenge, userID string, team *models.Team, answer, cID string) (*SubmitFlagResult, error) {
+	ownerID := userID
+	if team != nil {
+		ownerID = team.ID
+		result.TeamID = team.ID
+		result.TeamName = team.Name
+
+		var existingTeamSolve *models.Submission
+		if cID != "" {
+			existingTeamSolve, _ = s.submissionRepo.FindByChallengeAndTeamInContest(challenge.ID, team.ID, cID)
+		} else {
+			existingTeamSolve, _ = s.submissionRepo.FindByChallengeAndTeam(challenge.ID, team.ID)
+		}
+		if existingTeamSolve != nil {
+			result.IsCorrect = true
+			result.AlreadySolved = true
+			result.Message = "Your team already solved this challenge"
+			return result, nil
+		}
+	}
+
+	answer = strings.TrimSpace(answer)
+	if len(answer) > models.MaxManualAnswerLength {
+		return nil, ErrAnswerTooLong
+	}
+
+	pending, err := s.manualRepo.HasPending(challenge.ID, ownerID, team != nil, cID)
+	if err != nil {
+		return nil, err
+	}
+	if pending {
+		return nil, ErrAnswerPending
+	}
+
+	entry := &models.ManualSubmission{
+		ChallengeID: challenge.ID,
+		UserID:      userID,
+		ContestID:   cID,
+		Answer:      answer,
+	}
+	if team != nil {
+		entry.TeamID = team.ID
+	}
+	if err := s.manualRepo.Create(entry); err != nil {
+		return nil, err
+	}
+
+	result.Pending = true
+	result.ManualSubmissionID = entry.ID
+	result.Message = "Answer submitted for judging"
+	return result, nil
+}
+
+// ContestScoringType returns the scoring strategy a contest imposes on its challenges,
+// or "" when the challenges keep their own
+func (s *ChallengeService) ContestScoringType(contestID string) string {
+	if contestID == "" || s.contestEntityRepo == nil {
+		return ""
+	}
+	contest, err := s.contestEntityRepo.FindByID(contestID)
+	if err != nil {
+		return ""
+	}
+	return contest.ScoringType
+}
+
+// solveSettler returns the ledger settlement a first solve of the challenge by sub records
+// along with its claim, or nil without a score ledger
+func (s *ChallengeService) solveSettler(challenge *models.Challenge, sub *models.Submission) repositories.SolveSettler {
+	if s.ledgerService == nil {
+		return nil
+	}
+	return s.ledgerService.SolveSettler(challenge, sub)
+}
+
+// awardSolve reports the credit of a submission that claimed a first solve, which already
+// counted it in the solve counts and the score ledger: points at the new solve count and
+// first blood (contest teams only). Used for flag solves and for accepted answers to
+// manually graded challenges alike.
+func (s *ChallengeService) awardSolve(result *SubmitFlagResult, challenge *models.Challenge, team *models.Team, sub *models.Submission) {
+	cID := sub.ContestID
+	if cID == "" || s.contestSolveRepo == nil {
+		if updated, err := s.challengeRepo.GetChallengeByID(challenge.ID); err == nil {
+			challenge = updated
+		}
+	}
+	s.setCurrentPoints(result, challenge, cID)
+
+	if team != nil && cID != "" {
+		s.applyFirstBlood(result, challenge, cID, team.ID)
+	}
+	invalidateContestScoreboardCache(cID)
+}
+
+// setCurrentPoints fills in what a solve of the challenge is worth right now and the solve
+// count that value rests on, which inside a contest is the contest's own
+func (s *ChallengeService) setCurrentPoints(result *SubmitFlagResult, challenge *models.Challenge, contestID string) {
+	if contestID != "" && s.contestSolveRepo != nil {
+		contestSolves, _ := s.contestSolveRepo.GetContestSolveCount(contestID, challenge.ID)
+		result.Points = challenge.PointsInContest(s.ContestScoringType(contestID), contestSolves)
+		result.SolveCount = contestSolves
+		return
+	}
+	result.Points = challenge.CurrentPoints()
+	result.SolveCount = challenge.SolveCount
+}
@@ -0,0 +1,51 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/Uttam-Mahata/RootAccess/backend/internal/models"
+	"github.com/Uttam-Mahata/RootAccess/backend/internal/repositories"
+)
+
+type CheatingIncidentService struct {
+	incidentRepo *repositories.CheatingIncidentRepository
+}
+
+func NewCheatingIncidentService(incidentRepo *repositories.CheatingIncidentRepository) *CheatingIncidentService {
+	return &CheatingIncidentService{
+		incidentRepo: incidentRepo,
+	}
+}
+
+// GetIncidents returns paginated incidents, optionally filtered by status
+func (s *CheatingIncidentService) GetIncidents(status string, limit, page int) ([]models.CheatingIncident, int64, error) {
+	incidents, err := s.incidentRepo.List(status, limit, page)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	count, err := s.incidentRepo.Count(status)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return incidents, count, nil
+}
+
+// ReviewIncident records an admin's decision (confirmed, dismissed or reopened) on an incident
+func (s *CheatingIncidentService) ReviewIncident(id, status, reviewerID, note string) (*models.CheatingIncident, error) {
+	switch status {
+	case models.CheatingIncidentStatusOpen, models.CheatingIncidentStatusConfirmed, models.CheatingIncidentStatusDismissed:
+	default:
+		return nil, errors.New("status must be 'open', 'confirmed' or 'dismissed'")
+	}
+
+	if err := s.incidentRepo.UpdateReview(id, status, reviewerID, note); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errors.New("incident not found")
+		}
+		return nil, err
+	}
+	return s.incidentRepo.FindByID(id)
+}
@@ -2,6 +2,7 @@ package services
 
 import (
 	"errors"
+	"strings"
 	"time"
 
 	"github.com/Uttam-Mahata/RootAccess/backend/internal/models"
@@ -15,6 +16,7 @@ type ContestAdminService struct {
 	roundChallengeRepo *repositories.RoundChallengeRepository
 	challengeRepo      *repositories.ChallengeRepository
 	registrationRepo   *repositories.TeamContestRegistrationRepository
+	submissionRepo     *repositories.SubmissionRepository
 }
 
 func NewContestAdminService(
@@ -24,6 +26,7 @@ func NewContestAdminService(
 	roundChallengeRepo *repositories.RoundChallengeRepository,
 	challengeRepo *repositories.ChallengeRepository,
 	registrationRepo *repositories.TeamContestRegistrationRepository,
+	submissionRepo *repositories.SubmissionRepository,
 ) *ContestAdminService {
 	return &ContestAdminService{
 		contestRepo:        contestRepo,
@@ -32,6 +35,7 @@ func NewContestAdminService(
 		roundChallengeRepo: roundChallengeRepo,
 		challengeRepo:      challengeRepo,
 		registrationRepo:   registrationRepo,
+		submissionRepo:     submissionRepo,
 	}
 }
 
@@ -46,7 +50,7 @@ func (s *ContestAdminService) GetContest(id string) (*models.Contest, error) {
 }
 
 // CreateContest creates a new contest
-func (s *ContestAdminService) CreateContest(name, description string, startTime, endTime time.Time, freezeTime *time.Time, scoreboardVisibility string) (*models.Contest, error) {
+func (s *ContestAdminService) CreateContest(name, description string, startTime, endTime time.Time, freezeTime *time.Time, scoreboardVisibility, flagFormat, scoringType, rankingMode string, penaltyMinutes int, tieBreaker string) (*models.Contest, error) {
 	ft := ""
 	if freezeTime != nil {
 		ft = freezeTime.Format(time.RFC3339)
@@ -54,6 +58,21 @@ func (s *ContestAdminService) CreateContest(name, description string, startTime,
 	if !endTime.After(startTime) {
 		return nil, errors.New("end time must be after start time")
 	}
+	if err := models.ValidateFlagFormat(flagFormat); err != nil {
+		return nil, err
+	}
+	if scoringType != "" && !models.IsValidScoringType(scoringType) {
+		return nil, errors.New("invalid scoring_type")
+	}
+	if !models.IsValidRankingMode(rankingMode) {
+		return nil, errors.New("invalid ranking_mode")
+	}
+	if err := models.ValidatePenaltyMinutes(penaltyMinutes); err != nil {
+		return nil, err
+	}
+	if !models.IsValidTieBreaker(tieBreaker) {
+		return nil, errors.New("invalid tie_breaker")
+	}
 
 	contest := &models.Contest{
 		Name:                 name,
@@ -62,6 +81,11 @@ func (s *ContestAdminService) CreateContest(name, description string, startTime,
 		EndTime:              endTime,
 		FreezeTime:           ft,
 		ScoreboardVisibility: scoreboardVisibility,
+		FlagFormat:           flagFormat,
+		ScoringType:          scoringType,
+		RankingMode:          rankingMode,
+		PenaltyMinutes:       penaltyMinutes,
+		TieBreaker:           tieBreaker,
 		IsActive:             false,
 	}
 	if err := s.contestEntityRepo.Create(contest); err != nil {
@@ -71,7 +95,7 @@ func (s *ContestAdminService) CreateContest(name, description string, startTime,
 }
 
 // UpdateContest updates a contest
-func (s *ContestAdminService) UpdateContest(id string, name, description string, startTime, endTime time.Time, isActive bool, freezeTime *time.Time, scoreboardVisibility string) (*models.Contest, error) {
+func (s *ContestAdminService) UpdateContest(id string, name, description string, startTime, endTime time.Time, isActive bool, freezeTime *time.Time, scoreboardVisibility, flagFormat, scoringType, rankingMode string, penaltyMinutes int, tieBreaker string) (*models.Contest, error) {
 	ft := ""
 	if freezeTime != nil {
 		ft = freezeTime.Format(time.RFC3339)
@@ -84,6 +108,21 @@ func (s *ContestAdminService) UpdateContest(id string, name, description string,
 	if !endTime.After(startTime) {
 		return nil, errors.New("end time must be after start time")
 	}
+	if err := models.ValidateFlagFormat(flagFormat); err != nil {
+		return nil, err
+	}
+	if scoringType != "" && !models.IsValidScoringType(scoringType) {
+		return nil, errors.New("invalid scoring_type")
+	}
+	if !models.IsValidRankingMode(rankingMode) {
+		return nil, errors.New("invalid ranking_mode")
+	}
+	if err := models.ValidatePenaltyMinutes(penaltyMinutes); err != nil {
+		return nil, err
+	}
+	if !models.IsValidTieBreaker(tieBreaker) {
+		return nil, errors.New("invalid tie_breaker")
+	}
 
 	contest.Name = name
 	contest.Description = description
@@ -92,10 +131,17 @@ func (s *ContestAdminService) UpdateContest(id string, name, description string,
 	contest.IsActive = isActive
 	contest.FreezeTime = ft
 	contest.ScoreboardVisibility = scoreboardVisibility
+	contest.FlagFormat = flagFormat
+	contest.ScoringType = scoringType
+	contest.RankingMode = rankingMode
+	contest.PenaltyMinutes = penaltyMinutes
+	contest.TieBreaker = tieBreaker
 
 	if err := s.contestEntityRepo.Update(contest); err != nil {
 		return nil, err
 	}
+	// Cached scoreboards may be ranked under the old settings
+	invalidateContestScoreboardCache(contest.ID)
 	return contest, nil
 }
 
@@ -213,12 +259,16 @@ func (s *ContestAdminService) DeleteRound(id string) error {
 	return s.contestRoundRepo.Delete(id)
 }
 
-// AttachChallengesToRound attaches challenges to a round and sets their ContestID
+// AttachChallengesToRound attaches challenges to a round and sets their ContestID. It refuses
+// attachments that would leave challenges of the contest impossible to unlock there.
 func (s *ContestAdminService) AttachChallengesToRound(roundID string, challengeIDs []string) error {
 	round, err := s.contestRoundRepo.FindByID(roundID)
 	if err != nil {
 		return err
 	}
+	if err := s.checkContestReachability(round.ContestID, challengeIDs); err != nil {
+		return err
+	}
 
 	for _, cid := range challengeIDs {
 		challengeOID := cid
@@ -231,6 +281,43 @@ func (s *ContestAdminService) AttachChallengesToRound(roundID string, challengeI
 	return nil
 }
 
+// checkContestReachability rejects adding challengeIDs to a contest when some challenge of
+// the contest could be unlocked before but not after, because unlocks only count solves made
+// in the same contest
+func (s *ContestAdminService) checkContestReachability(contestID string, challengeIDs []string) error {
+	challenges, err := s.challengeRepo.GetAllChallengesForList()
+	if err != nil {
+		return err
+	}
+	contests, err := s.roundChallengeRepo.GetChallengeIDsByContest()
+	if err != nil {
+		return err
+	}
+
+	current := contests[contestID]
+	nodes := prerequisiteNodes(challenges)
+	wasUnreachable := make(map[string]bool)
+	for _, id := range models.FindUnreachableInContests(nodes, map[string][]string{contestID: current})[contestID] {
+		wasUnreachable[id] = true
+	}
+
+	proposed := append(append([]string{}, current...), challengeIDs...)
+	titles := make(map[string]string, len(challenges))
+	for _, ch := range challenges {
+		titles[ch.ID] = ch.Title
+	}
+	var broken []string
+	for _, id := range models.FindUnreachableInContests(nodes, map[string][]string{contestID: proposed})[contestID] {
+		if !wasUnreachable[id] {
+			broken = append(broken, titles[id])
+		}
+	}
+	if len(broken) > 0 {
+		return errors.New("prerequisites could never be satisfied in this contest for: " + strings.Join(broken, ", "))
+	}
+	return nil
+}
+
 // DetachChallengesFromRound removes challenges from a round
 func (s *ContestAdminService) DetachChallengesFromRound(roundID string, challengeIDs []string) error {
 	round, err := s.contestRoundRepo.FindByID(roundID)
@@ -257,9 +344,27 @@ func (s *ContestAdminService) GetChallengesForRound(roundID string) ([]string, e
 	return s.roundChallengeRepo.GetChallengesByRound(oid)
 }
 
-// GetVisibleChallengeIDs returns challenge IDs that are currently visible to players.
+// GetContestChallengeIDs returns every challenge attached to any round of a contest,
+// regardless of round timing or publication state
+func (s *ContestAdminService) GetContestChallengeIDs(contestID string) ([]string, error) {
+	if _, err := s.contestEntityRepo.FindByID(contestID); err != nil {
+		return nil, errors.New("contest not found")
+	}
+	rounds, err := s.contestRoundRepo.ListByContestID(contestID)
+	if err != nil || len(rounds) == 0 {
+		return nil, err
+	}
+	roundIDs := make([]string, len(rounds))
+	for i := range rounds {
+		roundIDs[i] = rounds[i].ID
+	}
+	return s.roundChallengeRepo.GetChallengeIDsForRounds(roundIDs)
+}
+
+// GetVisibleChallengeIDs returns published challenge IDs that are currently visible to players.
 // Returns nil if no active contest or outside contest/round times.
 // If teamID is provided, only returns challenges from contests the team is registered for
+// whose prerequisites the team has met in this contest
 func (s *ContestAdminService) GetVisibleChallengeIDs(now time.Time, teamID *string) ([]string, error) {
 	config, err := s.contestRepo.GetActiveContest()
 	if err != nil || config == nil || config.ContestID == "" {
@@ -298,7 +403,43 @@ func (s *ContestAdminService) GetVisibleChallengeIDs(now time.Time, teamID *stri
 		roundIDs[i] = rounds[i].ID
 	}
 
-	return s.roundChallengeRepo.GetChallengeIDsForRounds(roundIDs)
+	ids, err := s.roundChallengeRepo.GetChallengeIDsForRounds(roundIDs)
+	if err != nil || len(ids) == 0 {
+		return ids, err
+	}
+
+	// Scheduled challenges stay hidden until the scheduler publishes them
+	ids, err = s.challengeRepo.FilterPublishedIDs(ids)
+	if err != nil || len(ids) == 0 {
+		return ids, err
+	}
+
+	return s.filterLockedChallenges(ids, config.ContestID, *teamID)
+}
+
+// filterLockedChallenges drops challenges whose prerequisites the team has not met yet.
+// Challenges the team already solved stay visible even if their rules changed since.
+func (s *ContestAdminService) filterLockedChallenges(ids []string, contestID, teamID string) ([]string, error) {
+	prereqs, err := s.challengeRepo.GetPrerequisitesForChallenges(ids)
+	if err != nil {
+		return nil, err
+	}
+	if len(prereqs) == 0 || s.submissionRepo == nil {
+		return ids, nil
+	}
+
+	solved, err := s.submissionRepo.GetTeamSolvedChallengeCategories(teamID, contestID)
+	if err != nil {
+		return nil, err
+	}
+
+	unlocked := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if _, ok := solved[id]; ok || models.PrerequisitesMet(prereqs[id], solved) {
+			unlocked = append(unlocked, id)
+		}
+	}
+	return unlocked, nil
 }
 
 // GetVisibleChallenges returns challenges that are currently visible to players (published, in active contest/round)
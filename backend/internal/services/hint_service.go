@@ -11,17 +11,20 @@ type HintService struct {
 	hintRepo      *repositories.HintRepository
 	challengeRepo *repositories.ChallengeRepository
 	teamRepo      *repositories.TeamRepository
+	ledgerService *ScoreLedgerService
 }
 
 func NewHintService(
 	hintRepo *repositories.HintRepository,
 	challengeRepo *repositories.ChallengeRepository,
 	teamRepo *repositories.TeamRepository,
+	ledgerService *ScoreLedgerService,
 ) *HintService {
 	return &HintService{
 		hintRepo:      hintRepo,
 		challengeRepo: challengeRepo,
 		teamRepo:      teamRepo,
+		ledgerService: ledgerService,
 	}
 }
 
@@ -75,8 +78,9 @@ func (s *HintService) GetHintsForChallenge(challengeID string, userID string) ([
 	return response, nil
 }
 
-// RevealHint reveals a hint for a user, deducting points from their team
-func (s *HintService) RevealHint(challengeID string, hintID string, userID string) (*HintResponse, error) {
+// RevealHint reveals a hint for a user, deducting points from their team. The cost is
+// charged against contestID, the contest active at the time, if any.
+func (s *HintService) RevealHint(challengeID string, hintID string, userID string, contestID string) (*HintResponse, error) {
 	challenge, err := s.challengeRepo.GetChallengeByID(challengeID)
 	if err != nil {
 		return nil, err
@@ -134,15 +138,19 @@ func (s *HintService) RevealHint(challengeID string, hintID string, userID strin
 		HintID:      hintOID,
 		ChallengeID: cid,
 		UserID:      userID,
+		ContestID:   contestID,
 		Cost:        targetHint.Cost,
 	}
 	if team != nil {
 		reveal.TeamID = team.ID
-		// Deduct points from team
-		s.teamRepo.UpdateTeamScore(team.ID, -targetHint.Cost)
 	}
 
-	if err := s.hintRepo.CreateReveal(reveal); err != nil {
+	if s.ledgerService != nil {
+		err = s.ledgerService.RecordHint(reveal)
+	} else {
+		err = s.hintRepo.CreateReveal(reveal, nil)
+	}
+	if err != nil {
 		return nil, err
 	}
 
@@ -0,0 +1,206 @@
+package services
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/Uttam-Mahata/RootAccess/backend/internal/models"
+	"github.com/Uttam-Mahata/RootAccess/backend/internal/repositories"
+	"github.com/Uttam-Mahata/RootAccess/backend/internal/utils"
+)
+
+// ErrAlreadyJudged is returned when a verdict is given on an answer that is no longer pending
+var ErrAlreadyJudged = repositories.ErrAlreadyJudged
+
+// JudgingService grades answers to manually graded challenges. Accepted answers go
+// through the same scoring path as flag solves.
+type JudgingService struct {
+	manualRepo       *repositories.ManualSubmissionRepository
+	challengeRepo    *repositories.ChallengeRepository
+	submissionRepo   *repositories.SubmissionRepository
+	teamRepo         *repositories.TeamRepository
+	contestSolveRepo *repositories.ContestSolveRepository
+	challengeService *ChallengeService
+	ledgerService    *ScoreLedgerService
+}
+
+func NewJudgingService(
+	manualRepo *repositories.ManualSubmissionRepository,
+	challengeRepo *repositories.ChallengeRepository,
+	submissionRepo *repositories.SubmissionRepository,
+	teamRepo *repositories.TeamRepository,
+	contestSolveRepo *repositories.ContestSolveRepository,
+	challengeService *ChallengeService,
+	ledgerService *ScoreLedgerService,
+) *JudgingService {
+	return &JudgingService{
+		manualRepo:       manualRepo,
+		challengeRepo:    challengeRepo,
+		submissionRepo:   submissionRepo,
+		teamRepo:         teamRepo,
+		contestSolveRepo: contestSolveRepo,
+		challengeService: challengeService,
+		ledgerService:    ledgerService,
+	}
+}
+
+// GetQueue lists answers in a state (pending by default), optionally for one contest or challenge
+func (s *JudgingService) GetQueue(status, contestID, challengeID string) ([]models.ManualSubmission, error) {
+	if status == "" {
+		status = models.JudgementPending
+	}
+	if !models.IsValidJudgementStatus(status) {
+		return nil, errors.New("invalid status")
+	}
+	return s.manualRepo.List(status, contestID, challengeID)
+}
+
+func (s *JudgingService) GetSubmission(id string) (*models.ManualSubmission, error) {
+	return s.manualRepo.GetByID(id)
+}
+
+// GetOwnAnswers returns the answers the user's team (or the user, without a team) gave to a challenge
+func (s *JudgingService) GetOwnAnswers(challengeID, userID string) ([]models.ManualSubmission, error) {
+	team, _ := s.teamRepo.FindTeamByMemberID(userID)
+	var entries []models.ManualSubmission
+	var err error
+	if team != nil {
+		entries, err = s.manualRepo.GetByOwner(challengeID, team.ID, true)
+	} else {
+		entries, err = s.manualRepo.GetByOwner(challengeID, userID, false)
+	}
+	if err != nil {
+		return nil, err
+	}
+	for i := range entries {
+		entries[i].JudgedBy = ""
+	}
+	return entries, nil
+}
+
+// Judge gives a verdict on a pending answer. Accepting records a correct submission at the
+// time the answer was given and awards the solve; rejecting records a wrong submission so
+// attempt limits apply; partial credit is recorded in the score ledger as is. The verdict is
+// stored in the same transaction as the submission or the ledger entries it leads to.
+func (s *JudgingService) Judge(id, verdict string, points int, feedback, judgedBy string) (*models.ManualSubmission, *SubmitFlagResult, error) {
+	entry, err := s.manualRepo.GetByID(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if entry.Status != models.JudgementPending {
+		return nil, nil, ErrAlreadyJudged
+	}
+
+	challenge, err := s.challengeRepo.GetChallengeByID(entry.ChallengeID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	status, err := models.JudgementStatusForVerdict(verdict, points, s.currentPoints(challenge, entry.ContestID))
+	if err != nil {
+		return nil, nil, err
+	}
+	if status != models.JudgementPartial {
+		points = 0
+	}
+
+	var team *models.Team
+	if entry.TeamID != "" {
+		team, _ = s.teamRepo.FindTeamByID(entry.TeamID)
+	}
+	alreadySolved := s.hasSolved(entry)
+	if status == models.JudgementPartial && alreadySolved {
+		return nil, nil, errors.New("partial credit cannot be given once the challenge is solved")
+	}
+
+	judgement := repositories.ManualVerdict{
+		ID:       id,
+		Status:   status,
+		Points:   points,
+		Feedback: strings.TrimSpace(feedback),
+		JudgedBy: judgedBy,
+	}
+
+	result := &SubmitFlagResult{IsCorrect: status == models.JudgementAccepted}
+	if team != nil {
+		result.TeamID = team.ID
+		result.TeamName = team.Name
+	}
+
+	var submission *models.Submission
+	firstSolve := false
+	if status == models.JudgementPartial {
+		var settle repositories.SolveSettler
+		if s.ledgerService != nil {
+			partial := *entry
+			partial.Status = status
+			partial.PointsAwarded = points
+			partial.JudgedBy = judgedBy
+			settle = s.ledgerService.PartialSettler(challenge, &partial)
+		}
+		if err := s.manualRepo.Judge(judgement, settle); err != nil {
+			return nil, nil, err
+		}
+	} else {
+		submission = &models.Submission{
+			UserID:      entry.UserID,
+			TeamID:      entry.TeamID,
+			ChallengeID: entry.ChallengeID,
+			ContestID:   entry.ContestID,
+			Flag:        utils.HashFlag(entry.Answer),
+			IsCorrect:   status == models.JudgementAccepted,
+			Timestamp:   entry.CreatedAt,
+		}
+		if firstSolve, err = s.submissionRepo.RecordSubmission(submission, repositories.RecordOptions{
+			Settle:  s.challengeService.solveSettler(challenge, submission),
+			Verdict: &judgement,
+		}); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if status == models.JudgementAccepted {
+		if firstSolve {
+			s.challengeService.awardSolve(result, challenge, team, submission)
+		} else {
+			result.AlreadySolved = true
+		}
+	}
+	if status != models.JudgementRejected {
+		s.challengeService.invalidateScoreboardCache()
+		invalidateContestScoreboardCache(entry.ContestID)
+	}
+
+	judgedEntry, err := s.manualRepo.GetByID(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	return judgedEntry, result, nil
+}
+
+// currentPoints is what a solve of the challenge is worth right now in the contest
+func (s *JudgingService) currentPoints(challenge *models.Challenge, contestID string) int {
+	if contestID != "" && s.contestSolveRepo != nil {
+		if count, err := s.contestSolveRepo.GetContestSolveCount(contestID, challenge.ID); err == nil {
+			return challenge.PointsInContest(s.challengeService.ContestScoringType(contestID), count)
+		}
+	}
+	return challenge.CurrentPoints()
+}
+
+// hasSolved reports whether the answer's team (or user, without a team) already has a
+// correct submission for the challenge in the answer's contest
+func (s *JudgingService) hasSolved(entry *models.ManualSubmission) bool {
+	var existing *models.Submission
+	switch {
+	case entry.TeamID != "" && entry.ContestID != "":
+		existing, _ = s.submissionRepo.FindByChallengeAndTeamInContest(entry.ChallengeID, entry.TeamID, entry.ContestID)
+	case entry.TeamID != "":
+		existing, _ = s.submissionRepo.FindByChallengeAndTeam(entry.ChallengeID, entry.TeamID)
+	case entry.ContestID != "":
+		existing, _ = s.submissionRepo.FindByChallengeAndUserInContest(entry.ChallengeID, entry.UserID, entry.ContestID)
+	default:
+		existing, _ = s.submissionRepo.FindByChallengeAndUser(entry.ChallengeID, entry.UserID)
+	}
+	return existing != nil
+}
@@ -0,0 +1,162 @@
+package services
+
+import (
+	"sort"
+
+	"github.com/Uttam-Mahata/RootAccess/backend/internal/models"
+	"github.com/Uttam-Mahata/RootAccess/backend/internal/repositories"
+)
+
+// ReconciliationService repairs derived counters that drifted from the raw submissions,
+// e.g. after a crash between the writes of a solve or after deleting submissions:
+// challenge and per-contest solve counts, the score ledger and the team scores cached
+// from it.
+type ReconciliationService struct {
+	challengeRepo    *repositories.ChallengeRepository
+	contestSolveRepo *repositories.ContestSolveRepository
+	teamRepo         *repositories.TeamRepository
+	ledgerRepo       *repositories.ScoreLedgerRepository
+	ledgerService    *ScoreLedgerService
+}
+
+func NewReconciliationService(
+	challengeRepo *repositories.ChallengeRepository,
+	contestSolveRepo *repositories.ContestSolveRepository,
+	teamRepo *repositories.TeamRepository,
+	ledgerRepo *repositories.ScoreLedgerRepository,
+	ledgerService *ScoreLedgerService,
+) *ReconciliationService {
+	return &ReconciliationService{
+		challengeRepo:    challengeRepo,
+		contestSolveRepo: contestSolveRepo,
+		teamRepo:         teamRepo,
+		ledgerRepo:       ledgerRepo,
+		ledgerService:    ledgerService,
+	}
+}
+
+// Preview reports what a reconciliation would change, without changing anything
+func (s *ReconciliationService) Preview() (*models.ReconciliationReport, error) {
+	return s.reconcile(false, "")
+}
+
+// Apply repairs every drifted counter and drops the cached scoreboards
+func (s *ReconciliationService) Apply(adminID string) (*models.ReconciliationReport, error) {
+	return s.reconcile(true, adminID)
+}
+
+func (s *ReconciliationService) reconcile(apply bool, adminID string) (*models.ReconciliationReport, error) {
+	report := &models.ReconciliationReport{
+		Drifts:        []models.CounterDrift{},
+		LedgerEntries: []models.ScoreLedgerEntry{},
+		Applied:       apply,
+	}
+
+	// Stored team scores, read before settling the ledger refreshes any of them
+	teams, err := s.teamRepo.GetAllTeams()
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[string]string, len(teams))
+	storedScores := make(map[string]int, len(teams))
+	for _, t := range teams {
+		names[t.ID] = t.Name
+		storedScores[t.ID] = t.Score
+	}
+
+	// Solve counts, which dynamic challenge values depend on
+	challenges, err := s.challengeRepo.GetAllChallenges()
+	if err != nil {
+		return nil, err
+	}
+	titles := make(map[string]string, len(challenges))
+	storedSolves := make(map[string]int, len(challenges))
+	for _, c := range challenges {
+		titles[c.ID] = c.Title
+		storedSolves[c.ID] = c.SolveCount
+	}
+	solves, err := s.challengeRepo.CountValidSolves()
+	if err != nil {
+		return nil, err
+	}
+	for id := range solves {
+		if _, exists := titles[id]; !exists {
+			delete(solves, id) // submissions left behind by a deleted challenge
+		}
+	}
+	storedContestSolves, err := s.contestSolveRepo.GetAllSolveCounts()
+	if err != nil {
+		return nil, err
+	}
+	contestSolves, err := s.contestSolveRepo.CountValidSolves()
+	if err != nil {
+		return nil, err
+	}
+
+	recount := make(map[string]bool)
+	for _, d := range models.DiffCounters(models.CounterChallengeSolves, "", storedSolves, solves, titles) {
+		report.Drifts = append(report.Drifts, d)
+		recount[d.TargetID] = true
+	}
+	var contestIDs []string
+	for id := range storedContestSolves {
+		contestIDs = append(contestIDs, id)
+	}
+	for id := range contestSolves {
+		if _, seen := storedContestSolves[id]; !seen {
+			contestIDs = append(contestIDs, id)
+		}
+	}
+	sort.Strings(contestIDs)
+	for _, contestID := range contestIDs {
+		for _, d := range models.DiffCounters(models.CounterContestSolves, contestID, storedContestSolves[contestID], contestSolves[contestID], titles) {
+			report.Drifts = append(report.Drifts, d)
+			recount[d.TargetID] = true
+		}
+	}
+	if apply {
+		for id := range recount {
+			if err := s.challengeRepo.RecountSolves(id); err != nil {
+				return nil, err
+			}
+			if err := s.contestSolveRepo.RecountSolves(id); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// The score ledger, settled at the corrected solve counts
+	entries, err := s.ledgerService.Reconcile(apply, adminID, solves, contestSolves)
+	if err != nil {
+		return nil, err
+	}
+	report.LedgerEntries = append(report.LedgerEntries, entries...)
+
+	// Team scores, which are the sum of their ledger entries
+	scores, err := s.ledgerRepo.GetTeamTotals()
+	if err != nil {
+		return nil, err
+	}
+	if !apply {
+		// What the ledger corrections would add; applied ones are in the totals already
+		for _, e := range entries {
+			if e.TeamID != "" {
+				scores[e.TeamID] += e.Points
+			}
+		}
+	}
+	for id := range scores {
+		if _, exists := names[id]; !exists {
+			delete(scores, id) // entries of a deleted team
+		}
+	}
+	teamDrifts := models.DiffCounters(models.CounterTeamScore, "", storedScores, scores, names)
+	report.Drifts = append(report.Drifts, teamDrifts...)
+	if apply {
+		if err := s.ledgerRepo.RefreshTeamScores(); err != nil {
+			return nil, err
+		}
+		invalidateAllScoreboardCaches()
+	}
+	return report, nil
+}
@@ -0,0 +1,558 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/Uttam-Mahata/RootAccess/backend/internal/models"
+	"github.com/Uttam-Mahata/RootAccess/backend/internal/repositories"
+)
+
+// ScoreLedgerService records every point-affecting event in the score ledger, which
+// scoreboards, profiles and analytics all read from. Solves, invalidations and partial
+// credit settle the challenge: each solver's credit is brought in line with the
+// challenge's current value, so dynamic decay shows up as revaluation entries.
+type ScoreLedgerService struct {
+	ledgerRepo        *repositories.ScoreLedgerRepository
+	challengeRepo     *repositories.ChallengeRepository
+	submissionRepo    *repositories.SubmissionRepository
+	contestSolveRepo  *repositories.ContestSolveRepository
+	contestEntityRepo *repositories.ContestEntityRepository
+	registrationRepo  *repositories.TeamContestRegistrationRepository
+	manualRepo        *repositories.ManualSubmissionRepository
+	hintRepo          *repositories.HintRepository
+	adjustmentRepo    *repositories.ScoreAdjustmentRepository
+}
+
+func NewScoreLedgerService(
+	ledgerRepo *repositories.ScoreLedgerRepository,
+	challengeRepo *repositories.ChallengeRepository,
+	submissionRepo *repositories.SubmissionRepository,
+	contestSolveRepo *repositories.ContestSolveRepository,
+	contestEntityRepo *repositories.ContestEntityRepository,
+	registrationRepo *repositories.TeamContestRegistrationRepository,
+	manualRepo *repositories.ManualSubmissionRepository,
+	hintRepo *repositories.HintRepository,
+	adjustmentRepo *repositories.ScoreAdjustmentRepository,
+) *ScoreLedgerService {
+	return &ScoreLedgerService{
+		ledgerRepo:        ledgerRepo,
+		challengeRepo:     challengeRepo,
+		submissionRepo:    submissionRepo,
+		contestSolveRepo:  contestSolveRepo,
+		contestEntityRepo: contestEntityRepo,
+		registrationRepo:  registrationRepo,
+		manualRepo:        manualRepo,
+		hintRepo:          hintRepo,
+		adjustmentRepo:    adjustmentRepo,
+	}
+}
+
+// SolveSettler returns the settlement RecordSubmission runs in its transaction when sub
+// claims a first solve: the solve is credited and the challenge's other solvers revalued,
+// in its contest and, since the global solve count changed too, outside contests
+func (s *ScoreLedgerService) SolveSettler(challenge *models.Challenge, sub *models.Submission) repositories.SolveSettler {
+	return s.submissionSettler(challenge, sub, models.LedgerCause{Kind: models.LedgerSolve, Reason: "Solved"})
+}
+
+// InvalidationSettler returns the settlement that takes back the credit of an invalidated
+// submission, for SubmissionRepository.Invalidate to run with the invalidation
+func (s *ScoreLedgerService) InvalidationSettler(challenge *models.Challenge, sub *models.Submission, adminID string) repositories.SolveSettler {
+	return s.submissionSettler(challenge, sub, models.LedgerCause{
+		Kind:    models.LedgerInvalidation,
+		ActorID: adminID,
+		Reason:  "Submission invalidated: " + sub.InvalidationReason,
+	})
+}
+
+// RestoreSettler returns the settlement that credits a restored submission again, for
+// SubmissionRepository.Restore to run with the restore
+func (s *ScoreLedgerService) RestoreSettler(challenge *models.Challenge, sub *models.Submission, adminID string) repositories.SolveSettler {
+	return s.submissionSettler(challenge, sub, models.LedgerCause{
+		Kind:    models.LedgerSolve,
+		ActorID: adminID,
+		Reason:  "Submission restored",
+	})
+}
+
+// submissionSettler settles a challenge after a change to one of its submissions, crediting
+// the change to cause in the submission's contest and as a revaluation outside contests
+func (s *ScoreLedgerService) submissionSettler(challenge *models.Challenge, sub *models.Submission, cause models.LedgerCause) repositories.SolveSettler {
+	cause.TeamID = sub.TeamID
+	cause.UserID = sub.UserID
+	cause.ReferenceID = sub.ID
+	return func(contestID string, solves []models.Submission, solveCount int, current []models.ScoreLedgerEntry) ([]models.ScoreLedgerEntry, error) {
+		answers, err := s.partialAnswers(contestID, challenge)
+		if err != nil {
+			return nil, err
+		}
+		expected, err := s.creditFor(contestID, challenge, s.valueAt(contestID, challenge, solveCount), solves, answers)
+		if err != nil {
+			return nil, err
+		}
+		scoped := cause
+		scoped.At = time.Now()
+		if contestID != sub.ContestID {
+			scoped = contestRevaluation(scoped)
+		}
+		return models.SettleChallengeCredit(contestID, challenge.ID, current, expected, scoped), nil
+	}
+}
+
+// contestRevaluation is what a solve event in a contest means outside contests: a change
+// in the global solve count, revaluing the challenge for its solvers there
+func contestRevaluation(cause models.LedgerCause) models.LedgerCause {
+	return models.LedgerCause{
+		Kind:        models.LedgerRevaluation,
+		ReferenceID: cause.ReferenceID,
+		ActorID:     cause.ActorID,
+		Reason:      cause.Reason + " in a contest",
+		At:          cause.At,
+	}
+}
+
+// PartialSettler returns the settlement that credits partial credit given to an answer, for
+// ManualSubmissionRepository.Judge to run with the verdict. entry carries the new verdict,
+// which the stored answers do not show until it commits.
+func (s *ScoreLedgerService) PartialSettler(challenge *models.Challenge, entry *models.ManualSubmission) repositories.SolveSettler {
+	return func(contestID string, solves []models.Submission, solveCount int, current []models.ScoreLedgerEntry) ([]models.ScoreLedgerEntry, error) {
+		answers, err := s.partialAnswers(contestID, challenge)
+		if err != nil {
+			return nil, err
+		}
+		answers = append(answers, *entry)
+		expected, err := s.creditFor(contestID, challenge, s.valueAt(contestID, challenge, solveCount), solves, answers)
+		if err != nil {
+			return nil, err
+		}
+		cause := models.LedgerCause{
+			Kind:        models.LedgerPartial,
+			TeamID:      entry.TeamID,
+			UserID:      entry.UserID,
+			ReferenceID: entry.ID,
+			ActorID:     entry.JudgedBy,
+			Reason:      "Partial credit",
+			At:          time.Now(),
+		}
+		return models.SettleChallengeCredit(contestID, challenge.ID, current, expected, cause), nil
+	}
+}
+
+// RecordHint stores a hint reveal and charges it, in one transaction
+func (s *ScoreLedgerService) RecordHint(reveal *models.HintReveal) error {
+	err := s.hintRepo.CreateReveal(reveal, func(r *models.HintReveal) []models.ScoreLedgerEntry {
+		if r.Cost == 0 {
+			return nil
+		}
+		return []models.ScoreLedgerEntry{hintLedgerEntry(r)}
+	})
+	if err == nil && reveal.Cost != 0 {
+		invalidateContestScoreboardCache(reveal.ContestID)
+	}
+	return err
+}
+
+// RecordAdjustment stores a manual adjustment and credits it, in one transaction.
+// Adjustments belong to no contest and count on every contest's scoreboard.
+func (s *ScoreLedgerService) RecordAdjustment(adj *models.ScoreAdjustment) error {
+	err := s.adjustmentRepo.Create(adj, func(a *models.ScoreAdjustment) []models.ScoreLedgerEntry {
+		return []models.ScoreLedgerEntry{adjustmentLedgerEntry(a)}
+	})
+	if err == nil {
+		invalidateAllScoreboardCaches()
+	}
+	return err
+}
+
+// TeamStatement explains a team's score entry by entry, in one contest or overall
+func (s *ScoreLedgerService) TeamStatement(teamID, contestID string) (*models.LedgerStatement, error) {
+	entries, err := s.ledgerRepo.GetByTeam(teamID, contestID)
+	if err != nil {
+		return nil, err
+	}
+	return models.NewLedgerStatement(models.ScoreAdjustmentTargetTeam, teamID, contestID, entries), nil
+}
+
+// SeedIfEmpty fills an empty ledger from the solves, partial credit, hint reveals and
+// adjustments recorded before it existed, each at the time it happened. Solves and partial
+// credit are replayed in order, so dynamic values decay at the moments they did.
+func (s *ScoreLedgerService) SeedIfEmpty() error {
+	var entries []models.ScoreLedgerEntry
+	reveals, err := s.hintRepo.GetAllReveals()
+	if err != nil {
+		return err
+	}
+	for i := range reveals {
+		if reveals[i].Cost != 0 {
+			entries = append(entries, hintLedgerEntry(&reveals[i]))
+		}
+	}
+	adjustments, err := s.adjustmentRepo.GetAll()
+	if err != nil {
+		return err
+	}
+	for i := range adjustments {
+		entries = append(entries, adjustmentLedgerEntry(&adjustments[i]))
+	}
+
+	seeding, err := s.ledgerRepo.AppendIfEmpty(entries)
+	if err != nil || !seeding {
+		return err
+	}
+
+	scopes, err := s.creditScopes()
+	if err != nil {
+		return err
+	}
+	for _, sc := range scopes {
+		if err := s.seedChallenge(sc.ContestID, sc.ChallengeID); err != nil {
+			return fmt.Errorf("seeding challenge %s in contest %q: %w", sc.ChallengeID, sc.ContestID, err)
+		}
+	}
+	invalidateAllScoreboardCaches()
+	return nil
+}
+
+// seedChallenge replays the credit events of a challenge in a contest, settling after each
+// one at the solve count of that moment and with only what had happened by then, so that
+// the ledger reads as if it had recorded them live
+func (s *ScoreLedgerService) seedChallenge(contestID, challengeID string) error {
+	const reason = "Recorded before the score ledger existed"
+	challenge, err := s.challengeRepo.GetChallengeByID(challengeID)
+	if err != nil {
+		return err
+	}
+
+	// Outside contests the challenge is valued at the solve count of every contest
+	var solves []models.Submission
+	if contestID == "" {
+		solves, err = s.submissionRepo.GetCorrectSubmissionsByChallenge(challengeID)
+	} else {
+		solves, err = s.submissionRepo.GetCorrectSubmissionsByContestAndChallenge(contestID, challengeID)
+	}
+	if err != nil {
+		return err
+	}
+	answers, err := s.partialAnswers(contestID, challenge)
+	if err != nil {
+		return err
+	}
+
+	for _, ev := range models.ReplayCredit(contestID, solves, answers, reason) {
+		until := ev.Cause.At
+		expected, err := s.challengeCredit(contestID, challenge, s.valueAt(contestID, challenge, ev.SolveCount), &until)
+		if err != nil {
+			return err
+		}
+		err = s.ledgerRepo.Settle(contestID, challengeID, func(current []models.ScoreLedgerEntry) []models.ScoreLedgerEntry {
+			return models.SettleChallengeCredit(contestID, challengeID, current, expected, ev.Cause)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	// Stored solve counts are what live settlements value the challenge at from now on
+	return s.settle(contestID, challengeID, models.LedgerCause{Kind: models.LedgerRevaluation, Reason: reason, At: time.Now()})
+}
+
+// Reconcile settles every challenge that submissions, graded answers or the ledger itself
+// give credit on, so that the ledger agrees with them again, and returns the entries that
+// took. With apply unset nothing is recorded. Challenges are valued at the given solve
+// counts (challenge ID -> count, and contest ID -> challenge ID -> count) rather than the
+// stored ones, which may have drifted.
+func (s *ScoreLedgerService) Reconcile(apply bool, actorID string, solves map[string]int, contestSolves map[string]map[string]int) ([]models.ScoreLedgerEntry, error) {
+	scopes, err := s.creditScopes()
+	if err != nil {
+		return nil, err
+	}
+	cause := models.LedgerCause{
+		Kind:    models.LedgerRevaluation,
+		ActorID: actorID,
+		Reason:  "Reconciled with submissions",
+		At:      time.Now(),
+	}
+
+	var entries []models.ScoreLedgerEntry
+	for _, sc := range scopes {
+		challenge, err := s.challengeRepo.GetChallengeByID(sc.ChallengeID)
+		if err != nil {
+			// Credit on a deleted challenge is left as it is
+			continue
+		}
+		count := solves[challenge.ID]
+		if sc.ContestID != "" {
+			count = contestSolves[sc.ContestID][challenge.ID]
+		}
+		settled, err := s.settleAt(sc.ContestID, challenge, s.valueAt(sc.ContestID, challenge, count), cause, apply)
+		if err != nil {
+			return entries, fmt.Errorf("reconciling challenge %s in contest %q: %w", sc.ChallengeID, sc.ContestID, err)
+		}
+		entries = append(entries, settled...)
+	}
+	return entries, nil
+}
+
+// creditScopes returns every contest and challenge with credit to hand out or to take
+// back: those with valid solves or partial credit, and those the ledger credits
+func (s *ScoreLedgerService) creditScopes() ([]models.LedgerScope, error) {
+	var scopes []models.LedgerScope
+	seen := make(map[models.LedgerScope]bool)
+	add := func(contestID, challengeID string) {
+		if sc := (models.LedgerScope{ContestID: contestID, ChallengeID: challengeID}); !seen[sc] {
+			seen[sc] = true
+			scopes = append(scopes, sc)
+		}
+	}
+	solves, err := s.submissionRepo.GetAllCorrectSubmissions()
+	if err != nil {
+		return nil, err
+	}
+	for _, sub := range solves {
+		add(sub.ContestID, sub.ChallengeID)
+	}
+	if s.manualRepo != nil {
+		partial, err := s.manualRepo.List(models.JudgementPartial, "", "")
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range partial {
+			add(e.ContestID, e.ChallengeID)
+		}
+	}
+	credited, err := s.ledgerRepo.GetCreditScopes()
+	if err != nil {
+		return nil, err
+	}
+	for _, sc := range credited {
+		add(sc.ContestID, sc.ChallengeID)
+	}
+	return scopes, nil
+}
+
+// settle records what changed in the credit every solver should hold on a challenge in a
+// contest ("" outside contests)
+func (s *ScoreLedgerService) settle(contestID, challengeID string, cause models.LedgerCause) error {
+	challenge, err := s.challengeRepo.GetChallengeByID(challengeID)
+	if err != nil {
+		return err
+	}
+	_, err = s.settleAt(contestID, challenge, s.challengeValue(contestID, challenge), cause, true)
+	return err
+}
+
+// settleAt settles a challenge worth value per solve and returns the entries it appended,
+// or with apply unset, the entries it would append
+func (s *ScoreLedgerService) settleAt(contestID string, challenge *models.Challenge, value int, cause models.LedgerCause, apply bool) ([]models.ScoreLedgerEntry, error) {
+	expected, err := s.challengeCredit(contestID, challenge, value, nil)
+	if err != nil {
+		return nil, err
+	}
+	plan := func(current []models.ScoreLedgerEntry) []models.ScoreLedgerEntry {
+		return models.SettleChallengeCredit(contestID, challenge.ID, current, expected, cause)
+	}
+	if !apply {
+		current, err := s.ledgerRepo.GetByChallenge(contestID, challenge.ID)
+		if err != nil {
+			return nil, err
+		}
+		return plan(current), nil
+	}
+
+	var appended []models.ScoreLedgerEntry
+	err = s.ledgerRepo.Settle(contestID, challenge.ID, func(current []models.ScoreLedgerEntry) []models.ScoreLedgerEntry {
+		appended = plan(current)
+		return appended
+	})
+	if err != nil {
+		return nil, err
+	}
+	invalidateContestScoreboardCache(contestID)
+	return appended, nil
+}
+
+// challengeCredit works out what every solver of a challenge in a contest should hold:
+// value for the first solve of each team (or user without a team), first blood bonuses
+// for registered teams, and the best partial credit of those who have not solved it.
+// With until set, only solves and answers made by then count.
+func (s *ScoreLedgerService) challengeCredit(contestID string, challenge *models.Challenge, value int, until *time.Time) ([]models.ChallengeCredit, error) {
+	solves, err := s.submissionRepo.GetCorrectSubmissionsByContestAndChallenge(contestID, challenge.ID)
+	if err != nil {
+		return nil, err
+	}
+	answers, err := s.partialAnswers(contestID, challenge)
+	if err != nil {
+		return nil, err
+	}
+	if until != nil {
+		keptSolves := solves[:0]
+		for _, sub := range solves {
+			if !sub.Timestamp.After(*until) {
+				keptSolves = append(keptSolves, sub)
+			}
+		}
+		solves = keptSolves
+		keptAnswers := answers[:0]
+		for _, a := range answers {
+			if !a.CreatedAt.After(*until) {
+				keptAnswers = append(keptAnswers, a)
+			}
+		}
+		answers = keptAnswers
+	}
+	return s.creditFor(contestID, challenge, value, solves, answers)
+}
+
+// creditFor works out the credit on a challenge in a contest from its valid correct
+// submissions and partially credited answers
+func (s *ScoreLedgerService) creditFor(contestID string, challenge *models.Challenge, value int, solves []models.Submission, answers []models.ManualSubmission) ([]models.ChallengeCredit, error) {
+	sort.SliceStable(solves, func(i, j int) bool {
+		return solves[i].Timestamp.Before(solves[j].Timestamp)
+	})
+
+	var credits []models.ChallengeCredit
+	solved := make(map[string]int)
+	for _, sub := range solves {
+		owner := solveOwner(sub.TeamID, sub.UserID)
+		if _, seen := solved[owner]; seen {
+			continue
+		}
+		solved[owner] = len(credits)
+		credits = append(credits, models.ChallengeCredit{
+			TeamID: sub.TeamID,
+			UserID: sub.UserID,
+			Solve:  value,
+		})
+	}
+
+	if contestID != "" && len(challenge.FirstBloodBonus) > 0 && s.registrationRepo != nil {
+		teamIDs, err := s.registrationRepo.GetContestTeams(contestID)
+		if err != nil {
+			return nil, err
+		}
+		registered := make(map[string]bool, len(teamIDs))
+		for _, id := range teamIDs {
+			registered[id] = true
+		}
+		eligible := make([]models.Submission, 0, len(solves))
+		for _, sub := range solves {
+			if registered[sub.TeamID] {
+				eligible = append(eligible, sub)
+			}
+		}
+		for teamID, solve := range models.FirstBloodPlaces(eligible)[challenge.ID] {
+			credits[solved[solveOwner(teamID, "")]].FirstBlood = models.FirstBloodBonus(value, challenge.FirstBloodBonus, solve.Place)
+		}
+	}
+
+	for _, award := range models.BestPartialAwards(answers) {
+		if _, ok := solved[solveOwner(award.TeamID, award.UserID)]; ok {
+			continue
+		}
+		credits = append(credits, models.ChallengeCredit{
+			TeamID:  award.TeamID,
+			UserID:  award.UserID,
+			Partial: award.PointsAwarded,
+		})
+	}
+	return credits, nil
+}
+
+// partialAnswers returns the answers to a manually graded challenge given partial credit
+// in a contest ("" outside contests)
+func (s *ScoreLedgerService) partialAnswers(contestID string, challenge *models.Challenge) ([]models.ManualSubmission, error) {
+	if s.manualRepo == nil || challenge.GradingMode != models.GradingManual {
+		return nil, nil
+	}
+	answers, err := s.manualRepo.List(models.JudgementPartial, contestID, challenge.ID)
+	if err != nil {
+		return nil, err
+	}
+	if contestID == "" {
+		// An empty filter lists every contest's answers
+		kept := answers[:0]
+		for _, a := range answers {
+			if a.ContestID == "" {
+				kept = append(kept, a)
+			}
+		}
+		answers = kept
+	}
+	return answers, nil
+}
+
+// challengeValue is what a solve of the challenge is worth now in a contest: at the
+// contest's solve count and under its scoring override, or at the global solve count
+// outside contests
+func (s *ScoreLedgerService) challengeValue(contestID string, challenge *models.Challenge) int {
+	if contestID == "" || s.contestSolveRepo == nil {
+		return challenge.CurrentPoints()
+	}
+	count, _ := s.contestSolveRepo.GetContestSolveCount(contestID, challenge.ID)
+	return challenge.PointsInContest(s.contestScoring(contestID), count)
+}
+
+// valueAt is what a solve of the challenge is worth in a contest at a given solve count: the
+// contest's own count under its scoring override, or the global count outside contests
+func (s *ScoreLedgerService) valueAt(contestID string, challenge *models.Challenge, count int) int {
+	if contestID == "" {
+		atCount := *challenge
+		atCount.SolveCount = count
+		return atCount.CurrentPoints()
+	}
+	return challenge.PointsInContest(s.contestScoring(contestID), count)
+}
+
+// contestScoring returns a contest's scoring override, "" when it has none
+func (s *ScoreLedgerService) contestScoring(contestID string) string {
+	if s.contestEntityRepo != nil {
+		if contest, err := s.contestEntityRepo.FindByID(contestID); err == nil {
+			return contest.ScoringType
+		}
+	}
+	return ""
+}
+
+// solveOwner keys a solve by its team, or its user when they have none
+func solveOwner(teamID, userID string) string {
+	if teamID != "" {
+		return "team:" + teamID
+	}
+	return userID
+}
+
+func hintLedgerEntry(reveal *models.HintReveal) models.ScoreLedgerEntry {
+	createdAt := reveal.CreatedAt
+	if createdAt.IsZero() {
+		createdAt = time.Now()
+	}
+	return models.ScoreLedgerEntry{
+		ContestID:   reveal.ContestID,
+		TeamID:      reveal.TeamID,
+		UserID:      reveal.UserID,
+		ChallengeID: reveal.ChallengeID,
+		Kind:        models.LedgerHint,
+		Points:      -reveal.Cost,
+		ReferenceID: reveal.HintID,
+		Reason:      "Hint revealed",
+		CreatedAt:   createdAt,
+	}
+}
+
+func adjustmentLedgerEntry(adj *models.ScoreAdjustment) models.ScoreLedgerEntry {
+	e := models.ScoreLedgerEntry{
+		Kind:        models.LedgerAdjustment,
+		Points:      adj.Delta,
+		ActorID:     adj.CreatedBy,
+		ReferenceID: adj.ID,
+		Reason:      adj.Reason,
+		CreatedAt:   adj.CreatedAt,
+	}
+	if adj.TargetType == models.ScoreAdjustmentTargetTeam {
+		e.TeamID = adj.TargetID
+	} else {
+		e.UserID = adj.TargetID
+	}
+	return e
+}
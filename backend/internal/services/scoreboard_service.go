@@ -3,6 +3,7 @@ package services
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"sort"
 	"time"
@@ -18,29 +19,32 @@ type ScoreboardService struct {
 	challengeRepo      *repositories.ChallengeRepository
 	teamRepo           *repositories.TeamRepository
 	contestRepo        *repositories.ContestRepository
-	adjustmentRepo     *repositories.ScoreAdjustmentRepository
+	ledgerRepo         *repositories.ScoreLedgerRepository
 	contestEntityRepo  *repositories.ContestEntityRepository
 	contestRoundRepo   *repositories.ContestRoundRepository
 	roundChallengeRepo *repositories.RoundChallengeRepository
 	registrationRepo   *repositories.TeamContestRegistrationRepository
-	contestSolveRepo   *repositories.ContestSolveRepository
+	hintRepo           *repositories.HintRepository
 }
 
 type UserScore struct {
-	Username string `json:"username"`
-	Score    int    `json:"score"`
-	TeamName string `json:"team_name,omitempty"`
+	Username string           `json:"username"`
+	Score    int              `json:"score"`
+	TeamName string           `json:"team_name,omitempty"`
+	TieBreak *models.TieBreak `json:"tie_break,omitempty"` // what ordered the user among equal scores
 }
 
 type TeamScore struct {
-	ID          string    `json:"id"`
-	Name        string    `json:"name"`
-	Description string    `json:"description"`
-	Score       int       `json:"score"`
-	MemberIDs   []string  `json:"member_ids"`
-	LeaderID    string    `json:"leader_id,omitempty"`
-	CreatedAt   time.Time `json:"created_at,omitempty"`
-	UpdatedAt   time.Time `json:"updated_at,omitempty"`
+	ID          string           `json:"id"`
+	Name        string           `json:"name"`
+	Description string           `json:"description"`
+	Score       int              `json:"score"`               // points, or the number of solves in ICPC ranking
+	Penalty     *int             `json:"penalty,omitempty"`   // ICPC ranking only: penalty minutes
+	TieBreak    *models.TieBreak `json:"tie_break,omitempty"` // what ordered the team among equal scores
+	MemberIDs   []string         `json:"member_ids"`
+	LeaderID    string           `json:"leader_id,omitempty"`
+	CreatedAt   time.Time        `json:"created_at,omitempty"`
+	UpdatedAt   time.Time        `json:"updated_at,omitempty"`
 }
 
 func NewScoreboardService(
@@ -49,12 +53,12 @@ func NewScoreboardService(
 	challengeRepo *repositories.ChallengeRepository,
 	teamRepo *repositories.TeamRepository,
 	contestRepo *repositories.ContestRepository,
-	adjustmentRepo *repositories.ScoreAdjustmentRepository,
+	ledgerRepo *repositories.ScoreLedgerRepository,
 	contestEntityRepo *repositories.ContestEntityRepository,
 	contestRoundRepo *repositories.ContestRoundRepository,
 	roundChallengeRepo *repositories.RoundChallengeRepository,
 	registrationRepo *repositories.TeamContestRegistrationRepository,
-	contestSolveRepo *repositories.ContestSolveRepository,
+	hintRepo *repositories.HintRepository,
 ) *ScoreboardService {
 	return &ScoreboardService{
 		userRepo:           userRepo,
@@ -62,12 +66,12 @@ func NewScoreboardService(
 		challengeRepo:      challengeRepo,
 		teamRepo:           teamRepo,
 		contestRepo:        contestRepo,
-		adjustmentRepo:     adjustmentRepo,
+		ledgerRepo:         ledgerRepo,
 		contestEntityRepo:  contestEntityRepo,
 		contestRoundRepo:   contestRoundRepo,
 		roundChallengeRepo: roundChallengeRepo,
 		registrationRepo:   registrationRepo,
-		contestSolveRepo:   contestSolveRepo,
+		hintRepo:           hintRepo,
 	}
 }
 
@@ -134,37 +138,34 @@ func (s *ScoreboardService) getFreezeInfoForContest(contestID string) *time.Time
 	return nil
 }
 
-// getCorrectSubmissions returns correct submissions, respecting freeze time if set.
-// When contestID is provided, only returns submissions for that contest.
-func (s *ScoreboardService) getCorrectSubmissions(freezeTime *time.Time) ([]models.Submission, error) {
-	if freezeTime != nil {
-		return s.submissionRepo.GetCorrectSubmissionsBefore(*freezeTime)
+// contestLedger returns the ledger entries counting on a contest's scoreboards: those on
+// its challenges, plus adjustments, recorded up to until when it is set
+func (s *ScoreboardService) contestLedger(contestID string, until *time.Time, contestChallenges map[string]bool) ([]models.ScoreLedgerEntry, error) {
+	entries, err := s.ledgerRepo.GetByContest(contestID, until)
+	if err != nil {
+		return nil, err
 	}
-	return s.submissionRepo.GetAllCorrectSubmissions()
-}
-
-// getCorrectSubmissionsForContest returns correct submissions scoped to a specific contest,
-// respecting freeze time if set.
-func (s *ScoreboardService) getCorrectSubmissionsForContest(contestID string, freezeTime *time.Time) ([]models.Submission, error) {
-	if freezeTime != nil {
-		return s.submissionRepo.GetCorrectSubmissionsByContestBefore(contestID, *freezeTime)
+	counted := entries[:0]
+	for _, e := range entries {
+		if e.ChallengeID == "" || contestChallenges[e.ChallengeID] {
+			counted = append(counted, e)
+		}
 	}
-	return s.submissionRepo.GetCorrectSubmissionsByContest(contestID)
+	return counted, nil
 }
 
-// GetScoreboard returns the individual scoreboard for a specific contest.
-// If contestID is empty, returns an empty slice.
-func (s *ScoreboardService) GetScoreboard(contestID string) ([]UserScore, error) {
+// GetScoreboard returns the individual scoreboard for a specific contest, or as it stood
+// at asOf, to the minute, when set. If contestID is empty, returns an empty slice.
+func (s *ScoreboardService) GetScoreboard(contestID string, asOf *time.Time) ([]UserScore, error) {
 	if contestID == "" {
 		return []UserScore{}, nil
 	}
 
 	ctx := context.Background()
 	freezeTime := s.getFreezeInfoForContest(contestID)
-	cacheKey := fmt.Sprintf("scoreboard:%s", contestID)
-	if freezeTime != nil {
-		cacheKey = fmt.Sprintf("scoreboard:%s:frozen", contestID)
-	}
+	asOf = roundAsOf(asOf)
+	cacheKey := scoreboardCacheKey("scoreboard", contestID, freezeTime, asOf)
+	cutoff := scoreboardCutoff(freezeTime, asOf)
 
 	// Try Redis cache
 	if database.Registry != nil && database.Registry.Scoreboard != nil {
@@ -210,99 +211,70 @@ func (s *ScoreboardService) GetScoreboard(contestID string) ([]UserScore, error)
 		}
 	}
 
-	// Get all challenges for point calculation
-	challenges, err := s.challengeRepo.GetAllChallenges()
+	entries, err := s.contestLedger(contestID, cutoff, contestChallenges)
 	if err != nil {
 		return nil, err
 	}
 
-	// Use contest-specific solve counts for dynamic scoring
-	var contestSolveCounts map[string]int
-	if s.contestSolveRepo != nil {
-		contestSolveCounts, _ = s.contestSolveRepo.GetContestSolveCounts(contestID)
-	}
-
-	challengePoints := make(map[string]int)
-	for _, c := range challenges {
-		if contestChallenges[c.ID] {
-			if contestSolveCounts != nil {
-				challengePoints[c.ID] = c.PointsForSolveCount(contestSolveCounts[c.ID])
-			} else {
-				challengePoints[c.ID] = c.CurrentPoints()
-			}
+	// Only members of registered teams are ranked
+	userScores := make(map[string]int)
+	for _, e := range entries {
+		if registeredUserIDs[e.UserID] {
+			userScores[e.UserID] += e.Points
 		}
 	}
 
-	// Get submissions scoped to this contest
-	submissions, err := s.getCorrectSubmissionsForContest(contestID, freezeTime)
+	// Fetch all users to map ID to Username
+	users, err := s.userRepo.GetAllUsers()
 	if err != nil {
 		return nil, err
 	}
 
-	userScores := make(map[string]int)
-	for _, sub := range submissions {
-		userID := sub.UserID
-		challengeID := sub.ChallengeID
-
-		// Only count if user belongs to a registered team AND challenge is in contest
-		if !registeredUserIDs[userID] {
-			continue
-		}
-		points, inContest := challengePoints[challengeID]
-		if !inContest {
-			continue
-		}
-		userScores[userID] += points
+	usernameMap := make(map[string]string)
+	for _, u := range users {
+		usernameMap[u.ID] = u.Username
 	}
-
-	// Apply manual user score adjustments
-	if s.adjustmentRepo != nil && len(userScores) > 0 {
-		userIDs := make([]string, 0, len(userScores))
-		for uid := range userScores {
-			if oid := uid; err == nil {
-				userIDs = append(userIDs, oid)
-			}
-		}
-		if len(userIDs) > 0 {
-			if deltas, err := s.adjustmentRepo.GetAdjustmentsForUsers(userIDs); err == nil {
-				for uid, delta := range deltas {
-					userScores[uid] += delta
-				}
-			}
+	username := func(uid string) string {
+		if name, exists := usernameMap[uid]; exists {
+			return name
 		}
+		return "Unknown"
 	}
 
-	// Fetch all users to map ID to Username
-	users, err := s.userRepo.GetAllUsers()
+	// Equal scores are ordered by the contest's tie-breaking rule
+	rule := s.contestTieBreaker(contestID)
+	stats, err := s.tieBreakStats(contestID, rule, cutoff, contestChallenges, entries, func(teamID, userID string) string {
+		if registeredUserIDs[userID] {
+			return userID
+		}
+		return ""
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	usernameMap := make(map[string]string)
-	for _, u := range users {
-		usernameMap[u.ID] = u.Username
+	userIDs := make([]string, 0, len(userScores))
+	for uid := range userScores {
+		userIDs = append(userIDs, uid)
 	}
+	sort.Slice(userIDs, func(i, j int) bool {
+		a, b := userIDs[i], userIDs[j]
+		if userScores[a] == userScores[b] {
+			return tieBreakLess(rule, stats[a], stats[b], username(a), username(b))
+		}
+		return userScores[a] > userScores[b]
+	})
 
 	var scores []UserScore
-	for uid, score := range userScores {
-		username, exists := usernameMap[uid]
-		if !exists {
-			username = "Unknown"
-		}
+	for _, uid := range userIDs {
 		scores = append(scores, UserScore{
-			Username: username,
-			Score:    score,
+			Username: username(uid),
+			Score:    userScores[uid],
 			TeamName: userTeamMap[uid],
+			TieBreak: stats[uid].TieBreak(rule),
 		})
 	}
 
-	sort.Slice(scores, func(i, j int) bool {
-		if scores[i].Score == scores[j].Score {
-			return scores[i].Username < scores[j].Username
-		}
-		return scores[i].Score > scores[j].Score
-	})
-
 	// Cache in Redis
 	if database.Registry != nil && database.Registry.Scoreboard != nil {
 		data, err := json.Marshal(scores)
@@ -314,19 +286,18 @@ func (s *ScoreboardService) GetScoreboard(contestID string) ([]UserScore, error)
 	return scores, nil
 }
 
-// GetTeamScoreboard returns the team scoreboard for a specific contest.
-// If contestID is empty, returns an empty slice.
-func (s *ScoreboardService) GetTeamScoreboard(contestID string) ([]TeamScore, error) {
+// GetTeamScoreboard returns the team scoreboard for a specific contest, or as it stood at
+// asOf, to the minute, when set. If contestID is empty, returns an empty slice.
+func (s *ScoreboardService) GetTeamScoreboard(contestID string, asOf *time.Time) ([]TeamScore, error) {
 	if contestID == "" {
 		return []TeamScore{}, nil
 	}
 
 	ctx := context.Background()
 	freezeTime := s.getFreezeInfoForContest(contestID)
-	cacheKey := fmt.Sprintf("team_scoreboard:%s", contestID)
-	if freezeTime != nil {
-		cacheKey = fmt.Sprintf("team_scoreboard:%s:frozen", contestID)
-	}
+	asOf = roundAsOf(asOf)
+	cacheKey := scoreboardCacheKey("team_scoreboard", contestID, freezeTime, asOf)
+	cutoff := scoreboardCutoff(freezeTime, asOf)
 
 	// Try Redis cache
 	if database.Registry != nil && database.Registry.Scoreboard != nil {
@@ -357,52 +328,32 @@ func (s *ScoreboardService) GetTeamScoreboard(contestID string) ([]TeamScore, er
 		return nil, err
 	}
 
-	// Get all challenges for point values
-	challenges, err := s.challengeRepo.GetAllChallenges()
-	if err != nil {
-		return nil, err
-	}
-
-	// Use contest-specific solve counts for dynamic scoring
-	var contestSolveCountsTeam map[string]int
-	if s.contestSolveRepo != nil {
-		contestSolveCountsTeam, _ = s.contestSolveRepo.GetContestSolveCounts(contestID)
-	}
-
-	challengePoints := make(map[string]int)
-	for _, c := range challenges {
-		if contestChallenges[c.ID] {
-			if contestSolveCountsTeam != nil {
-				challengePoints[c.ID] = c.PointsForSolveCount(contestSolveCountsTeam[c.ID])
-			} else {
-				challengePoints[c.ID] = c.CurrentPoints()
+	if contest := s.getContest(contestID); contest != nil && contest.RankingMode == models.RankingICPC {
+		scores, err := s.icpcTeamScores(contest, cutoff, contestChallenges, contestTeams, allTeams)
+		if err != nil {
+			return nil, err
+		}
+		if database.Registry != nil && database.Registry.Scoreboard != nil {
+			if data, err := json.Marshal(scores); err == nil {
+				_ = database.Registry.Scoreboard.Set(ctx, cacheKey, data, 5*time.Minute).Err()
 			}
 		}
+		return scores, nil
 	}
 
-	// Get submissions scoped to this contest
-	submissions, err := s.getCorrectSubmissionsForContest(contestID, freezeTime)
+	entries, err := s.contestLedger(contestID, cutoff, contestChallenges)
 	if err != nil {
 		return nil, err
 	}
+	teamTotals := models.LedgerTotals(entries, func(e models.ScoreLedgerEntry) string { return e.TeamID })
 
-	// Map TeamID -> Set of ChallengeIDs solved (only contest challenges)
-	teamSolves := make(map[string]map[string]bool)
-	for _, sub := range submissions {
-		if sub.TeamID == "" {
-			continue
-		}
-		tid := sub.TeamID
-		cid := sub.ChallengeID
-
-		if !contestTeams[tid] || !contestChallenges[cid] {
-			continue
-		}
-
-		if teamSolves[tid] == nil {
-			teamSolves[tid] = make(map[string]bool)
-		}
-		teamSolves[tid][cid] = true
+	// Equal scores are ordered by the contest's tie-breaking rule
+	rule := s.contestTieBreaker(contestID)
+	stats, err := s.tieBreakStats(contestID, rule, cutoff, contestChallenges, entries, func(teamID, userID string) string {
+		return teamID
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	var scores []TeamScore
@@ -412,13 +363,6 @@ func (s *ScoreboardService) GetTeamScoreboard(contestID string) ([]TeamScore, er
 			continue
 		}
 
-		totalScore := 0
-		if solves, exists := teamSolves[tid]; exists {
-			for cid := range solves {
-				totalScore += challengePoints[cid]
-			}
-		}
-
 		memberIDs, _ := s.teamRepo.GetTeamMembers(team.ID)
 		for i, mid := range memberIDs {
 			memberIDs[i] = mid
@@ -428,7 +372,8 @@ func (s *ScoreboardService) GetTeamScoreboard(contestID string) ([]TeamScore, er
 			ID:          tid,
 			Name:        team.Name,
 			Description: team.Description,
-			Score:       totalScore,
+			Score:       teamTotals[tid],
+			TieBreak:    stats[tid].TieBreak(rule),
 			MemberIDs:   memberIDs,
 			LeaderID:    team.LeaderID,
 			CreatedAt:   team.CreatedAt,
@@ -436,29 +381,9 @@ func (s *ScoreboardService) GetTeamScoreboard(contestID string) ([]TeamScore, er
 		})
 	}
 
-	// Apply manual team score adjustments
-	if s.adjustmentRepo != nil && len(scores) > 0 {
-		teamIDs := make([]string, 0, len(scores))
-		for _, ts := range scores {
-			oid := ts.ID
-			if true {
-				teamIDs = append(teamIDs, oid)
-			}
-		}
-		if len(teamIDs) > 0 {
-			if deltas, err := s.adjustmentRepo.GetAdjustmentsForTeams(teamIDs); err == nil {
-				for i := range scores {
-					if delta, ok := deltas[scores[i].ID]; ok {
-						scores[i].Score += delta
-					}
-				}
-			}
-		}
-	}
-
 	sort.Slice(scores, func(i, j int) bool {
 		if scores[i].Score == scores[j].Score {
-			return scores[i].Name < scores[j].Name
+			return tieBreakLess(rule, stats[scores[i].ID], stats[scores[j].ID], scores[i].Name, scores[j].Name)
 		}
 		return scores[i].Score > scores[j].Score
 	})
@@ -474,6 +399,210 @@ func (s *ScoreboardService) GetTeamScoreboard(contestID string) ([]TeamScore, er
 	return scores, nil
 }
 
+// scoreboardCutoff is the moment a scoreboard counts events up to: asOf when set, but
+// never past the freeze time of a frozen scoreboard
+func scoreboardCutoff(freezeTime, asOf *time.Time) *time.Time {
+	if asOf == nil || (freezeTime != nil && freezeTime.Before(*asOf)) {
+		return freezeTime
+	}
+	return asOf
+}
+
+// roundAsOf rounds a requested past moment down to the minute, so that past scoreboards
+// can be cached per minute. Moments not yet past ask for the current scoreboard.
+func roundAsOf(asOf *time.Time) *time.Time {
+	if asOf == nil || !asOf.Before(time.Now()) {
+		return nil
+	}
+	rounded := asOf.Truncate(time.Minute)
+	return &rounded
+}
+
+// scoreboardCacheKey names the cached scoreboard of a contest: the current one, the frozen
+// one, or the one as it stood at asOf
+func scoreboardCacheKey(prefix, contestID string, freezeTime, asOf *time.Time) string {
+	switch {
+	case asOf != nil:
+		return fmt.Sprintf("%s:%s:as_of:%d", prefix, contestID, asOf.Unix())
+	case freezeTime != nil:
+		return fmt.Sprintf("%s:%s:frozen", prefix, contestID)
+	default:
+		return fmt.Sprintf("%s:%s", prefix, contestID)
+	}
+}
+
+// invalidateContestScoreboardCache drops every cached scoreboard and score progression of
+// a contest: current, frozen or past
+func invalidateContestScoreboardCache(contestID string) {
+	if contestID == "" || database.Registry == nil || database.Registry.Scoreboard == nil {
+		return
+	}
+	ctx := context.Background()
+	keys := []string{
+		fmt.Sprintf("scoreboard:%s", contestID),
+		fmt.Sprintf("scoreboard:%s:frozen", contestID),
+		fmt.Sprintf("team_scoreboard:%s", contestID),
+		fmt.Sprintf("team_scoreboard:%s:frozen", contestID),
+	}
+	for _, pattern := range []string{"scoreboard:%s:as_of:*", "team_scoreboard:%s:as_of:*", "team_score_progression:%s:*"} {
+		iter := database.Registry.Scoreboard.Scan(ctx, 0, fmt.Sprintf(pattern, contestID), 100).Iterator()
+		for iter.Next(ctx) {
+			keys = append(keys, iter.Val())
+		}
+	}
+	_ = database.Registry.Scoreboard.Del(ctx, keys...).Err()
+}
+
+// invalidateAllScoreboardCaches drops the cached scoreboards and score progressions of
+// every contest, for changes such as adjustments that count in all of them
+func invalidateAllScoreboardCaches() {
+	if database.Registry == nil || database.Registry.Scoreboard == nil {
+		return
+	}
+	ctx := context.Background()
+	var keys []string
+	for _, pattern := range []string{"scoreboard:*", "team_scoreboard:*", "team_score_progression:*"} {
+		iter := database.Registry.Scoreboard.Scan(ctx, 0, pattern, 100).Iterator()
+		for iter.Next(ctx) {
+			keys = append(keys, iter.Val())
+		}
+	}
+	if len(keys) > 0 {
+		_ = database.Registry.Scoreboard.Del(ctx, keys...).Err()
+	}
+}
+
+// contestTieBreaker returns the rule ordering equal scores on a contest's scoreboards
+func (s *ScoreboardService) contestTieBreaker(contestID string) string {
+	if contest := s.getContest(contestID); contest != nil {
+		return models.TieBreakerOrDefault(contest.TieBreaker)
+	}
+	return models.TieBreakAlphabetical
+}
+
+// tieBreakStats gathers what a tie-breaking rule compares from the events on a contest's
+// challenges up to cutoff. key picks the scoreboard entry an event counts for.
+func (s *ScoreboardService) tieBreakStats(contestID, rule string, cutoff *time.Time, contestChallenges map[string]bool, entries []models.ScoreLedgerEntry, key func(teamID, userID string) string) (map[string]models.TieBreakStats, error) {
+	var reveals []models.HintReveal
+	var attempts []models.Submission
+	switch rule {
+	case models.TieBreakFewerHints:
+		if s.hintRepo == nil {
+			break
+		}
+		all, err := s.hintRepo.GetRevealsByContest(contestID)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range all {
+			if contestChallenges[r.ChallengeID] && (cutoff == nil || !r.CreatedAt.After(*cutoff)) {
+				reveals = append(reveals, r)
+			}
+		}
+	case models.TieBreakFewerWrong:
+		all, err := s.submissionRepo.GetAttemptsByContest(contestID)
+		if err != nil {
+			return nil, err
+		}
+		for _, sub := range all {
+			if contestChallenges[sub.ChallengeID] && (cutoff == nil || !sub.Timestamp.After(*cutoff)) {
+				attempts = append(attempts, sub)
+			}
+		}
+	}
+	return models.CollectTieBreakStats(entries, reveals, attempts, key), nil
+}
+
+// tieBreakLess orders two scoreboard entries with equal scores: by the tie-breaking rule,
+// then by name
+func tieBreakLess(rule string, a, b models.TieBreakStats, nameA, nameB string) bool {
+	if c := models.CompareTieBreak(rule, a, b); c != 0 {
+		return c < 0
+	}
+	return nameA < nameB
+}
+
+// getContest returns a contest, or nil when it cannot be loaded
+func (s *ScoreboardService) getContest(contestID string) *models.Contest {
+	if s.contestEntityRepo == nil {
+		return nil
+	}
+	contest, err := s.contestEntityRepo.FindByID(contestID)
+	if err != nil {
+		return nil
+	}
+	return contest
+}
+
+// icpcTeamScores ranks the registered teams of an ICPC contest by solves, then by penalty
+// time. Submissions after cutoff are not counted; points, bonuses, hints and
+// adjustments play no part.
+func (s *ScoreboardService) icpcTeamScores(contest *models.Contest, cutoff *time.Time, contestChallenges, contestTeams map[string]bool, allTeams []models.Team) ([]TeamScore, error) {
+	attempts, err := s.submissionRepo.GetAttemptsByContest(contest.ID)
+	if err != nil {
+		return nil, err
+	}
+	counted := make([]models.Submission, 0, len(attempts))
+	for _, sub := range attempts {
+		if !contestTeams[sub.TeamID] || !contestChallenges[sub.ChallengeID] {
+			continue
+		}
+		if cutoff != nil && sub.Timestamp.After(*cutoff) {
+			continue
+		}
+		counted = append(counted, sub)
+	}
+	icpc := models.ICPCScores(counted, contest.StartTime, contest.PenaltyMinutes)
+
+	// Teams still tied on solves, penalty and last solve time are ordered by the contest's
+	// tie-breaking rule, where reaching the score means the last solve
+	rule := models.TieBreakerOrDefault(contest.TieBreaker)
+	stats, err := s.tieBreakStats(contest.ID, rule, cutoff, contestChallenges, nil, func(teamID, userID string) string {
+		if contestTeams[teamID] {
+			return teamID
+		}
+		return ""
+	})
+	if err != nil {
+		return nil, err
+	}
+	for teamID, score := range icpc {
+		st := stats[teamID]
+		st.ReachedAt = score.LastSolveAt
+		stats[teamID] = st
+	}
+
+	scores := []TeamScore{}
+	for _, team := range allTeams {
+		if !contestTeams[team.ID] {
+			continue
+		}
+		memberIDs, _ := s.teamRepo.GetTeamMembers(team.ID)
+		penalty := icpc[team.ID].Penalty
+		scores = append(scores, TeamScore{
+			ID:          team.ID,
+			Name:        team.Name,
+			Description: team.Description,
+			Score:       icpc[team.ID].Solved,
+			Penalty:     &penalty,
+			TieBreak:    stats[team.ID].TieBreak(rule),
+			MemberIDs:   memberIDs,
+			LeaderID:    team.LeaderID,
+			CreatedAt:   team.CreatedAt,
+			UpdatedAt:   team.UpdatedAt,
+		})
+	}
+
+	sort.SliceStable(scores, func(i, j int) bool {
+		a, b := icpc[scores[i].ID], icpc[scores[j].ID]
+		if models.ICPCLess(a, b) || models.ICPCLess(b, a) {
+			return models.ICPCLess(a, b)
+		}
+		return tieBreakLess(rule, stats[scores[i].ID], stats[scores[j].ID], scores[i].Name, scores[j].Name)
+	})
+	return scores, nil
+}
+
 // TeamScoreProgression represents a team's score at a point in time
 type TeamScoreProgression struct {
 	TeamID string            `json:"team_id"`
@@ -502,7 +631,11 @@ func (s *ScoreboardService) GetTeamScoreProgression(days int, contestID string)
 	}
 
 	ctx := context.Background()
+	freezeTime := s.getFreezeInfoForContest(contestID)
 	cacheKey := fmt.Sprintf("team_score_progression:%s:%d", contestID, days)
+	if freezeTime != nil {
+		cacheKey = fmt.Sprintf("team_score_progression:%s:%d:frozen", contestID, days)
+	}
 	if database.Registry != nil && database.Registry.Scoreboard != nil {
 		if val, err := database.Registry.Scoreboard.Get(ctx, cacheKey).Result(); err == nil {
 			var cached []TeamScoreProgression
@@ -530,62 +663,29 @@ func (s *ScoreboardService) GetTeamScoreProgression(days int, contestID string)
 		return nil, err
 	}
 
-	// Get all challenges
-	challenges, err := s.challengeRepo.GetAllChallenges()
-	if err != nil {
-		return nil, err
-	}
-
-	// Use contest-specific solve counts for dynamic scoring
-	var contestSolveCountsProg map[string]int
-	if s.contestSolveRepo != nil {
-		contestSolveCountsProg, _ = s.contestSolveRepo.GetContestSolveCounts(contestID)
-	}
-
-	challengePoints := make(map[string]int)
-	for _, c := range challenges {
-		if contestChallenges[c.ID] {
-			if contestSolveCountsProg != nil {
-				challengePoints[c.ID] = c.PointsForSolveCount(contestSolveCountsProg[c.ID])
-			} else {
-				challengePoints[c.ID] = c.CurrentPoints()
-			}
-		}
-	}
-
-	// Get correct submissions scoped to this contest
-	submissions, err := s.submissionRepo.GetCorrectSubmissionsByContest(contestID)
+	// A frozen contest's progression stops at the freeze, like its scoreboards
+	entries, err := s.contestLedger(contestID, freezeTime, contestChallenges)
 	if err != nil {
 		return nil, err
 	}
 
-	sort.Slice(submissions, func(i, j int) bool {
-		return submissions[i].Timestamp.Before(submissions[j].Timestamp)
-	})
-
-	// Group submissions by team and date (only contest teams + contest challenges)
-	teamSolvesByDate := make(map[string]map[string]map[string]bool)
-	since := time.Now().AddDate(0, 0, -days)
-	for _, sub := range submissions {
-		if sub.TeamID == "" || sub.Timestamp.Before(since) {
+	// Daily score changes per team; everything before the first day shown is the starting score
+	first := time.Now().AddDate(0, 0, -(days - 1))
+	since := time.Date(first.Year(), first.Month(), first.Day(), 0, 0, 0, 0, first.Location())
+	teamStart := make(map[string]int)
+	teamDeltaByDate := make(map[string]map[string]int)
+	for _, e := range entries {
+		if !contestTeams[e.TeamID] {
 			continue
 		}
-
-		teamID := sub.TeamID
-		challengeID := sub.ChallengeID
-
-		if !contestTeams[teamID] || !contestChallenges[challengeID] {
+		if e.CreatedAt.Before(since) {
+			teamStart[e.TeamID] += e.Points
 			continue
 		}
-
-		date := sub.Timestamp.Format("2006-01-02")
-		if teamSolvesByDate[teamID] == nil {
-			teamSolvesByDate[teamID] = make(map[string]map[string]bool)
-		}
-		if teamSolvesByDate[teamID][date] == nil {
-			teamSolvesByDate[teamID][date] = make(map[string]bool)
+		if teamDeltaByDate[e.TeamID] == nil {
+			teamDeltaByDate[e.TeamID] = make(map[string]int)
 		}
-		teamSolvesByDate[teamID][date][challengeID] = true
+		teamDeltaByDate[e.TeamID][e.CreatedAt.Format("2006-01-02")] += e.Points
 	}
 
 	// Build progression for registered teams
@@ -602,23 +702,10 @@ func (s *ScoreboardService) GetTeamScoreProgression(days int, contestID string)
 			Data:   make([]TimeSeriesScore, 0, days),
 		}
 
-		cumulativeSolves := make(map[string]bool)
+		score := teamStart[teamID]
 		for i := days - 1; i >= 0; i-- {
 			day := time.Now().AddDate(0, 0, -i).Format("2006-01-02")
-
-			if daySolves, exists := teamSolvesByDate[teamID][day]; exists {
-				for cid := range daySolves {
-					cumulativeSolves[cid] = true
-				}
-			}
-
-			score := 0
-			for cid := range cumulativeSolves {
-				if points, exists := challengePoints[cid]; exists {
-					score += points
-				}
-			}
-
+			score += teamDeltaByDate[teamID][day]
 			progression.Data = append(progression.Data, TimeSeriesScore{
 				Date:  day,
 				Score: score,
@@ -628,28 +715,6 @@ func (s *ScoreboardService) GetTeamScoreProgression(days int, contestID string)
 		progressions = append(progressions, progression)
 	}
 
-	// Apply manual team score adjustments as a flat offset
-	if s.adjustmentRepo != nil && len(progressions) > 0 {
-		teamIDs := make([]string, 0, len(progressions))
-		for _, p := range progressions {
-			oid := p.TeamID
-			if true {
-				teamIDs = append(teamIDs, oid)
-			}
-		}
-		if len(teamIDs) > 0 {
-			if deltas, err := s.adjustmentRepo.GetAdjustmentsForTeams(teamIDs); err == nil {
-				for i := range progressions {
-					if delta, ok := deltas[progressions[i].TeamID]; ok && delta != 0 {
-						for j := range progressions[i].Data {
-							progressions[i].Data[j].Score += delta
-						}
-					}
-				}
-			}
-		}
-	}
-
 	sort.Slice(progressions, func(i, j int) bool {
 		iScore := 0
 		jScore := 0
@@ -673,3 +738,97 @@ func (s *ScoreboardService) GetTeamScoreProgression(days int, contestID string)
 
 	return progressions, nil
 }
+
+// scoreBreakdown itemizes the contest ledger entries keep selects, as counted on the
+// contest's scoreboards
+func (s *ScoreboardService) scoreBreakdown(contestID, targetType, targetID string, keep func(e models.ScoreLedgerEntry) bool) (*models.ScoreBreakdown, error) {
+	freezeTime := s.getFreezeInfoForContest(contestID)
+	b := &models.ScoreBreakdown{
+		ContestID:     contestID,
+		TargetType:    targetType,
+		TargetID:      targetID,
+		Solves:        []models.SolveScore{},
+		PartialCredit: []models.PartialScore{},
+		Hints:         []models.HintCost{},
+		FrozenAt:      freezeTime,
+	}
+	if keep == nil {
+		b.Tally()
+		return b, nil
+	}
+
+	contestChallenges, err := s.getContestChallengeIDs(contestID)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := s.contestLedger(contestID, freezeTime, contestChallenges)
+	if err != nil {
+		return nil, err
+	}
+	kept := entries[:0]
+	for _, e := range entries {
+		if keep(e) {
+			kept = append(kept, e)
+		}
+	}
+	b.AddLedgerEntries(kept)
+
+	challenges, err := s.challengeRepo.GetAllChallenges()
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[string]models.Challenge, len(challenges))
+	for _, c := range challenges {
+		byID[c.ID] = c
+	}
+	for i := range b.Solves {
+		b.Solves[i].Title = byID[b.Solves[i].ChallengeID].Title
+		b.Solves[i].Category = byID[b.Solves[i].ChallengeID].Category
+	}
+	for i := range b.PartialCredit {
+		b.PartialCredit[i].Title = byID[b.PartialCredit[i].ChallengeID].Title
+	}
+	for i := range b.Hints {
+		b.Hints[i].Title = byID[b.Hints[i].ChallengeID].Title
+	}
+	return b, nil
+}
+
+// GetUserScoreBreakdown itemizes a user's score on the contest's individual scoreboard.
+// Users outside registered teams score nothing, as on the scoreboard.
+func (s *ScoreboardService) GetUserScoreBreakdown(contestID, userID string) (*models.ScoreBreakdown, error) {
+	contestTeams, err := s.getContestTeamIDs(contestID)
+	if err != nil {
+		return nil, err
+	}
+	var keep func(e models.ScoreLedgerEntry) bool
+	if team, err := s.teamRepo.FindTeamByMemberID(userID); err == nil && team != nil && contestTeams[team.ID] {
+		keep = func(e models.ScoreLedgerEntry) bool { return e.UserID == userID }
+	}
+	return s.scoreBreakdown(contestID, models.ScoreAdjustmentTargetUser, userID, keep)
+}
+
+// ErrNotInTeam is returned when a user without a team asks for their team's breakdown
+var ErrNotInTeam = errors.New("you are not a member of any team")
+
+// GetMemberTeamScoreBreakdown itemizes the score of the team userID belongs to
+func (s *ScoreboardService) GetMemberTeamScoreBreakdown(contestID, userID string) (*models.ScoreBreakdown, error) {
+	team, err := s.teamRepo.FindTeamByMemberID(userID)
+	if err != nil || team == nil {
+		return nil, ErrNotInTeam
+	}
+	return s.GetTeamScoreBreakdown(contestID, team.ID)
+}
+
+// GetTeamScoreBreakdown itemizes a team's score on the contest's team scoreboard
+func (s *ScoreboardService) GetTeamScoreBreakdown(contestID, teamID string) (*models.ScoreBreakdown, error) {
+	contestTeams, err := s.getContestTeamIDs(contestID)
+	if err != nil {
+		return nil, err
+	}
+	var keep func(e models.ScoreLedgerEntry) bool
+	if contestTeams[teamID] {
+		keep = func(e models.ScoreLedgerEntry) bool { return e.TeamID == teamID }
+	}
+	return s.scoreBreakdown(contestID, models.ScoreAdjustmentTargetTeam, teamID, keep)
+}
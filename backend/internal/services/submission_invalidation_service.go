@@ -0,0 +1,156 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Uttam-Mahata/RootAccess/backend/internal/models"
+	"github.com/Uttam-Mahata/RootAccess/backend/internal/repositories"
+)
+
+// Errors returned when invalidating or restoring a submission
+var (
+	ErrSubmissionNotFound    = errors.New("submission not found")
+	ErrSubmissionNotSolve    = errors.New("only correct submissions can be invalidated")
+	ErrAlreadyInvalidated    = repositories.ErrAlreadyInvalidated
+	ErrNotInvalidated        = repositories.ErrNotInvalidated
+	ErrInvalidationReason    = fmt.Errorf("a reason of at most %d characters is required", models.MaxInvalidationReasonLength)
+	ErrSolvedSinceInvalidate = errors.New("the challenge has been solved again since; invalidate that solve first")
+)
+
+// SubmissionInvalidationService lets admins take back solves, e.g. after catching
+// cheating, and restore them. Solve counts are rebuilt from the remaining valid
+// submissions so dynamic scores recover for everyone else, and the score ledger records
+// the points taken back or given again.
+type SubmissionInvalidationService struct {
+	submissionRepo   *repositories.SubmissionRepository
+	challengeRepo    *repositories.ChallengeRepository
+	contestSolveRepo *repositories.ContestSolveRepository
+	teamRepo         *repositories.TeamRepository
+	ledgerService    *ScoreLedgerService
+}
+
+func NewSubmissionInvalidationService(
+	submissionRepo *repositories.SubmissionRepository,
+	challengeRepo *repositories.ChallengeRepository,
+	contestSolveRepo *repositories.ContestSolveRepository,
+	teamRepo *repositories.TeamRepository,
+	ledgerService *ScoreLedgerService,
+) *SubmissionInvalidationService {
+	return &SubmissionInvalidationService{
+		submissionRepo:   submissionRepo,
+		challengeRepo:    challengeRepo,
+		contestSolveRepo: contestSolveRepo,
+		teamRepo:         teamRepo,
+		ledgerService:    ledgerService,
+	}
+}
+
+// ListInvalidated returns invalidated submissions, optionally for one contest
+func (s *SubmissionInvalidationService) ListInvalidated(contestID string) ([]models.Submission, error) {
+	return s.submissionRepo.GetInvalidatedSubmissions(contestID)
+}
+
+// Invalidate stops a correct submission from counting as a solve
+func (s *SubmissionInvalidationService) Invalidate(id, reason, adminID string) (*models.Submission, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" || len(reason) > models.MaxInvalidationReasonLength {
+		return nil, ErrInvalidationReason
+	}
+	sub, err := s.submissionRepo.GetByID(id)
+	if err != nil {
+		return nil, ErrSubmissionNotFound
+	}
+	if !sub.IsCorrect {
+		return nil, ErrSubmissionNotSolve
+	}
+	if sub.InvalidatedAt != nil {
+		return nil, ErrAlreadyInvalidated
+	}
+
+	now := time.Now()
+	sub.InvalidatedAt = &now
+	sub.InvalidatedBy = adminID
+	sub.InvalidationReason = reason
+	settle, err := s.settler(sub, func(ch *models.Challenge) repositories.SolveSettler {
+		return s.ledgerService.InvalidationSettler(ch, sub, adminID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := s.submissionRepo.Invalidate(id, adminID, reason, now, settle); err != nil {
+		return nil, err
+	}
+	invalidateContestScoreboardCache(sub.ContestID)
+	return sub, nil
+}
+
+// Restore makes an invalidated submission count again. It is refused when the owner has
+// solved the challenge again in the meantime, which would count the solve twice.
+func (s *SubmissionInvalidationService) Restore(id, adminID string) (*models.Submission, error) {
+	sub, err := s.submissionRepo.GetByID(id)
+	if err != nil {
+		return nil, ErrSubmissionNotFound
+	}
+	if sub.InvalidatedAt == nil {
+		return nil, ErrNotInvalidated
+	}
+	if s.solvedAgain(sub) {
+		return nil, ErrSolvedSinceInvalidate
+	}
+
+	sub.InvalidatedAt = nil
+	sub.InvalidatedBy = ""
+	sub.InvalidationReason = ""
+	settle, err := s.settler(sub, func(ch *models.Challenge) repositories.SolveSettler {
+		return s.ledgerService.RestoreSettler(ch, sub, adminID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := s.submissionRepo.Restore(id, settle); err != nil {
+		return nil, err
+	}
+	invalidateContestScoreboardCache(sub.ContestID)
+	return sub, nil
+}
+
+// Recipients returns the users to notify about a change to a submission: the members
+// of its team, or its submitter without a team
+func (s *SubmissionInvalidationService) Recipients(sub *models.Submission) []string {
+	if sub.TeamID != "" {
+		if members, err := s.teamRepo.GetTeamMembers(sub.TeamID); err == nil && len(members) > 0 {
+			return members
+		}
+	}
+	return []string{sub.UserID}
+}
+
+func (s *SubmissionInvalidationService) solvedAgain(sub *models.Submission) bool {
+	var err error
+	switch {
+	case sub.TeamID != "" && sub.ContestID != "":
+		_, err = s.submissionRepo.FindByChallengeAndTeamInContest(sub.ChallengeID, sub.TeamID, sub.ContestID)
+	case sub.TeamID != "":
+		_, err = s.submissionRepo.FindByChallengeAndTeam(sub.ChallengeID, sub.TeamID)
+	case sub.ContestID != "":
+		_, err = s.submissionRepo.FindByChallengeAndUserInContest(sub.ChallengeID, sub.UserID, sub.ContestID)
+	default:
+		_, err = s.submissionRepo.FindByChallengeAndUser(sub.ChallengeID, sub.UserID)
+	}
+	return err == nil
+}
+
+// settler builds the ledger settlement for a change to sub, or nil without a ledger
+func (s *SubmissionInvalidationService) settler(sub *models.Submission, build func(*models.Challenge) repositories.SolveSettler) (repositories.SolveSettler, error) {
+	if s.ledgerService == nil {
+		return nil, nil
+	}
+	challenge, err := s.challengeRepo.GetChallengeByID(sub.ChallengeID)
+	if err != nil {
+		return nil, err
+	}
+	return build(challenge), nil
+}
@@ -17,8 +17,7 @@ type TeamService struct {
 	invitationRepo *repositories.TeamInvitationRepository
 	userRepo       *repositories.UserRepository
 	emailService   *EmailService
-	submissionRepo *repositories.SubmissionRepository
-	challengeRepo  *repositories.ChallengeRepository
+	ledgerRepo     *repositories.ScoreLedgerRepository
 }
 
 func NewTeamService(
@@ -26,16 +25,14 @@ func NewTeamService(
 	invitationRepo *repositories.TeamInvitationRepository,
 	userRepo *repositories.UserRepository,
 	emailService *EmailService,
-	submissionRepo *repositories.SubmissionRepository,
-	challengeRepo *repositories.ChallengeRepository,
+	ledgerRepo *repositories.ScoreLedgerRepository,
 ) *TeamService {
 	return &TeamService{
 		teamRepo:       teamRepo,
 		invitationRepo: invitationRepo,
 		userRepo:       userRepo,
 		emailService:   emailService,
-		submissionRepo: submissionRepo,
-		challengeRepo:  challengeRepo,
+		ledgerRepo:     ledgerRepo,
 	}
 }
 
@@ -106,37 +103,13 @@ func (s *TeamService) CreateTeam(leaderID, name, description string) (*models.Te
 	return team, nil
 }
 
-// Helper to calculate real-time dynamic score for a team
+// calculateTeamScore sums a team's score ledger across all contests
 func (s *TeamService) calculateTeamScore(teamID string) int {
-	// Get all correct submissions for the team
-	submissions, err := s.submissionRepo.GetTeamSubmissions(teamID)
+	total, err := s.ledgerRepo.GetTeamTotal(teamID)
 	if err != nil {
 		return 0
 	}
-
-	// Early return if no submissions - avoid unnecessary DB queries
-	if len(submissions) == 0 {
-		return 0
-	}
-
-	totalScore := 0
-	seenChallenges := make(map[string]bool)
-
-	for _, sub := range submissions {
-		challengeID := sub.ChallengeID
-		if seenChallenges[challengeID] {
-			continue
-		}
-		seenChallenges[challengeID] = true
-
-		// Get current challenge state (with up-to-date solve count)
-		challenge, err := s.challengeRepo.GetChallengeByID(challengeID)
-		if err == nil {
-			// Add the CURRENT (decayed) value of the challenge
-			totalScore += challenge.CurrentPoints()
-		}
-	}
-	return totalScore
+	return total
 }
 
 // GetTeamByID returns a team by its ID with dynamic score
@@ -0,0 +1,91 @@
+package storage
+
+import (
+	"context"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestProvider(t *testing.T) *LocalProvider {
+	t.Helper()
+	p, err := NewLocalProvider(t.TempDir(), "http://localhost:8080/", "test-key")
+	if err != nil {
+		t.Fatalf("NewLocalProvider() error = %v", err)
+	}
+	return p
+}
+
+// signedParams extracts the object key, expiry and signature from a download URL
+func signedParams(t *testing.T, rawURL string) (string, string, string) {
+	t.Helper()
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		t.Fatalf("invalid download URL %q: %v", rawURL, err)
+	}
+	key := strings.TrimPrefix(u.Path, LocalFilesRoute+"/")
+	return key, u.Query().Get("expires"), u.Query().Get("signature")
+}
+
+func TestLocalProvider_SignedDownload(t *testing.T) {
+	p := newTestProvider(t)
+	ctx := context.Background()
+
+	if err := p.UploadFile(ctx, "attachments", "chal-1/abc/binary file.zip", strings.NewReader("payload")); err != nil {
+		t.Fatalf("UploadFile() error = %v", err)
+	}
+
+	downloadURL, err := p.GetDownloadURL(ctx, "attachments", "chal-1/abc/binary file.zip", time.Minute)
+	if err != nil {
+		t.Fatalf("GetDownloadURL() error = %v", err)
+	}
+	if !strings.HasPrefix(downloadURL, "http://localhost:8080"+LocalFilesRoute+"/attachments/") {
+		t.Fatalf("GetDownloadURL() = %q, want API files route", downloadURL)
+	}
+
+	key, expires, signature := signedParams(t, downloadURL)
+	bucket, filename, _ := strings.Cut(key, "/")
+
+	fullPath, err := p.Open(bucket, filename, expires, signature, time.Now())
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	if data, _ := os.ReadFile(fullPath); string(data) != "payload" {
+		t.Errorf("Open() served %q, want %q", data, "payload")
+	}
+
+	if _, err := p.Open(bucket, filename, expires, signature, time.Now().Add(2*time.Minute)); err == nil {
+		t.Error("Open() accepted an expired link")
+	}
+	if _, err := p.Open(bucket, "chal-1/abc/other.zip", expires, signature, time.Now()); err == nil {
+		t.Error("Open() accepted a signature for a different object")
+	}
+	if _, err := p.Open(bucket, filename, expires, strings.Repeat("0", len(signature)), time.Now()); err == nil {
+		t.Error("Open() accepted a forged signature")
+	}
+
+	if err := p.DeleteFile(ctx, bucket, filename); err != nil {
+		t.Fatalf("DeleteFile() error = %v", err)
+	}
+	if _, err := p.Open(bucket, filename, expires, signature, time.Now()); err != ErrNotFound {
+		t.Errorf("Open() after delete error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestLocalProvider_RejectsTraversal(t *testing.T) {
+	p := newTestProvider(t)
+
+	keys := []struct{ bucket, filename string }{
+		{"attachments", "../secret"},
+		{"attachments", "a/../../secret"},
+		{"../etc", "passwd"},
+		{"attachments", ""},
+	}
+	for _, k := range keys {
+		if err := p.UploadFile(context.Background(), k.bucket, k.filename, strings.NewReader("x")); err == nil {
+			t.Errorf("UploadFile(%q, %q) succeeded, want error", k.bucket, k.filename)
+		}
+	}
+}
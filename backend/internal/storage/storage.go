@@ -0,0 +1,44 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// ErrNotFound is returned when a requested object does not exist
+var ErrNotFound = errors.New("storage: object not found")
+
+// StorageProvider abstracts the object store used for challenge attachments.
+// See docs/PROVIDER_PATTERN_PLAN.md for the planned S3/R2 implementations.
+type StorageProvider interface {
+	// UploadFile stores everything read from r, so large files are never held in memory
+	UploadFile(ctx context.Context, bucket string, filename string, r io.Reader) error
+	DeleteFile(ctx context.Context, bucket string, filename string) error
+	// GetDownloadURL returns a URL that allows downloading the object until it expires
+	GetDownloadURL(ctx context.Context, bucket string, filename string, expiresIn time.Duration) (string, error)
+}
+
+// Options configures the storage provider selected by NewProvider
+type Options struct {
+	Type       string // "local" (default); "s3" and "r2" are not implemented yet
+	LocalPath  string // root directory for the local provider
+	PublicURL  string // externally reachable API base URL used in local download links
+	SigningKey string // secret used to sign local download links
+}
+
+// NewProvider returns the storage provider for the configured type
+func NewProvider(opts Options) (StorageProvider, error) {
+	switch opts.Type {
+	case "", "local":
+		provider, err := NewLocalProvider(opts.LocalPath, opts.PublicURL, opts.SigningKey)
+		if err != nil {
+			return nil, err
+		}
+		return provider, nil
+	default:
+		return nil, fmt.Errorf("storage type %q is not supported yet", opts.Type)
+	}
+}
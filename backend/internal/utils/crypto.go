@@ -1,8 +1,13 @@
 package utils
 
 import (
+	"crypto/hmac"
 	"crypto/sha256"
+	"crypto/subtle"
 	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
 )
 
 // HashFlag creates a SHA-256 hash of the flag
@@ -16,3 +21,44 @@ func VerifyFlag(submittedFlag, storedHash string) bool {
 	submittedHash := HashFlag(submittedFlag)
 	return submittedHash == storedHash
 }
+
+// HashFlagCaseInsensitive hashes the lower-cased flag so comparisons ignore case
+func HashFlagCaseInsensitive(flag string) string {
+	return HashFlag(strings.ToLower(flag))
+}
+
+// VerifyFlagCaseInsensitive compares a submitted flag against a hash created by HashFlagCaseInsensitive
+func VerifyFlagCaseInsensitive(submittedFlag, storedHash string) bool {
+	return HashFlagCaseInsensitive(submittedFlag) == storedHash
+}
+
+// CompileFlagPattern compiles a flag regex anchored to the whole submission
+func CompileFlagPattern(pattern string) (*regexp.Regexp, error) {
+	return regexp.Compile("^(?:" + pattern + ")$")
+}
+
+// MatchFlagPattern reports whether the submitted flag fully matches the pattern.
+// Invalid patterns never match.
+func MatchFlagPattern(submittedFlag, pattern string) bool {
+	re, err := CompileFlagPattern(pattern)
+	if err != nil {
+		return false
+	}
+	return re.MatchString(submittedFlag)
+}
+
+// GenerateDynamicFlag derives a per-owner flag of the form prefix{base_<hmac>}.
+// The HMAC covers the challenge and owner (team or user) IDs, so every team gets a
+// different flag for every challenge and flags cannot be forged without the secret.
+func GenerateDynamicFlag(secret, prefix, base, challengeID, ownerID string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(challengeID + ":" + ownerID))
+	sum := hex.EncodeToString(mac.Sum(nil))
+	return fmt.Sprintf("%s{%s_%s}", prefix, base, sum[:32])
+}
+
+// VerifyDynamicFlag compares a submitted flag against the flag generated for the owner
+func VerifyDynamicFlag(submittedFlag, secret, prefix, base, challengeID, ownerID string) bool {
+	expected := GenerateDynamicFlag(secret, prefix, base, challengeID, ownerID)
+	return subtle.ConstantTimeCompare([]byte(submittedFlag), []byte(expected)) == 1
+}
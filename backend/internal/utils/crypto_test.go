@@ -0,0 +1,80 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestVerifyFlagCaseInsensitive(t *testing.T) {
+	stored := HashFlagCaseInsensitive("flag{Foo_Bar}")
+
+	tests := []struct {
+		submitted string
+		want      bool
+	}{
+		{"flag{Foo_Bar}", true},
+		{"flag{foo_bar}", true},
+		{"FLAG{FOO_BAR}", true},
+		{"flag{foo_baz}", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.submitted, func(t *testing.T) {
+			if got := VerifyFlagCaseInsensitive(tt.submitted, stored); got != tt.want {
+				t.Errorf("VerifyFlagCaseInsensitive(%q) = %v, want %v", tt.submitted, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchFlagPattern(t *testing.T) {
+	tests := []struct {
+		name      string
+		pattern   string
+		submitted string
+		want      bool
+	}{
+		{"exact match", `flag\{[0-9]+\}`, "flag{1337}", true},
+		{"rejects prefix garbage", `flag\{[0-9]+\}`, "xflag{1337}", false},
+		{"rejects suffix garbage", `flag\{[0-9]+\}`, "flag{1337}x", false},
+		{"alternation is anchored", `flag\{a\}|flag\{b\}`, "flag{a}flag{b}", false},
+		{"alternation matches either", `flag\{a\}|flag\{b\}`, "flag{b}", true},
+		{"invalid pattern never matches", `flag\{(`, "flag{(", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MatchFlagPattern(tt.submitted, tt.pattern); got != tt.want {
+				t.Errorf("MatchFlagPattern(%q, %q) = %v, want %v", tt.submitted, tt.pattern, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGenerateDynamicFlag(t *testing.T) {
+	flag := GenerateDynamicFlag("secret", "RA", "sqli", "challenge-1", "team-1")
+
+	if !strings.HasPrefix(flag, "RA{sqli_") || !strings.HasSuffix(flag, "}") {
+		t.Fatalf("GenerateDynamicFlag() = %q, want RA{sqli_<hmac>} format", flag)
+	}
+	if again := GenerateDynamicFlag("secret", "RA", "sqli", "challenge-1", "team-1"); again != flag {
+		t.Errorf("GenerateDynamicFlag() is not deterministic: %q != %q", again, flag)
+	}
+	if other := GenerateDynamicFlag("secret", "RA", "sqli", "challenge-1", "team-2"); other == flag {
+		t.Error("different teams must get different flags")
+	}
+	if other := GenerateDynamicFlag("secret", "RA", "sqli", "challenge-2", "team-1"); other == flag {
+		t.Error("different challenges must get different flags")
+	}
+	if other := GenerateDynamicFlag("other", "RA", "sqli", "challenge-1", "team-1"); other == flag {
+		t.Error("different secrets must give different flags")
+	}
+
+	if !VerifyDynamicFlag(flag, "secret", "RA", "sqli", "challenge-1", "team-1") {
+		t.Error("VerifyDynamicFlag() rejected the owner's flag")
+	}
+	if VerifyDynamicFlag(flag, "secret", "RA", "sqli", "challenge-1", "team-2") {
+		t.Error("VerifyDynamicFlag() accepted another team's flag")
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"text/tabwriter"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/Uttam-Mahata/RootAccess/cli/internal/api"
@@ -47,9 +48,10 @@ var scoreboardCmd = &cobra.Command{
 
 		// 2. Get individual scoreboard for that contest
 		var scores []struct {
-			Username string `json:"username"`
-			Score    int    `json:"score"`
-			TeamName string `json:"team_name"`
+			Username string    `json:"username"`
+			Score    int       `json:"score"`
+			TeamName string    `json:"team_name"`
+			TieBreak *tieBreak `json:"tie_break"`
 		}
 
 		if err := client.Get("/scoreboard?contest_id="+contestID, &scores); err != nil {
@@ -62,19 +64,52 @@ var scoreboardCmd = &cobra.Command{
 			return
 		}
 
+		// Show what broke ties when the contest orders equal scores by more than names
+		showTieBreak := scores[0].TieBreak != nil && scores[0].TieBreak.Rule != "alphabetical"
+
 		w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
-		fmt.Fprintln(w, "RANK\tUSERNAME\tTEAM\tPOINTS")
+		if showTieBreak {
+			fmt.Fprintln(w, "RANK\tUSERNAME\tTEAM\tPOINTS\tTIE-BREAK")
+		} else {
+			fmt.Fprintln(w, "RANK\tUSERNAME\tTEAM\tPOINTS")
+		}
 		for i, s := range scores {
 			team := s.TeamName
 			if team == "" {
 				team = "-"
 			}
-			fmt.Fprintf(w, "%d\t%s\t%s\t%d\n", i+1, s.Username, team, s.Score)
+			if showTieBreak {
+				fmt.Fprintf(w, "%d\t%s\t%s\t%d\t%s\n", i+1, s.Username, team, s.Score, s.TieBreak)
+			} else {
+				fmt.Fprintf(w, "%d\t%s\t%s\t%d\n", i+1, s.Username, team, s.Score)
+			}
 		}
 		w.Flush()
 	},
 }
 
+// tieBreak is the tie-breaker metadata of a scoreboard entry
+type tieBreak struct {
+	Rule             string     `json:"rule"`
+	ReachedAt        *time.Time `json:"reached_at"`
+	HintsUsed        *int       `json:"hints_used"`
+	WrongSubmissions *int       `json:"wrong_submissions"`
+}
+
+func (t *tieBreak) String() string {
+	switch {
+	case t == nil:
+		return "-"
+	case t.ReachedAt != nil:
+		return "reached " + t.ReachedAt.Local().Format("2006-01-02 15:04:05")
+	case t.HintsUsed != nil:
+		return fmt.Sprintf("%d hints", *t.HintsUsed)
+	case t.WrongSubmissions != nil:
+		return fmt.Sprintf("%d wrong", *t.WrongSubmissions)
+	}
+	return "-"
+}
+
 func init() {
 	rootCmd.AddCommand(scoreboardCmd)
 }